@@ -0,0 +1,471 @@
+// Package mockzabbix fake Zabbix JSON-RPC api for unit tests, keeping objects in
+// memory so resources can be created, read, updated and deleted without a server
+package mockzabbix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ID_FIELDS id field of each object kind, used by the generic get/create/update/delete
+var ID_FIELDS = map[string]string{
+	"action":           "actionid",
+	"discoveryrule":    "itemid",
+	"globalmacro":      "globalmacroid",
+	"graph":            "graphid",
+	"host":             "hostid",
+	"hostgroup":        "groupid",
+	"hostprototype":    "hostid",
+	"httptest":         "httptestid",
+	"item":             "itemid",
+	"itemprototype":    "itemid",
+	"maintenance":      "maintenanceid",
+	"mediatype":        "mediatypeid",
+	"proxy":            "proxyid",
+	"proxygroup":       "proxy_groupid",
+	"role":             "roleid",
+	"script":           "scriptid",
+	"template":         "templateid",
+	"templategroup":    "groupid",
+	"trigger":          "triggerid",
+	"triggerprototype": "triggerid",
+	"user":             "userid",
+	"userdirectory":    "userdirectoryid",
+	"usergroup":        "usrgrpid",
+	"usermacro":        "hostmacroid",
+	"valuemap":         "valuemapid",
+}
+
+// SELECT_FIELDS stored fields returned by select params of the generic get, as
+// create and update store what they are sent
+var SELECT_FIELDS = map[string]string{
+	"selectGroups":              "groups",
+	"selectHostGroupRights":     "hostgroup_rights",
+	"selectInterfaces":          "interfaces",
+	"selectMacros":              "macros",
+	"selectRights":              "rights",
+	"selectSteps":               "steps",
+	"selectTags":                "tags",
+	"selectTemplateGroupRights": "templategroup_rights",
+	"selectUsers":               "users",
+	"selectUsrgrps":             "usrgrps",
+}
+
+// Error zabbix api error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+// HandlerFunc custom method implementation, replacing the generic one
+type HandlerFunc func(params json.RawMessage) (interface{}, *Error)
+
+// Call a request received by the server
+type Call struct {
+	Method string
+	Params json.RawMessage
+}
+
+// Server in memory zabbix api
+type Server struct {
+	*httptest.Server
+
+	// Version reported by apiinfo.version, e.g. 6.4.0
+	Version string
+
+	mu       sync.Mutex
+	lastID   int
+	objects  map[string]map[string]map[string]interface{}
+	handlers map[string]HandlerFunc
+	calls    []Call
+}
+
+// New start a server reporting the given api version
+func New(version string) *Server {
+	s := &Server{
+		Version:  version,
+		lastID:   10000,
+		objects:  map[string]map[string]map[string]interface{}{},
+		handlers: map[string]HandlerFunc{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// APIURL url of the json-rpc endpoint
+func (s *Server) APIURL() string {
+	return s.URL + "/api_jsonrpc.php"
+}
+
+// Handle replace the implementation of a method
+func (s *Server) Handle(method string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = fn
+}
+
+// Seed store an object of a kind, returning its new id
+func (s *Server) Seed(kind string, obj map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store(kind, obj)
+}
+
+// Object stored object of a kind, nil if missing
+func (s *Server) Object(kind, id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[kind][id]
+}
+
+// Calls requests received so far, optionally only those of a method
+func (s *Server) Calls(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	calls := []Call{}
+	for _, c := range s.calls {
+		if method == "" || c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// store save obj under a new id, the caller holds the lock
+func (s *Server) store(kind string, obj map[string]interface{}) string {
+	s.lastID++
+	id := strconv.Itoa(s.lastID)
+
+	if s.objects[kind] == nil {
+		s.objects[kind] = map[string]map[string]interface{}{}
+	}
+	copied := map[string]interface{}{}
+	for k, v := range obj {
+		copied[k] = v
+	}
+	copied[ID_FIELDS[kind]] = id
+	s.objects[kind][id] = copied
+	return id
+}
+
+// serve answer a single json-rpc request
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		ID     int32           `json:"id"`
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, rpcErr := s.call(req.Method, req.Params)
+
+	res := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+	}
+	if rpcErr != nil {
+		res["error"] = rpcErr
+	} else {
+		res["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json-rpc")
+	json.NewEncoder(w).Encode(res)
+}
+
+// call dispatch a method to its custom or generic implementation
+func (s *Server) call(method string, params json.RawMessage) (interface{}, *Error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: method, Params: params})
+	fn, custom := s.handlers[method]
+	s.mu.Unlock()
+
+	if custom {
+		return fn(params)
+	}
+
+	// method names are case insensitive, e.g. APIInfo.version
+	method = strings.ToLower(method)
+
+	switch method {
+	case "apiinfo.version":
+		return s.Version, nil
+	case "user.login":
+		return "mock-session", nil
+	case "user.logout":
+		return true, nil
+	}
+
+	parts := strings.SplitN(method, ".", 2)
+	// usermacro.createglobal and friends manage global macros
+	if len(parts) == 2 && parts[0] == "usermacro" && strings.HasSuffix(parts[1], "global") {
+		parts = []string{"globalmacro", strings.TrimSuffix(parts[1], "global")}
+	}
+	idField, ok := ID_FIELDS[parts[0]]
+	if !ok || len(parts) != 2 {
+		return nil, invalidMethod(method)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch parts[1] {
+	case "get":
+		return s.get(parts[0], idField, params)
+	case "create":
+		return s.create(parts[0], idField, params)
+	case "update":
+		return s.update(parts[0], idField, params)
+	case "delete":
+		return s.delete(parts[0], idField, params)
+	}
+	return nil, invalidMethod(method)
+}
+
+// get generic get supporting ids, filter, search, output, select, countOutput,
+// sortfield, sortorder and limit
+func (s *Server) get(kind, idField string, raw json.RawMessage) (interface{}, *Error) {
+	params := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	// global macros are read through usermacro.get as well
+	if global, _ := params["globalmacro"].(bool); global && kind == "usermacro" {
+		kind, idField = "globalmacro", ID_FIELDS["globalmacro"]
+	}
+
+	ids := stringSet(params[idField+"s"])
+	filter, _ := params["filter"].(map[string]interface{})
+	search, _ := params["search"].(map[string]interface{})
+	startSearch, _ := params["startSearch"].(bool)
+
+	keys := []string{}
+	for id := range s.objects[kind] {
+		keys = append(keys, id)
+	}
+	sort.Strings(keys)
+
+	found := []map[string]interface{}{}
+	for _, id := range keys {
+		obj := s.objects[kind][id]
+		if ids != nil && !ids[id] {
+			continue
+		}
+		if !matches(obj, filter, func(have, want string) bool { return have == want }) {
+			continue
+		}
+		if !matches(obj, search, func(have, want string) bool {
+			if startSearch {
+				return strings.HasPrefix(have, want)
+			}
+			return strings.Contains(have, want)
+		}) {
+			continue
+		}
+		found = append(found, obj)
+	}
+
+	if sortBy := stringList(params["sortfield"]); len(sortBy) > 0 {
+		desc := strings.EqualFold(fmt.Sprintf("%v", params["sortorder"]), "DESC")
+		sort.SliceStable(found, func(i, j int) bool {
+			for _, field := range sortBy {
+				if c := compare(found[i][field], found[j][field]); c != 0 {
+					return (c < 0) != desc
+				}
+			}
+			return false
+		})
+	}
+	if limit, ok := params["limit"].(float64); ok && int(limit) < len(found) {
+		found = found[:int(limit)]
+	}
+	if count, _ := params["countOutput"].(bool); count {
+		return strconv.Itoa(len(found)), nil
+	}
+
+	fields := stringSet(params["output"])
+	if fields != nil {
+		for param, field := range SELECT_FIELDS {
+			if _, ok := params[param]; ok {
+				fields[field] = true
+			}
+		}
+	}
+	list := make([]map[string]interface{}, len(found))
+	for i, obj := range found {
+		list[i] = map[string]interface{}{}
+		for k, v := range obj {
+			if fields == nil || fields[k] || k == idField {
+				list[i][k] = v
+			}
+		}
+	}
+	return list, nil
+}
+
+// create generic create of one or more objects
+func (s *Server) create(kind, idField string, raw json.RawMessage) (interface{}, *Error) {
+	objs, err := objectList(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, obj := range objs {
+		ids = append(ids, s.store(kind, obj))
+	}
+	return map[string]interface{}{idField + "s": ids}, nil
+}
+
+// update generic update merging the given fields into stored objects
+func (s *Server) update(kind, idField string, raw json.RawMessage) (interface{}, *Error) {
+	objs, err := objectList(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, obj := range objs {
+		id, _ := obj[idField].(string)
+		stored, ok := s.objects[kind][id]
+		if !ok {
+			return nil, noPermissions()
+		}
+		for k, v := range obj {
+			stored[k] = v
+		}
+		ids = append(ids, id)
+	}
+	return map[string]interface{}{idField + "s": ids}, nil
+}
+
+// delete generic delete taking a list of ids
+func (s *Server) delete(kind, idField string, raw json.RawMessage) (interface{}, *Error) {
+	var ids []string
+	if err := json.Unmarshal(raw, &ids); err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	for _, id := range ids {
+		if _, ok := s.objects[kind][id]; !ok {
+			return nil, noPermissions()
+		}
+	}
+	for _, id := range ids {
+		delete(s.objects[kind], id)
+	}
+	// zabbix reports deleted item prototypes as prototypeids
+	if kind == "itemprototype" {
+		return map[string]interface{}{"prototypeids": ids}, nil
+	}
+	return map[string]interface{}{idField + "s": ids}, nil
+}
+
+// objectList params holding a single object or a list of them
+func objectList(raw json.RawMessage) ([]map[string]interface{}, *Error) {
+	var list []map[string]interface{}
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, invalidParams(err.Error())
+	}
+	return []map[string]interface{}{obj}, nil
+}
+
+// matches whether all fields of a filter or search match the object, a list of
+// wanted values matches any of them
+func matches(obj, conditions map[string]interface{}, match func(have, want string) bool) bool {
+	for field, want := range conditions {
+		have := fmt.Sprintf("%v", obj[field])
+		ok := false
+		for w := range stringSet(want) {
+			if match(have, w) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// stringSet a string or list param as a set, nil when not given or "extend"
+func stringSet(v interface{}) map[string]bool {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "extend" {
+			return nil
+		}
+		return map[string]bool{v: true}
+	case []interface{}:
+		set := map[string]bool{}
+		for _, e := range v {
+			set[fmt.Sprintf("%v", e)] = true
+		}
+		return set
+	}
+	return map[string]bool{fmt.Sprintf("%v", v): true}
+}
+
+// stringList a string or list param as a list, nil when not given
+func stringList(v interface{}) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, e := range v {
+			list[i] = fmt.Sprintf("%v", e)
+		}
+		return list
+	}
+	return []string{fmt.Sprintf("%v", v)}
+}
+
+// compare order two field values, numerically when both are numbers
+func compare(a, b interface{}) int {
+	as, bs := fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)
+	an, aerr := strconv.ParseFloat(as, 64)
+	bn, berr := strconv.ParseFloat(bs, 64)
+	switch {
+	case aerr == nil && berr == nil && an != bn:
+		if an < bn {
+			return -1
+		}
+		return 1
+	case aerr == nil && berr == nil:
+		return 0
+	}
+	return strings.Compare(as, bs)
+}
+
+func invalidMethod(method string) *Error {
+	return &Error{Code: -32601, Message: "Method not found.", Data: fmt.Sprintf("Incorrect API %q.", method)}
+}
+
+func invalidParams(data string) *Error {
+	return &Error{Code: -32602, Message: "Invalid params.", Data: data}
+}
+
+func noPermissions() *Error {
+	return &Error{Code: -32500, Message: "Application error.", Data: "No permissions to referred object or it does not exist!"}
+}
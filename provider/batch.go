@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BATCH_ID_FIELDS id field of the objects returned by get methods that can be
+// batched, reads passing a single id in "<field>s" are coalesced
+var BATCH_ID_FIELDS = map[string]string{
+	"action.get":           "actionid",
+	"discoveryrule.get":    "itemid",
+	"graph.get":            "graphid",
+	"host.get":             "hostid",
+	"hostgroup.get":        "groupid",
+	"item.get":             "itemid",
+	"itemprototype.get":    "itemid",
+	"maintenance.get":      "maintenanceid",
+	"mediatype.get":        "mediatypeid",
+	"proxy.get":            "proxyid",
+	"role.get":             "roleid",
+	"script.get":           "scriptid",
+	"template.get":         "templateid",
+	"templategroup.get":    "groupid",
+	"trigger.get":          "triggerid",
+	"triggerprototype.get": "triggerid",
+	"user.get":             "userid",
+	"usergroup.get":        "usrgrpid",
+	"valuemap.get":         "valuemapid",
+}
+
+// BATCH_EXCLUDED_PARAMS params changing the shape or size of get results, calls
+// using them are never batched
+var BATCH_EXCLUDED_PARAMS = []string{"limit", "countOutput", "preservekeys", "groupCount"}
+
+// batchMaxIDs ids per batched call, further reads start a new batch
+const batchMaxIDs = 500
+
+// readBatcher coalesce concurrent single object reads of the same method and
+// params into one get call with all ids, refreshing n resources of a type in
+// parallel then costs n/parallelism calls instead of n
+type readBatcher struct {
+	window  time.Duration
+	mu      sync.Mutex
+	pending map[string]*readBatch
+}
+
+// readBatch a get call being collected
+type readBatch struct {
+	req     *http.Request
+	call    map[string]interface{}
+	idField string
+	ids     []string
+	done    chan struct{}
+
+	// outcome, set before done is closed
+	result map[string][]json.RawMessage
+	errObj json.RawMessage
+	err    error
+}
+
+// newReadBatcher batcher collecting reads for window before sending them
+func newReadBatcher(window time.Duration) *readBatcher {
+	return &readBatcher{window: window, pending: map[string]*readBatch{}}
+}
+
+// do send req as part of a batch, ok is false when the call can not be batched
+// and has to be sent on its own
+func (b *readBatcher) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (res *http.Response, ok bool, err error) {
+	if b == nil || req.GetBody == nil {
+		return nil, false, nil
+	}
+	call, idField, id, key, ok := batchable(req)
+	if !ok {
+		return nil, false, nil
+	}
+
+	b.mu.Lock()
+	batch := b.pending[key]
+	if batch == nil {
+		batch = &readBatch{req: req, call: call, idField: idField, done: make(chan struct{})}
+		b.pending[key] = batch
+		time.AfterFunc(b.window, func() {
+			b.mu.Lock()
+			b.flush(key, batch)
+			b.mu.Unlock()
+			batch.run(send)
+		})
+	}
+	batch.ids = append(batch.ids, id)
+	if len(batch.ids) >= batchMaxIDs {
+		b.flush(key, batch)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-req.Context().Done():
+		return nil, true, req.Context().Err()
+	case <-batch.done:
+	}
+	if batch.err != nil {
+		return nil, true, batch.err
+	}
+	return batch.response(req, call["id"], id), true, nil
+}
+
+// flush stop adding reads to a batch, the caller holds the lock
+func (b *readBatcher) flush(key string, batch *readBatch) {
+	if b.pending[key] == batch {
+		delete(b.pending, key)
+	}
+}
+
+// batchable parse a call, returning the id it reads and the key of the batch it
+// belongs to when it can be batched
+func batchable(req *http.Request) (call map[string]interface{}, idField, id, key string, ok bool) {
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	if json.NewDecoder(body).Decode(&call) != nil {
+		return
+	}
+
+	method, _ := call["method"].(string)
+	idField, found := BATCH_ID_FIELDS[strings.ToLower(method)]
+	params, isMap := call["params"].(map[string]interface{})
+	if !found || !isMap {
+		return
+	}
+	for _, p := range BATCH_EXCLUDED_PARAMS {
+		if _, set := params[p]; set {
+			return
+		}
+	}
+
+	switch ids := params[idField+"s"].(type) {
+	case string:
+		id = ids
+	case []interface{}:
+		if len(ids) == 1 {
+			id, _ = ids[0].(string)
+		}
+	}
+	if id == "" {
+		return
+	}
+
+	// the id has to be in the results to split them back per read
+	if output, isList := params["output"].([]interface{}); isList && !containsValue(output, idField) {
+		params["output"] = append(output, idField)
+	}
+
+	rest := map[string]interface{}{}
+	for k, v := range params {
+		if k != idField+"s" {
+			rest[k] = v
+		}
+	}
+	b, err := json.Marshal(rest)
+	if err != nil {
+		return
+	}
+	// calls of different sessions are never mixed
+	key = fmt.Sprintf("%s %v %s %s", method, call["auth"], req.Header.Get("Authorization"), b)
+	return call, idField, id, key, true
+}
+
+// run send the batched call and split its results per id
+func (r *readBatch) run(send func(*http.Request) (*http.Response, error)) {
+	defer close(r.done)
+
+	params := r.call["params"].(map[string]interface{})
+	params[r.idField+"s"] = r.ids
+	b, err := json.Marshal(r.call)
+	if err != nil {
+		r.err = err
+		return
+	}
+	log.Trace("Batching %d %s calls", len(r.ids), r.call["method"])
+
+	// readers wait on their own context, one of them giving up must not fail
+	// the call for the others
+	req := r.req.Clone(context.Background())
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.ContentLength = int64(len(b))
+
+	res, err := send(req)
+	if err != nil {
+		r.err = err
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		r.err = fmt.Errorf("%s: HTTP %s", r.call["method"], res.Status)
+		return
+	}
+
+	var response struct {
+		Result []json.RawMessage `json:"result"`
+		Error  json.RawMessage   `json:"error"`
+	}
+	if r.err = json.NewDecoder(res.Body).Decode(&response); r.err != nil {
+		return
+	}
+	if len(response.Error) > 0 && string(response.Error) != "null" {
+		r.errObj = response.Error
+		return
+	}
+
+	r.result = map[string][]json.RawMessage{}
+	for _, raw := range response.Result {
+		var obj map[string]interface{}
+		if json.Unmarshal(raw, &obj) != nil {
+			continue
+		}
+		id := fmt.Sprintf("%v", obj[r.idField])
+		r.result[id] = append(r.result[id], raw)
+	}
+}
+
+// response the part of the batch result answering the read of id
+func (r *readBatch) response(req *http.Request, rpcID interface{}, id string) *http.Response {
+	answer := map[string]interface{}{"jsonrpc": "2.0", "id": rpcID}
+	if r.errObj != nil {
+		answer["error"] = r.errObj
+	} else {
+		result := r.result[id]
+		if result == nil {
+			result = []json.RawMessage{}
+		}
+		answer["result"] = result
+	}
+	b, _ := json.Marshal(answer)
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json-rpc"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+		Request:       req,
+	}
+}
+
+// containsValue whether a decoded json list holds v
+func containsValue(list []interface{}, v string) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
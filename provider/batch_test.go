@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+func TestUnitReadBatcher(t *testing.T) {
+	server := testUnitServer(t, "6.4.0")
+
+	ids := []string{}
+	for _, name := range []string{"tf-acc-a", "tf-acc-b", "tf-acc-c"} {
+		ids = append(ids, server.Seed("usergroup", map[string]interface{}{"name": name}))
+	}
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.APIURL()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.SetClient(&http.Client{Transport: &apiTransport{
+		base:    http.DefaultTransport,
+		batcher: newReadBatcher(50 * time.Millisecond),
+	}})
+
+	var wg sync.WaitGroup
+	names := make([]string, len(ids))
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			var res []map[string]interface{}
+			errs[i] = api.CallWithErrorParse("usergroup.get", zabbix.Params{
+				"output":    []string{"name"},
+				"usrgrpids": id,
+			}, &res)
+			if len(res) == 1 {
+				names[i], _ = res[0]["name"].(string)
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, want := range []string{"tf-acc-a", "tf-acc-b", "tf-acc-c"} {
+		if errs[i] != nil {
+			t.Fatalf("read %d: %s", i, errs[i])
+		}
+		if names[i] != want {
+			t.Errorf("read %d returned %q, expected %q", i, names[i], want)
+		}
+	}
+	if calls := server.Calls("usergroup.get"); len(calls) != 1 {
+		t.Errorf("expected 1 usergroup.get call, got %d", len(calls))
+	}
+}
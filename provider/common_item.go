@@ -6,8 +6,8 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -58,14 +58,14 @@ var itemCommonSchema = map[string]*schema.Schema{
 	"history": &schema.Schema{
 		Type:         schema.TypeString,
 		Description:  "Item History",
-		ValidateFunc: validation.StringIsNotWhiteSpace,
+		ValidateFunc: validateTimeSuffix,
 		Default:      "90d",
 		Optional:     true,
 	},
 	"trends": &schema.Schema{
 		Type:         schema.TypeString,
 		Description:  "Item Trends",
-		ValidateFunc: validation.StringIsNotWhiteSpace,
+		ValidateFunc: validateTimeSuffix,
 		//Default:      "365d",
 		Optional: true,
 		Computed: true,
@@ -86,24 +86,12 @@ var itemCommonSchema = map[string]*schema.Schema{
 		},
 		Optional: true,
 	},
-	"tag": &schema.Schema{
-		Type:     schema.TypeSet,
-		Optional: true,
-		Elem: &schema.Resource{
-			Schema: map[string]*schema.Schema{
-				"key": &schema.Schema{
-					Type:         schema.TypeString,
-					Required:     true,
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Description:  "Tag Key",
-				},
-				"value": &schema.Schema{
-					Type:        schema.TypeString,
-					Optional:    true,
-					Description: "Tag Value",
-				},
-			},
-		},
+	"tag": schemaTags(),
+	"valuemap_name": &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "Name of the value map to use, looked up on the item's host or template",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Optional:     true,
 	},
 }
 
@@ -112,7 +100,7 @@ var itemDelaySchema = map[string]*schema.Schema{
 	"delay": &schema.Schema{
 		Type:         schema.TypeString,
 		Optional:     true,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
+		ValidateFunc: validateUpdateInterval,
 		Default:      "1m",
 		Description:  "Item Delay period",
 	},
@@ -178,40 +166,99 @@ var itemPreprocessorSchema = &schema.Schema{
 	},
 }
 
+// itemLookup subset of the item object returned to the item data source
+type itemLookup struct {
+	ItemID    string           `json:"itemid"`
+	HostID    string           `json:"hostid"`
+	Key       string           `json:"key_"`
+	Name      string           `json:"name"`
+	ValueType zabbix.ValueType `json:"value_type,string"`
+	Status    int              `json:"status,string"`
+	Tags      zabbix.Tags      `json:"tags"`
+}
+
+// dataItem terraform item data handler
+func dataItem() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataItemRead),
+
+		Schema: map[string]*schema.Schema{
+			"hostid": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Host ID",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+				ExactlyOneOf: []string{"hostid", "host"},
+			},
+			"host": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Technical name of the host or template, resolved in the same lookup",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				ExactlyOneOf: []string{"hostid", "host"},
+			},
+			"key": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Item KEY",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Item Name",
+			},
+			"valuetype": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Item Value Type, one of: " + strings.Join(ITEM_VALUE_TYPES_ARR, ", "),
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Item is enabled",
+			},
+			"tag": dataTagsSchema(),
+		},
+	}
+}
+
 // Function signature for context manipulation
 type ItemHandler func(*schema.ResourceData, interface{}, *zabbix.Item)
 
 // return a terraform CreateFunc
-func itemGetCreateWrapper(c ItemHandler, r ItemHandler) schema.CreateFunc {
+func itemGetCreateWrapper(c ItemHandler, r ItemHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceItemCreate(d, m, c, r, false)
 	}
 }
-func protoItemGetCreateWrapper(c ItemHandler, r ItemHandler) schema.CreateFunc {
+func protoItemGetCreateWrapper(c ItemHandler, r ItemHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceItemCreate(d, m, c, r, true)
 	}
 }
 
 // return a terraform UpdateFunc
-func itemGetUpdateWrapper(c ItemHandler, r ItemHandler) schema.UpdateFunc {
+func itemGetUpdateWrapper(c ItemHandler, r ItemHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceItemUpdate(d, m, c, r, false)
 	}
 }
-func protoItemGetUpdateWrapper(c ItemHandler, r ItemHandler) schema.UpdateFunc {
+func protoItemGetUpdateWrapper(c ItemHandler, r ItemHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceItemUpdate(d, m, c, r, true)
 	}
 }
 
 // return a terraform ReadFunc
-func itemGetReadWrapper(r ItemHandler) schema.ReadFunc {
+func itemGetReadWrapper(r ItemHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceItemRead(d, m, r, false)
 	}
 }
-func protoItemGetReadWrapper(r ItemHandler) schema.ReadFunc {
+func protoItemGetReadWrapper(r ItemHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceItemRead(d, m, r, true)
 	}
@@ -228,9 +275,14 @@ func resourceItemCreate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 
 	log.Trace("preparing item object for create/update: %#v", item)
 
-	items := []zabbix.Item{*item}
+	// resolve the value map first, so a wrong name doesn't leave a half set up item
+	valueMapID, err := itemValueMapID(d, api)
 
-	var err error
+	if err != nil {
+		return err
+	}
+
+	items := []zabbix.Item{*item}
 
 	if prototype {
 		err = api.ProtoItemsCreate(items)
@@ -242,10 +294,16 @@ func resourceItemCreate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 		return err
 	}
 
-	log.Trace("created item: %+v", items[0])
+	log.Trace("created item: %s", items[0].ItemID)
 
 	d.SetId(items[0].ItemID)
 
+	if valueMapID != "0" {
+		if err := itemSetValueMap(d, api, valueMapID, prototype); err != nil {
+			return err
+		}
+	}
+
 	return resourceItemRead(d, m, r, prototype)
 }
 
@@ -261,9 +319,13 @@ func resourceItemUpdate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 
 	log.Trace("preparing item object for create/update: %#v", item)
 
-	items := []zabbix.Item{*item}
+	valueMapID, err := itemValueMapID(d, api)
 
-	var err error
+	if err != nil {
+		return err
+	}
+
+	items := []zabbix.Item{*item}
 
 	if prototype {
 		err = api.ProtoItemsUpdate(items)
@@ -275,6 +337,12 @@ func resourceItemUpdate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 		return err
 	}
 
+	if d.HasChange("valuemap_name") {
+		if err := itemSetValueMap(d, api, valueMapID, prototype); err != nil {
+			return err
+		}
+	}
+
 	return resourceItemRead(d, m, r, prototype)
 }
 
@@ -290,12 +358,12 @@ func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prot
 	params := zabbix.Params{
 		"itemids":             []string{d.Id()},
 		"selectPreprocessing": "extend",
-		"selectApplications":  "extend",
-		"selectTags":          "extend",
+		"selectApplications":  []string{"applicationid"},
+		"selectTags":          []string{"tag", "value"},
 	}
 
 	if prototype {
-		params["selectDiscoveryRule"] = "extend"
+		params["selectDiscoveryRule"] = []string{"itemid"}
 		items, err = api.ProtoItemsGet(params)
 	} else {
 		items, err = api.ItemsGet(params)
@@ -314,7 +382,7 @@ func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prot
 	}
 	item := items[0]
 
-	log.Debug("Got item: %+v", item)
+	log.Debug("Got item: %s", item.ItemID)
 
 	d.SetId(item.ItemID)
 	d.Set("hostid", item.HostID)
@@ -335,12 +403,71 @@ func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prot
 	d.Set("applications", applicationSet)
 	d.Set("tag", flattenTags(item.Tags))
 
+	if _, ok := d.GetOk("valuemap_name"); ok {
+		name, err := itemValueMapName(d, api, prototype)
+		if err != nil {
+			return err
+		}
+		d.Set("valuemap_name", name)
+	}
+
 	// run custom
 	r(d, m, &item)
 
 	return nil
 }
 
+// Read Item Data Source Handler
+func dataItemRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"output":     []string{"itemid", "hostid", "key_", "name", "value_type", "status"},
+		"selectTags": "extend",
+		"filter": map[string]interface{}{
+			"key_": d.Get("key").(string),
+		},
+	}
+	if v := d.Get("tag").(*schema.Set); v.Len() > 0 {
+		params["tags"] = tagFilter(d)
+	}
+
+	// item.get resolves the host by name itself, avoiding a separate host lookup
+	if v, ok := d.GetOk("hostid"); ok {
+		params["hostids"] = v
+	} else {
+		params["host"] = d.Get("host").(string)
+	}
+	log.Debug("Lookup of item with: %#v", params)
+
+	var items []itemLookup
+	err := api.CallWithErrorParse("item.get", params, &items)
+
+	if err != nil {
+		return err
+	}
+
+	if len(items) < 1 {
+		return errors.New("no item found")
+	}
+	if len(items) > 1 {
+		return errors.New("multiple items found")
+	}
+	item := items[0]
+
+	log.Debug("Got item: %s", item.ItemID)
+
+	d.SetId(item.ItemID)
+	d.Set("hostid", item.HostID)
+	d.Set("key", item.Key)
+	d.Set("name", item.Name)
+	d.Set("valuetype", ITEM_VALUE_TYPES_REV[item.ValueType])
+	d.Set("enabled", item.Status == 0)
+	d.Set("tag", flattenTags(item.Tags))
+
+	return nil
+}
+
 // Build the base Item Object
 func buildItemObject(d *schema.ResourceData, api *zabbix.API, prototype bool) *zabbix.Item {
 	item := zabbix.Item{
@@ -380,6 +507,81 @@ func buildItemObject(d *schema.ResourceData, api *zabbix.API, prototype bool) *z
 	return &item
 }
 
+// itemValueMapID resolve valuemap_name on the host or template of the item, "0"
+// clears the value map
+func itemValueMapID(d *schema.ResourceData, api *zabbix.API) (string, error) {
+	name, ok := d.GetOk("valuemap_name")
+	if !ok {
+		return "0", nil
+	}
+
+	params := zabbix.Params{
+		"output": []string{"valuemapid", "name"},
+		"filter": map[string]interface{}{
+			"name": name,
+		},
+	}
+
+	// value maps moved from global to host level in 5.4
+	if api.Config.Version >= 50400 {
+		params["hostids"] = d.Get("hostid")
+	}
+
+	valueMaps, err := valueMapsGet(api, params)
+
+	if err != nil {
+		return "", err
+	}
+	if len(valueMaps) != 1 {
+		return "", fmt.Errorf("value map %q not found on host %s", name, d.Get("hostid"))
+	}
+	return valueMaps[0].ValueMapID, nil
+}
+
+// itemSetValueMap point the item to a value map, zabbix.Item has no valuemapid
+func itemSetValueMap(d *schema.ResourceData, api *zabbix.API, valueMapID string, prototype bool) error {
+	method := "item.update"
+	if prototype {
+		method = "itemprototype.update"
+	}
+
+	_, err := api.CallWithError(method, map[string]interface{}{
+		"itemid":     d.Id(),
+		"valuemapid": valueMapID,
+	})
+	return err
+}
+
+// itemValueMapName name of the value map currently used by the item
+func itemValueMapName(d *schema.ResourceData, api *zabbix.API, prototype bool) (string, error) {
+	method := "item.get"
+	if prototype {
+		method = "itemprototype.get"
+	}
+
+	var items []struct {
+		ValueMapID string `json:"valuemapid"`
+	}
+	err := api.CallWithErrorParse(method, zabbix.Params{
+		"output":  []string{"valuemapid"},
+		"itemids": d.Id(),
+	}, &items)
+
+	if err != nil || len(items) != 1 || items[0].ValueMapID == "0" {
+		return "", err
+	}
+
+	valueMaps, err := valueMapsGet(api, zabbix.Params{
+		"output":      []string{"valuemapid", "name"},
+		"valuemapids": items[0].ValueMapID,
+	})
+
+	if err != nil || len(valueMaps) != 1 {
+		return "", err
+	}
+	return valueMaps[0].Name, nil
+}
+
 // Generate preprocessor objects
 func itemGeneratePreprocessors(d *schema.ResourceData) (preprocessors zabbix.Preprocessors) {
 	preprocessorCount := d.Get("preprocessor.#").(int)
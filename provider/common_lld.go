@@ -1,14 +1,16 @@
 package provider
 
 import (
+	"context"
+
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
-	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -33,8 +35,10 @@ var _ = func() bool {
 
 // operator
 var LLD_OPERATOR = map[string]zabbix.LLDOperatorType{
-	"match":    zabbix.LLDMatch,
-	"notmatch": zabbix.LLDNotMatch,
+	"match":     zabbix.LLDMatch,
+	"notmatch":  zabbix.LLDNotMatch,
+	"exists":    zabbix.LLDOperatorType("12"),
+	"notexists": zabbix.LLDOperatorType("13"),
 }
 
 var LLD_OPERATOR_REV = map[zabbix.LLDOperatorType]string{}
@@ -49,6 +53,63 @@ var _ = func() bool {
 	return false
 }()
 
+// prototype kind an override operation applies to
+var LLD_OVERRIDE_OBJECT = map[string]int{
+	"item_prototype":    0,
+	"trigger_prototype": 1,
+	"graph_prototype":   2,
+	"host_prototype":    3,
+}
+var LLD_OVERRIDE_OBJECT_REV = map[int]string{}
+
+// how an override operation matches prototype names
+var LLD_OVERRIDE_OPERATOR = map[string]int{
+	"equals":       0,
+	"not_equals":   1,
+	"contains":     2,
+	"not_contains": 3,
+	"matches":      8,
+	"not_matches":  9,
+}
+var LLD_OVERRIDE_OPERATOR_REV = map[int]string{}
+
+var LLD_OVERRIDE_STATUS = map[string]int{
+	"enabled":  0,
+	"disabled": 1,
+}
+var LLD_OVERRIDE_STATUS_REV = map[int]string{}
+
+var LLD_OVERRIDE_DISCOVER = map[string]int{
+	"yes": 0,
+	"no":  1,
+}
+var LLD_OVERRIDE_DISCOVER_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range LLD_OVERRIDE_OBJECT {
+		LLD_OVERRIDE_OBJECT_REV[v] = k
+	}
+	for k, v := range LLD_OVERRIDE_OPERATOR {
+		LLD_OVERRIDE_OPERATOR_REV[v] = k
+	}
+	for k, v := range LLD_OVERRIDE_STATUS {
+		LLD_OVERRIDE_STATUS_REV[v] = k
+	}
+	for k, v := range LLD_OVERRIDE_DISCOVER {
+		LLD_OVERRIDE_DISCOVER_REV[v] = k
+	}
+	return false
+}()
+
+// settings an override operation may change, per prototype kind
+var lldOverrideSettings = map[string][]string{
+	"item_prototype":    {"status", "discover", "delay", "history", "trends", "tag"},
+	"trigger_prototype": {"status", "discover", "severity", "tag"},
+	"graph_prototype":   {"discover"},
+	"host_prototype":    {"status", "discover", "tag", "template_ids", "inventory_mode"},
+}
+
 // common schema elements for all lld types
 var lldCommonSchema = map[string]*schema.Schema{
 	"hostid": &schema.Schema{
@@ -61,14 +122,14 @@ var lldCommonSchema = map[string]*schema.Schema{
 	"delay": &schema.Schema{
 		Type:         schema.TypeString,
 		Optional:     true,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
+		ValidateFunc: validateUpdateInterval,
 		Default:      "3600",
 		Description:  "LLD Delay period",
 	},
 	"lifetime": &schema.Schema{
 		Type:         schema.TypeString,
 		Optional:     true,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
+		ValidateFunc: validateTimeSuffix,
 		Default:      "30d",
 		Description:  "LLD Stale Item Lifetime",
 	},
@@ -96,10 +157,16 @@ var lldCommonSchema = map[string]*schema.Schema{
 	},
 	"formula": &schema.Schema{
 		Type:        schema.TypeString,
-		Description: "Formula",
+		Description: "Formula, referencing the condition ids, for evaltype custom",
 		Default:     "",
 		Optional:    true,
 	},
+	"eval_formula": &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Filter expression as evaluated by zabbix",
+		Computed:    true,
+	},
+	"override": lldOverrideSchema,
 }
 
 // Interface schema
@@ -181,8 +248,11 @@ var lldFilterConditionSchema = &schema.Schema{
 	Elem: &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"id": &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Formula ID, referenced by formula for evaltype custom",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[A-Z]+$"), "must be upper case letters"),
 			},
 			"macro": &schema.Schema{
 				Type:         schema.TypeString,
@@ -191,10 +261,9 @@ var lldFilterConditionSchema = &schema.Schema{
 				ValidateFunc: lldValidationMacro,
 			},
 			"value": &schema.Schema{
-				Type:         schema.TypeString,
-				Required:     true,
-				Description:  "Filter Value",
-				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Filter Value, required by match and notmatch",
 			},
 			"operator": &schema.Schema{
 				Type:         schema.TypeString,
@@ -207,25 +276,221 @@ var lldFilterConditionSchema = &schema.Schema{
 	},
 }
 
+// Schema for override blocks
+var lldOverrideSchema = &schema.Schema{
+	Type:        schema.TypeList,
+	Optional:    true,
+	Description: "Overrides applied in order to the discovered objects (zabbix >= 5.0)",
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Override Name",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			"stop": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Stop processing the following overrides when this one matches",
+			},
+			"evaltype": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "EvalType, one of: " + strings.Join(LLD_EVALTYPE_ARR, ", "),
+				ValidateFunc: validation.StringInSlice(LLD_EVALTYPE_ARR, false),
+				Default:      "andor",
+				Optional:     true,
+			},
+			"formula": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Formula, referencing the condition ids, for evaltype custom",
+				Default:     "",
+				Optional:    true,
+			},
+			"condition": lldFilterConditionSchema,
+			"operation": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"object": &schema.Schema{
+							Type:             schema.TypeString,
+							Required:         true,
+							Description:      "Prototype kind, one of: item_prototype, trigger_prototype, graph_prototype, host_prototype",
+							ValidateFunc:     validateEnum(LLD_OVERRIDE_OBJECT),
+							DiffSuppressFunc: suppressEnumDiff(LLD_OVERRIDE_OBJECT),
+						},
+						"operator": &schema.Schema{
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "equals",
+							Description:      "Name match, one of: equals, not_equals, contains, not_contains, matches, not_matches",
+							ValidateFunc:     validateEnum(LLD_OVERRIDE_OPERATOR),
+							DiffSuppressFunc: suppressEnumDiff(LLD_OVERRIDE_OPERATOR),
+						},
+						"value": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Prototype name to match, empty matches all",
+						},
+						"status": &schema.Schema{
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "Create the objects enabled or disabled",
+							ValidateFunc:     validateEnum(LLD_OVERRIDE_STATUS),
+							DiffSuppressFunc: suppressEnumDiff(LLD_OVERRIDE_STATUS),
+						},
+						"discover": &schema.Schema{
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "Discover the objects, yes or no",
+							ValidateFunc:     validateEnum(LLD_OVERRIDE_DISCOVER),
+							DiffSuppressFunc: suppressEnumDiff(LLD_OVERRIDE_DISCOVER),
+						},
+						"delay": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Item update interval",
+							ValidateFunc: validateUpdateInterval,
+						},
+						"history": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Item history storage period",
+							ValidateFunc: validateTimeSuffix,
+						},
+						"trends": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Item trends storage period",
+							ValidateFunc: validateTimeSuffix,
+						},
+						"severity": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Trigger severity, one of: " + strings.Join(TRIGGER_PRIORITY_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(TRIGGER_PRIORITY_ARR, false),
+						},
+						"tag": schemaTags(),
+						"template_ids": &schema.Schema{
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "Templates linked to discovered hosts",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+							},
+						},
+						"inventory_mode": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Inventory mode of discovered hosts, one of: " + strings.Join(HINV_LOOKUP_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(HINV_LOOKUP_ARR, false),
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// lldOverride override of a discovery rule, not modelled by the api library
+type lldOverride struct {
+	Name       string                 `json:"name"`
+	Step       int                    `json:"step,string"`
+	Stop       int                    `json:"stop,string"`
+	Filter     *zabbix.LLDRuleFilter  `json:"filter,omitempty"`
+	Operations []lldOverrideOperation `json:"operations"`
+}
+
+type lldOverrideOperation struct {
+	OperationObject int             `json:"operationobject,string"`
+	Operator        int             `json:"operator,string"`
+	Value           string          `json:"value"`
+	OpStatus        *lldOpStatus    `json:"opstatus,omitempty"`
+	OpDiscover      *lldOpDiscover  `json:"opdiscover,omitempty"`
+	OpPeriod        *lldOpPeriod    `json:"opperiod,omitempty"`
+	OpHistory       *lldOpHistory   `json:"ophistory,omitempty"`
+	OpTrends        *lldOpTrends    `json:"optrends,omitempty"`
+	OpSeverity      *lldOpSeverity  `json:"opseverity,omitempty"`
+	OpTag           zabbix.Tags     `json:"optag,omitempty"`
+	OpTemplate      []lldOpTemplate `json:"optemplate,omitempty"`
+	OpInventory     *lldOpInventory `json:"opinventory,omitempty"`
+}
+
+type lldOpStatus struct {
+	Status int `json:"status,string"`
+}
+type lldOpDiscover struct {
+	Discover int `json:"discover,string"`
+}
+type lldOpPeriod struct {
+	Delay string `json:"delay"`
+}
+type lldOpHistory struct {
+	History string `json:"history"`
+}
+type lldOpTrends struct {
+	Trends string `json:"trends"`
+}
+type lldOpSeverity struct {
+	Severity zabbix.SeverityType `json:"severity,string"`
+}
+type lldOpTemplate struct {
+	TemplateID string `json:"templateid"`
+}
+type lldOpInventory struct {
+	InventoryMode zabbix.InventoryMode `json:"inventory_mode,string"`
+}
+
+// dataLLD terraform lld rule data handler
+func dataLLD() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataLLDRead),
+
+		Schema: map[string]*schema.Schema{
+			"hostid": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Host ID",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+			},
+			"key": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "LLD KEY",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Required:     true,
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "LLD Name",
+				Computed:    true,
+			},
+		},
+	}
+}
+
 // Function signature for context manipulation
 type LLDHandler func(*schema.ResourceData, interface{}, *zabbix.LLDRule)
 
 // return a terraform CreateFunc
-func lldGetCreateWrapper(c LLDHandler, r LLDHandler) schema.CreateFunc {
+func lldGetCreateWrapper(c LLDHandler, r LLDHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceLLDCreate(d, m, c, r)
 	}
 }
 
 // return a terraform UpdateFunc
-func lldGetUpdateWrapper(c LLDHandler, r LLDHandler) schema.UpdateFunc {
+func lldGetUpdateWrapper(c LLDHandler, r LLDHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceLLDUpdate(d, m, c, r)
 	}
 }
 
 // return a terraform ReadFunc
-func lldGetReadWrapper(r LLDHandler) schema.ReadFunc {
+func lldGetReadWrapper(r LLDHandler) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		return resourceLLDRead(d, m, r)
 	}
@@ -250,10 +515,16 @@ func resourceLLDCreate(d *schema.ResourceData, m interface{}, c LLDHandler, r LL
 		return err
 	}
 
-	log.Trace("created lld: %+v", llds[0])
+	log.Trace("created lld: %s", llds[0].ItemID)
 
 	d.SetId(llds[0].ItemID)
 
+	if d.Get("override.#").(int) > 0 {
+		if err := lldOverridesUpdate(d, api); err != nil {
+			return err
+		}
+	}
+
 	return resourceLLDRead(d, m, r)
 }
 
@@ -277,6 +548,12 @@ func resourceLLDUpdate(d *schema.ResourceData, m interface{}, c LLDHandler, r LL
 		return err
 	}
 
+	if d.HasChange("override") {
+		if err := lldOverridesUpdate(d, api); err != nil {
+			return err
+		}
+	}
+
 	return resourceLLDRead(d, m, r)
 }
 
@@ -306,7 +583,7 @@ func resourceLLDRead(d *schema.ResourceData, m interface{}, r LLDHandler) error
 	}
 	lld := llds[0]
 
-	log.Debug("Got lld: %+v", lld)
+	log.Debug("Got lld: %s", lld.ItemID)
 
 	d.SetId(lld.ItemID)
 	d.Set("hostid", lld.HostID)
@@ -316,16 +593,61 @@ func resourceLLDRead(d *schema.ResourceData, m interface{}, r LLDHandler) error
 	d.Set("lifetime", lld.LifeTime)
 	d.Set("evaltype", LLD_EVALTYPE_REV[lld.Filter.EvalType])
 	d.Set("formula", lld.Filter.Formula)
-	d.Set("condition", flattenlldConditions(lld))
+	d.Set("eval_formula", lld.Filter.EvalFormula)
+	d.Set("condition", flattenlldConditions(lld.Filter.Conditions))
 	d.Set("preprocessor", flattenlldPreprocessors(lld))
 	d.Set("macro", flattenlldMacroPaths(lld))
 
+	if api.Config.Version >= 50000 {
+		overrides, err := lldOverridesGet(api, d.Id())
+		if err != nil {
+			return err
+		}
+		d.Set("override", flattenLLDOverrides(overrides))
+	}
+
 	// run custom
 	r(d, m, &lld)
 
 	return nil
 }
 
+// Read lld Data Source Handler
+func dataLLDRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"output":  []string{"itemid", "hostid", "key_", "name"},
+		"hostids": d.Get("hostid").(string),
+		"filter": map[string]interface{}{
+			"key_": d.Get("key").(string),
+		},
+	}
+	log.Debug("Lookup of lld with: %#v", params)
+
+	llds, err := api.LLDsGet(params)
+
+	if err != nil {
+		return err
+	}
+
+	if len(llds) < 1 {
+		return errors.New("no lld found")
+	}
+	if len(llds) > 1 {
+		return errors.New("multiple llds found")
+	}
+	lld := llds[0]
+
+	log.Debug("Got lld: %s", lld.ItemID)
+
+	d.SetId(lld.ItemID)
+	d.Set("key", lld.Key)
+	d.Set("name", lld.Name)
+
+	return nil
+}
+
 // Build the base lld Object
 func buildLLDObject(d *schema.ResourceData) *zabbix.LLDRule {
 	lld := zabbix.LLDRule{
@@ -341,7 +663,7 @@ func buildLLDObject(d *schema.ResourceData) *zabbix.LLDRule {
 
 	lld.Filter.EvalType = LLD_EVALTYPE[d.Get("evaltype").(string)]
 	lld.Filter.Formula = d.Get("formula").(string)
-	lld.Filter.Conditions = lldGenerateConditions(d)
+	lld.Filter.Conditions = lldGenerateConditions(d.Get("condition").([]interface{}))
 
 	return &lld
 }
@@ -386,20 +708,19 @@ func lldGenerateMacroPaths(d *schema.ResourceData) (paths zabbix.LLDMacroPaths)
 	return
 }
 
-// Generate LLD Filter Conditions
-func lldGenerateConditions(d *schema.ResourceData) (conditions zabbix.LLDRuleFilterConditions) {
-	conditionsCount := d.Get("condition.#").(int)
-	conditions = make(zabbix.LLDRuleFilterConditions, conditionsCount)
+// Generate LLD Filter Conditions, of the rule or one of its overrides
+func lldGenerateConditions(list []interface{}) (conditions zabbix.LLDRuleFilterConditions) {
+	conditions = make(zabbix.LLDRuleFilterConditions, len(list))
 
-	for i := 0; i < conditionsCount; i++ {
-		prefix := fmt.Sprintf("condition.%d.", i)
+	for i, raw := range list {
+		current := raw.(map[string]interface{})
 
 		conditions[i] = zabbix.LLDRuleFilterCondition{
-			Macro:    d.Get(prefix + "macro").(string),
-			Value:    d.Get(prefix + "value").(string),
-			Operator: LLD_OPERATOR[d.Get(prefix+"operator").(string)],
+			Macro:    current["macro"].(string),
+			Value:    current["value"].(string),
+			Operator: LLD_OPERATOR[current["operator"].(string)],
 		}
-		id := d.Get(prefix + "id").(string)
+		id, _ := current["id"].(string)
 		if id != "" {
 			conditions[i].FormulaID = id
 		}
@@ -427,7 +748,7 @@ func flattenlldPreprocessors(lld zabbix.LLDRule) []interface{} {
 func flattenlldMacroPaths(lld zabbix.LLDRule) *schema.Set {
 	set := schema.NewSet(func(i interface{}) int {
 		m := i.(map[string]interface{})
-		return hashcode.String(m["macro"].(string) + "P" + m["path"].(string))
+		return hashString(m["macro"].(string) + "P" + m["path"].(string))
 	}, []interface{}{})
 	for i := 0; i < len(lld.MacroPaths); i++ {
 		set.Add(map[string]interface{}{
@@ -439,15 +760,254 @@ func flattenlldMacroPaths(lld zabbix.LLDRule) *schema.Set {
 }
 
 // Generate terraform flattened form of lld filter conditions
-func flattenlldConditions(lld zabbix.LLDRule) []interface{} {
-	val := make([]interface{}, len(lld.Filter.Conditions))
-	for i := 0; i < len(lld.Filter.Conditions); i++ {
+func flattenlldConditions(conditions zabbix.LLDRuleFilterConditions) []interface{} {
+	val := make([]interface{}, len(conditions))
+	for i := 0; i < len(conditions); i++ {
 		val[i] = map[string]interface{}{
-			"id":       lld.Filter.Conditions[i].FormulaID,
-			"macro":    lld.Filter.Conditions[i].Macro,
-			"value":    lld.Filter.Conditions[i].Value,
-			"operator": LLD_OPERATOR_REV[lld.Filter.Conditions[i].Operator],
+			"id":       conditions[i].FormulaID,
+			"macro":    conditions[i].Macro,
+			"value":    conditions[i].Value,
+			"operator": LLD_OPERATOR_REV[conditions[i].Operator],
+		}
+	}
+	return val
+}
+
+// lldCustomizeDiff check the filters and overrides are complete before the api sees them
+func lldCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := validateLLDFilter(d.Get("evaltype").(string), d.Get("formula").(string), d.Get("condition").([]interface{})); err != nil {
+		return err
+	}
+
+	for i, raw := range d.Get("override").([]interface{}) {
+		override := raw.(map[string]interface{})
+		if err := validateLLDFilter(override["evaltype"].(string), override["formula"].(string), override["condition"].([]interface{})); err != nil {
+			return fmt.Errorf("override %d: %s", i, err)
+		}
+		for j, op := range override["operation"].([]interface{}) {
+			if err := validateLLDOverrideOperation(op.(map[string]interface{})); err != nil {
+				return fmt.Errorf("override %d operation %d: %s", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateLLDFilter check formula and condition values fit the evaltype and operators
+func validateLLDFilter(evaltype, formula string, conditions []interface{}) error {
+	if evaltype == "custom" && formula == "" {
+		return fmt.Errorf("evaltype custom needs a formula")
+	}
+
+	for i, raw := range conditions {
+		condition := raw.(map[string]interface{})
+		if evaltype == "custom" && condition["id"].(string) == "" {
+			return fmt.Errorf("condition %d: evaltype custom needs an id to reference in the formula", i)
+		}
+		switch condition["operator"].(string) {
+		case "match", "notmatch":
+			if condition["value"].(string) == "" {
+				return fmt.Errorf("condition %d: %s needs a value", i, condition["operator"])
+			}
+		default:
+			if condition["value"].(string) != "" {
+				return fmt.Errorf("condition %d: %s takes no value", i, condition["operator"])
+			}
+		}
+	}
+	return nil
+}
+
+// validateLLDOverrideOperation check an operation only changes settings its object has
+func validateLLDOverrideOperation(op map[string]interface{}) error {
+	object := enumName(op["object"].(string), LLD_OVERRIDE_OBJECT)
+	allowed := map[string]bool{}
+	for _, key := range lldOverrideSettings[object] {
+		allowed[key] = true
+	}
+
+	set := 0
+	for _, key := range []string{"status", "discover", "delay", "history", "trends", "severity", "inventory_mode"} {
+		if op[key].(string) == "" {
+			continue
+		}
+		if !allowed[key] {
+			return fmt.Errorf("%s has no %s", object, key)
+		}
+		set++
+	}
+	for _, key := range []string{"tag", "template_ids"} {
+		if op[key].(*schema.Set).Len() == 0 {
+			continue
+		}
+		if !allowed[key] {
+			return fmt.Errorf("%s has no %s", object, key)
+		}
+		set++
+	}
+
+	if set == 0 {
+		return fmt.Errorf("operation changes nothing, set one of: %s", strings.Join(lldOverrideSettings[object], ", "))
+	}
+	return nil
+}
+
+// lldGenerateOverrides build override structs from terraform inputs, steps follow the list order
+func lldGenerateOverrides(d *schema.ResourceData) []lldOverride {
+	list := d.Get("override").([]interface{})
+	overrides := make([]lldOverride, len(list))
+
+	for i, raw := range list {
+		current := raw.(map[string]interface{})
+		overrides[i] = lldOverride{
+			Name:       current["name"].(string),
+			Step:       i + 1,
+			Operations: []lldOverrideOperation{},
+		}
+		if current["stop"].(bool) {
+			overrides[i].Stop = 1
+		}
+		if conditions := current["condition"].([]interface{}); len(conditions) > 0 {
+			overrides[i].Filter = &zabbix.LLDRuleFilter{
+				EvalType:   LLD_EVALTYPE[current["evaltype"].(string)],
+				Formula:    current["formula"].(string),
+				Conditions: lldGenerateConditions(conditions),
+			}
+		}
+
+		for _, rawOp := range current["operation"].([]interface{}) {
+			op := rawOp.(map[string]interface{})
+			operation := lldOverrideOperation{
+				OperationObject: enumValue(op["object"].(string), LLD_OVERRIDE_OBJECT),
+				Operator:        enumValue(op["operator"].(string), LLD_OVERRIDE_OPERATOR),
+				Value:           op["value"].(string),
+			}
+			if v := op["status"].(string); v != "" {
+				operation.OpStatus = &lldOpStatus{Status: enumValue(v, LLD_OVERRIDE_STATUS)}
+			}
+			if v := op["discover"].(string); v != "" {
+				operation.OpDiscover = &lldOpDiscover{Discover: enumValue(v, LLD_OVERRIDE_DISCOVER)}
+			}
+			if v := op["delay"].(string); v != "" {
+				operation.OpPeriod = &lldOpPeriod{Delay: v}
+			}
+			if v := op["history"].(string); v != "" {
+				operation.OpHistory = &lldOpHistory{History: v}
+			}
+			if v := op["trends"].(string); v != "" {
+				operation.OpTrends = &lldOpTrends{Trends: v}
+			}
+			if v := op["severity"].(string); v != "" {
+				operation.OpSeverity = &lldOpSeverity{Severity: TRIGGER_PRIORITY[v]}
+			}
+			if v := op["inventory_mode"].(string); v != "" {
+				operation.OpInventory = &lldOpInventory{InventoryMode: HINV_LOOKUP[v]}
+			}
+			for _, tag := range op["tag"].(*schema.Set).List() {
+				t := tag.(map[string]interface{})
+				operation.OpTag = append(operation.OpTag, zabbix.Tag{Tag: tagName(t), Value: t["value"].(string)})
+			}
+			for _, id := range op["template_ids"].(*schema.Set).List() {
+				operation.OpTemplate = append(operation.OpTemplate, lldOpTemplate{TemplateID: id.(string)})
+			}
+			overrides[i].Operations = append(overrides[i].Operations, operation)
+		}
+	}
+
+	return overrides
+}
+
+// lldOverridesUpdate replace the overrides of the rule, the library has no field for them
+func lldOverridesUpdate(d *schema.ResourceData, api *zabbix.API) error {
+	overrides := lldGenerateOverrides(d)
+	if len(overrides) > 0 {
+		if err := requireVersion(api, 50000, "override"); err != nil {
+			return errorAt("override", err)
+		}
+	}
+
+	_, err := api.CallWithError("discoveryrule.update", zabbix.Params{
+		"itemid":    d.Id(),
+		"overrides": overrides,
+	})
+	return err
+}
+
+// lldOverridesGet read the overrides of a rule
+func lldOverridesGet(api *zabbix.API, id string) ([]lldOverride, error) {
+	var rules []struct {
+		Overrides []lldOverride `json:"overrides"`
+	}
+	err := api.CallWithErrorParse("discoveryrule.get", zabbix.Params{
+		"output":          []string{"itemid"},
+		"itemids":         []string{id},
+		"selectOverrides": []string{"name", "step", "stop", "filter", "operations"},
+	}, &rules)
+	if err != nil || len(rules) < 1 {
+		return nil, err
+	}
+
+	overrides := rules[0].Overrides
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Step < overrides[j].Step })
+	return overrides, nil
+}
+
+// flattenLLDOverrides convert response to terraform input
+func flattenLLDOverrides(overrides []lldOverride) []interface{} {
+	val := make([]interface{}, len(overrides))
+	for i, override := range overrides {
+		current := map[string]interface{}{
+			"name":      override.Name,
+			"stop":      override.Stop == 1,
+			"evaltype":  "andor",
+			"formula":   "",
+			"condition": []interface{}{},
+		}
+		if override.Filter != nil {
+			current["evaltype"] = LLD_EVALTYPE_REV[override.Filter.EvalType]
+			current["formula"] = override.Filter.Formula
+			current["condition"] = flattenlldConditions(override.Filter.Conditions)
+		}
+
+		ops := make([]interface{}, len(override.Operations))
+		for j, operation := range override.Operations {
+			op := map[string]interface{}{
+				"object":       LLD_OVERRIDE_OBJECT_REV[operation.OperationObject],
+				"operator":     LLD_OVERRIDE_OPERATOR_REV[operation.Operator],
+				"value":        operation.Value,
+				"tag":          flattenTags(operation.OpTag),
+				"template_ids": []interface{}{},
+			}
+			if operation.OpStatus != nil {
+				op["status"] = LLD_OVERRIDE_STATUS_REV[operation.OpStatus.Status]
+			}
+			if operation.OpDiscover != nil {
+				op["discover"] = LLD_OVERRIDE_DISCOVER_REV[operation.OpDiscover.Discover]
+			}
+			if operation.OpPeriod != nil {
+				op["delay"] = operation.OpPeriod.Delay
+			}
+			if operation.OpHistory != nil {
+				op["history"] = operation.OpHistory.History
+			}
+			if operation.OpTrends != nil {
+				op["trends"] = operation.OpTrends.Trends
+			}
+			if operation.OpSeverity != nil {
+				op["severity"] = TRIGGER_PRIORITY_REV[operation.OpSeverity.Severity]
+			}
+			if operation.OpInventory != nil {
+				op["inventory_mode"] = HINV_LOOKUP_REV[operation.OpInventory.InventoryMode]
+			}
+			ids := make([]interface{}, len(operation.OpTemplate))
+			for k, template := range operation.OpTemplate {
+				ids[k] = template.TemplateID
+			}
+			op["template_ids"] = ids
+			ops[j] = op
 		}
+		current["operation"] = ops
+		val[i] = current
 	}
 	return val
 }
@@ -1,13 +1,38 @@
 package provider
 
 import (
+	"context"
+
 	"fmt"
+	"regexp"
+	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
+// MACRO_TYPE user macro value types
+var MACRO_TYPE = map[string]int{
+	"text":   0,
+	"secret": 1,
+	"vault":  2,
+}
+var MACRO_TYPE_REV = map[int]string{}
+var MACRO_TYPE_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range MACRO_TYPE {
+		MACRO_TYPE_REV[v] = k
+		MACRO_TYPE_ARR = append(MACRO_TYPE_ARR, k)
+	}
+	return false
+}()
+
+// vault macro values reference a secret as path:key
+var vaultMacroValue = regexp.MustCompile("^.+:[^:]+$")
+
 // macro list schema
 var macroListSchema = &schema.Schema{
 	Type:     schema.TypeList,
@@ -24,43 +49,291 @@ var macroListSchema = &schema.Schema{
 				ValidateFunc: validation.StringIsNotWhiteSpace,
 				Description:  "Macro Name (key)",
 			},
-			"value": &schema.Schema{
-				Type:         schema.TypeString,
-				Required:     true,
-				ValidateFunc: validation.StringIsNotWhiteSpace,
-				Description:  "Macro Value",
-			},
+			"value":        macroValueSchema(),
+			"secret_value": macroSecretValueSchema(),
+			"type":         macroTypeSchema(),
 		},
 	},
 }
 
+// macroValueSchema value of a macro, text and vault ones or secret ones that
+// may show up in plans
+func macroValueSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Description:  "Macro Value, the vault path as path:key for vault macros",
+	}
+}
+
+// macroSecretValueSchema value of a secret macro kept out of plans
+func macroSecretValueSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Sensitive:    true,
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Description:  "Value of a secret macro in place of value, hidden in plan output",
+	}
+}
+
+// macroTypeSchema type of a macro
+func macroTypeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "text",
+		ValidateFunc:     validateEnum(MACRO_TYPE),
+		DiffSuppressFunc: suppressEnumDiff(MACRO_TYPE),
+		Description:      "Macro Type, one of: text, secret, vault",
+	}
+}
+
+// macroOverrideSchema host level values of macros inherited from linked templates
+var macroOverrideSchema = &schema.Schema{
+	Type:        schema.TypeList,
+	Optional:    true,
+	Elem:        macroListSchema.Elem,
+	Description: "Host level values of macros defined on the linked templates",
+}
+
+// UserMacro user macro including the type zabbix.Macro lacks
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/usermacro/object
+type UserMacro struct {
+	MacroID   string `json:"hostmacroid,omitempty"`
+	MacroName string `json:"macro"`
+	Value     string `json:"value"`
+	Type      int    `json:"type,string,omitempty"`
+}
+
+// macroOutput user macro fields read back, types came with 5.0
+func macroOutput(api *zabbix.API) []string {
+	output := []string{"hostmacroid", "macro", "value"}
+	if api.Config.Version >= 50000 {
+		output = append(output, "type")
+	}
+	return output
+}
+
 // macroGenerate build macro structs from terraform inputs
-func macroGenerate(d *schema.ResourceData) (macros zabbix.Macros) {
-	macroCount := d.Get("macro.#").(int)
-	macros = make(zabbix.Macros, macroCount)
+func macroGenerate(d *schema.ResourceData, api *zabbix.API) ([]UserMacro, error) {
+	return macroListGenerate(d, api, "macro")
+}
+
+// macroListGenerate build macro structs from a macro list attribute
+func macroListGenerate(d *schema.ResourceData, api *zabbix.API, key string) ([]UserMacro, error) {
+	macroCount := d.Get(key + ".#").(int)
+	macros := make([]UserMacro, macroCount)
 
 	for i := 0; i < macroCount; i++ {
-		prefix := fmt.Sprintf("macro.%d.", i)
+		prefix := fmt.Sprintf("%s.%d.", key, i)
 
-		macros[i] = zabbix.Macro{
-			MacroName: d.Get(prefix + "name").(string),
-			Value:     d.Get(prefix + "value").(string),
-			MacroID:   d.Get(prefix + "id").(string),
+		name := d.Get(prefix + "name").(string)
+		macroType := enumValue(d.Get(prefix+"type").(string), MACRO_TYPE)
+		value, err := macroValue(api, name, d.Get(prefix+"value").(string), d.Get(prefix+"secret_value").(string), macroType)
+		if err != nil {
+			return nil, errorAt(prefix+"value", err)
+		}
+
+		macros[i] = UserMacro{
+			MacroName: name,
+			Value:     value,
+			Type:      macroType,
 		}
 	}
 
-	return
+	return macros, nil
+}
+
+// macroValue the value sent for a macro from its value or secret_value, checking
+// the type is supported and vault values are paths
+func macroValue(api *zabbix.API, name, value, secretValue string, macroType int) (string, error) {
+	switch {
+	case value != "" && secretValue != "":
+		return "", fmt.Errorf("macro %s sets both value and secret_value", name)
+	case secretValue != "" && macroType != MACRO_TYPE["secret"]:
+		return "", fmt.Errorf("secret_value of macro %s is only for secret macros, use value", name)
+	case value == "" && secretValue == "":
+		return "", fmt.Errorf("macro %s needs a value or secret_value", name)
+	}
+
+	switch macroType {
+	case MACRO_TYPE["secret"]:
+		if err := requireVersion(api, 50000, "secret macros"); err != nil {
+			return "", err
+		}
+		if secretValue != "" {
+			return secretValue, nil
+		}
+	case MACRO_TYPE["vault"]:
+		if err := requireVersion(api, 50200, "vault macros"); err != nil {
+			return "", err
+		}
+		if !vaultMacroValue.MatchString(value) {
+			return "", fmt.Errorf("value of vault macro %s must be a vault path in the form path:key", name)
+		}
+	}
+	return value, nil
+}
+
+// flattenMacroValue value and secret_value of a macro read back. The api never
+// returns secret values, those are kept from the configuration
+func flattenMacroValue(macro UserMacro, value, secretValue string) (string, string) {
+	if macro.Type != MACRO_TYPE["secret"] {
+		return macro.Value, ""
+	}
+	return value, secretValue
+}
+
+// flattenMacros convert response to terraform input, secret macro values are
+// never returned by the api so they are kept from the configuration
+func flattenMacros(list []UserMacro, d *schema.ResourceData) []interface{} {
+	return flattenMacroList(list, d, "macro")
 }
 
-// flattenMacros convert response to terraform input
-func flattenMacros(list zabbix.Macros) []interface{} {
+// flattenMacroList convert response to the terraform input of a macro list attribute
+func flattenMacroList(list []UserMacro, d *schema.ResourceData, key string) []interface{} {
+	known := map[string]map[string]interface{}{}
+	for _, raw := range d.Get(key).([]interface{}) {
+		if current, ok := raw.(map[string]interface{}); ok {
+			known[current["name"].(string)] = current
+		}
+	}
+
 	val := make([]interface{}, len(list))
 	for i := 0; i < len(list); i++ {
+		value, secretValue := list[i].Value, ""
+		macroType := MACRO_TYPE_REV[list[i].Type]
+
+		if current, ok := known[list[i].MacroName]; ok {
+			value, secretValue = flattenMacroValue(list[i], current["value"].(string), current["secret_value"].(string))
+			// keep the spelling used in the configuration
+			if t, _ := current["type"].(string); enumValue(t, MACRO_TYPE) == list[i].Type {
+				macroType = t
+			}
+		}
+
 		val[i] = map[string]interface{}{
-			"name":  list[i].MacroName,
-			"value": list[i].Value,
-			"id":    list[i].MacroID,
+			"name":         list[i].MacroName,
+			"value":        value,
+			"secret_value": secretValue,
+			"type":         macroType,
+			"id":           list[i].MacroID,
 		}
 	}
 	return val
 }
+
+// macroBaseName macro name without its context, {$PORT:"http"} is {$PORT}
+func macroBaseName(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[:i] + "}"
+	}
+	return name
+}
+
+// templateMacroNames names of the macros defined on the templates and the
+// templates they are linked to, the macros a host linking them inherits
+func templateMacroNames(api *zabbix.API, templateIDs []string) (map[string]bool, error) {
+	names := map[string]bool{}
+	seen := map[string]bool{}
+
+	for len(templateIDs) > 0 {
+		for _, id := range templateIDs {
+			seen[id] = true
+		}
+
+		templates, err := api.TemplatesGet(zabbix.Params{
+			"output":                []string{"templateid"},
+			"templateids":           templateIDs,
+			"selectMacros":          []string{"macro"},
+			"selectParentTemplates": []string{"templateid"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		templateIDs = nil
+		for _, t := range templates {
+			for _, macro := range t.UserMacros {
+				names[macro.MacroName] = true
+			}
+			for _, parent := range t.ParentTemplates {
+				if !seen[parent.TemplateID] {
+					templateIDs = append(templateIDs, parent.TemplateID)
+				}
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// macroOverrideGenerate build the macro_override structs, checking each one
+// overrides a macro of the linked templates, with or without context
+func macroOverrideGenerate(d *schema.ResourceData, api *zabbix.API, templateIDs []string) ([]UserMacro, error) {
+	overrides, err := macroListGenerate(d, api, "macro_override")
+	if err != nil || len(overrides) == 0 {
+		return overrides, err
+	}
+
+	inherited, err := templateMacroNames(api, templateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, macro := range overrides {
+		if !inherited[macro.MacroName] && !inherited[macroBaseName(macro.MacroName)] {
+			return nil, fmt.Errorf("macro_override %s is not defined on any linked template, use a macro block instead", macro.MacroName)
+		}
+	}
+
+	return overrides, nil
+}
+
+// splitMacroOverrides separate the host macros overriding template macros from
+// the other host macros, by the names configured in macro_override
+func splitMacroOverrides(list []UserMacro, d *schema.ResourceData) (macros, overrides []UserMacro) {
+	names := map[string]bool{}
+	for _, raw := range d.Get("macro_override").([]interface{}) {
+		if current, ok := raw.(map[string]interface{}); ok {
+			names[current["name"].(string)] = true
+		}
+	}
+
+	for _, macro := range list {
+		if names[macro.MacroName] {
+			overrides = append(overrides, macro)
+		} else {
+			macros = append(macros, macro)
+		}
+	}
+	return
+}
+
+// validateMacroNames reject a macro configured in both macro and macro_override
+func validateMacroNames(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	names := map[string]bool{}
+	for _, raw := range d.Get("macro").([]interface{}) {
+		if current, ok := raw.(map[string]interface{}); ok {
+			names[current["name"].(string)] = true
+		}
+	}
+	for _, raw := range d.Get("macro_override").([]interface{}) {
+		if current, ok := raw.(map[string]interface{}); ok && names[current["name"].(string)] {
+			return fmt.Errorf("macro %s is set in both macro and macro_override", current["name"])
+		}
+	}
+	return nil
+}
+
+// withMacros request params of a host or template object carrying the typed macros
+func withMacros(api *zabbix.API, obj interface{}, macros []UserMacro) (map[string]interface{}, error) {
+	params, err := versionedParams(api, obj, nil)
+	if err != nil {
+		return nil, err
+	}
+	params["macros"] = macros
+	return params, nil
+}
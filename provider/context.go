@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// crudFunc create, read, update and delete handlers, run through contextCRUD
+type crudFunc func(d *schema.ResourceData, m interface{}) error
+
+// contextCRUD run a handler with an api whose calls are cancelled along with
+// ctx, reporting its error as diagnostics
+func contextCRUD(f crudFunc) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		api, release := apiWithContext(ctx, m)
+		defer release()
+		return errorDiagnostics(f(d, api))
+	}
+}
+
+// attributeError error caused by the value of one attribute
+type attributeError struct {
+	path string
+	err  error
+}
+
+func (e attributeError) Error() string {
+	return e.err.Error()
+}
+
+func (e attributeError) Unwrap() error {
+	return e.err
+}
+
+// errorAt attribute err to the attribute at path, like tag or macro.1.value,
+// so terraform points at it rather than the whole resource
+func errorAt(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return attributeError{path: path, err: err}
+}
+
+// errorDiagnostics diagnostics of a handler error, on its attribute if known
+func errorDiagnostics(err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var attr attributeError
+	if !errors.As(err, &attr) {
+		return diag.FromErr(err)
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       err.Error(),
+		AttributePath: attributePath(attr.path),
+	}}
+}
+
+// attributePath cty path of a flatmap style key, numbers index lists
+func attributePath(key string) cty.Path {
+	var path cty.Path
+	for _, step := range strings.Split(key, ".") {
+		if i, err := strconv.Atoi(step); err == nil {
+			path = path.IndexInt(i)
+		} else {
+			path = path.GetAttr(step)
+		}
+	}
+	return path
+}
+
+// contextTransport transport cancelling requests along with the context of the
+// terraform operation that sent them
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+// RoundTrip send req, keeping its own deadline from the client timeout
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(t.ctx, cancel)
+	done := func() {
+		stop()
+		cancel()
+	}
+
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		done()
+		return nil, err
+	}
+	res.Body = &doneBody{ReadCloser: res.Body, done: done}
+	return res, nil
+}
+
+// doneBody response body calling done once closed
+type doneBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *doneBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
+
+// apiWithContext copy of api whose calls are cancelled along with ctx, release
+// forgets it once the operation is over. api itself if it was not configured
+// by the provider
+func apiWithContext(ctx context.Context, m interface{}) (*zabbix.API, func()) {
+	api, _ := m.(*zabbix.API)
+	o := getProviderOptions(api)
+	if o.client == nil || o.blank == nil {
+		return api, func() {}
+	}
+
+	client := &http.Client{
+		Transport: contextTransport{ctx: ctx, base: o.client.Transport},
+		Timeout:   o.client.Timeout,
+	}
+	bound := copyAPI(o.blank)
+	bound.SetClient(client)
+
+	o.client = client
+	o.ctx = ctx
+	setProviderOptions(bound, o)
+
+	return bound, func() {
+		forgetProviderOptions(bound)
+		forgetTimeoutClients(bound)
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitContextCRUD(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"url":      server.APIURL(),
+		"username": "Admin",
+		"password": "zabbix",
+	}))
+	if diags.HasError() {
+		t.Fatalf("configuring provider: %v", diags)
+	}
+
+	r := resourceGlobalMacro()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":  "{$TF_UNIT}",
+		"value": "one",
+	})
+	if diags := r.CreateContext(context.Background(), d, p.Meta()); diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if d.Id() == "" || len(server.Calls("usermacro.createglobal")) != 1 {
+		t.Fatalf("expected the macro to be created, got id %q", d.Id())
+	}
+
+	// calls of a cancelled operation are not sent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if diags := r.ReadContext(ctx, d, p.Meta()); !diags.HasError() {
+		t.Error("expected a read with a cancelled context to fail")
+	}
+
+	// errors of an attribute point at it
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":         "{$TF_UNIT}",
+		"value":        "one",
+		"secret_value": "two",
+		"type":         "secret",
+	})
+	diags = r.CreateContext(context.Background(), d, p.Meta())
+	if len(diags) != 1 || !diags[0].AttributePath.Equals(cty.GetAttrPath("value")) {
+		t.Errorf("expected an error on value, got %+v", diags)
+	}
+}
+
+func TestUnitAttributePath(t *testing.T) {
+	want := cty.GetAttrPath("macro").IndexInt(1).GetAttr("value")
+	if got := attributePath("macro.1.value"); !got.Equals(want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// EVENT_SOURCE all event sources, the action resource only manages some of them
+var EVENT_SOURCE = map[string]int{
+	"trigger":          0,
+	"discovery":        1,
+	"autoregistration": 2,
+	"internal":         3,
+	"service":          4,
+}
+var EVENT_SOURCE_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range EVENT_SOURCE {
+		EVENT_SOURCE_REV[v] = k
+	}
+	return false
+}()
+
+// Action represent Zabbix action object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/action/object
+type Action struct {
+	ActionID    string `json:"actionid"`
+	Name        string `json:"name"`
+	EventSource int    `json:"eventsource,string"`
+	Status      int    `json:"status,string"`
+}
+
+// dataAction terraform data handler
+func dataAction() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataActionRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the action.",
+				Required:     true,
+			},
+			"event_source": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Type of events that the action will handle, one of: trigger, discovery, autoregistration, internal, service.",
+				Computed:    true,
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the action is enabled.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataActionRead terraform data resource read handler
+func dataActionRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	var t Action
+	if err := getByName(api, "action.get", "name", d.Get("name").(string), "action", &t); err != nil {
+		return err
+	}
+
+	log.Debug("Got action: %s", t.ActionID)
+
+	d.SetId(t.ActionID)
+	d.Set("name", t.Name)
+	setEnum(d, "event_source", t.EventSource, EVENT_SOURCE, EVENT_SOURCE_REV)
+	d.Set("enabled", t.Status == 0)
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// configuration export option names, mapped to their terraform attribute
+var CONFIG_EXPORT_OPTIONS = map[string]string{
+	"templates":       "templates",
+	"hosts":           "hosts",
+	"host_groups":     "host_groups",
+	"template_groups": "template_groups",
+	"maps":            "maps",
+	"media_types":     "mediaTypes",
+}
+
+// dataConfigurationExport terraform data handler
+func dataConfigurationExport() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"format": &schema.Schema{
+			Type:         schema.TypeString,
+			Description:  "Export format, one of: yaml, json, xml",
+			ValidateFunc: validation.StringInSlice([]string{"yaml", "json", "xml"}, false),
+			Optional:     true,
+			Default:      "yaml",
+		},
+		"document": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Exported configuration document",
+			Computed:    true,
+		},
+	}
+
+	for k := range CONFIG_EXPORT_OPTIONS {
+		s[k] = &schema.Schema{
+			Type:        schema.TypeSet,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "IDs of " + k + " to export",
+			Optional:    true,
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataConfigurationExportRead),
+		Schema:      s,
+	}
+}
+
+// dataConfigurationExportRead terraform data resource read handler
+func dataConfigurationExportRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	format := d.Get("format").(string)
+	if format == "yaml" {
+		if err := requireVersion(api, 50200, "yaml export"); err != nil {
+			return errorAt("format", err)
+		}
+	}
+
+	options := map[string]interface{}{}
+	for k, v := range CONFIG_EXPORT_OPTIONS {
+		if set := d.Get(k).(*schema.Set); set.Len() > 0 {
+			options[v] = set.List()
+		}
+	}
+
+	if len(options) < 1 {
+		return errors.New("no objects selected for export")
+	}
+
+	// template groups were split from host groups in 6.2
+	if _, ok := options["template_groups"]; ok {
+		if err := requireVersion(api, 60200, "template_groups export"); err != nil {
+			return err
+		}
+	}
+	if v, ok := options["host_groups"]; ok && api.Config.Version < 60200 {
+		delete(options, "host_groups")
+		options["groups"] = v
+	}
+
+	params := zabbix.Params{
+		"format":  format,
+		"options": options,
+	}
+	log.Debug("performing configuration export with params: %#v", params)
+
+	var document string
+	err := api.CallWithErrorParse("configuration.export", params, &document)
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(dataListID(params))
+	d.Set("document", document)
+
+	return nil
+}
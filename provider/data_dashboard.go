@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// Dashboard represent Zabbix dashboard object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/dashboard/object
+type Dashboard struct {
+	DashboardID string `json:"dashboardid"`
+	Name        string `json:"name"`
+	UserID      string `json:"userid"`
+	Private     int    `json:"private,string"`
+}
+
+// dataDashboard terraform data handler
+func dataDashboard() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataDashboardRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the dashboard.",
+				Required:     true,
+			},
+			"userid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "ID of the dashboard owner.",
+				Computed:    true,
+			},
+			"private": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the dashboard is private.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataDashboardRead terraform data resource read handler
+func dataDashboardRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	var t Dashboard
+	if err := getByName(api, "dashboard.get", "name", d.Get("name").(string), "dashboard", &t); err != nil {
+		return err
+	}
+
+	log.Debug("Got dashboard: %s", t.DashboardID)
+
+	d.SetId(t.DashboardID)
+	d.Set("name", t.Name)
+	d.Set("userid", t.UserID)
+	d.Set("private", t.Private == 1)
+
+	return nil
+}
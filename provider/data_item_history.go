@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// itemLastValue subset of the item object holding the latest collected value
+type itemLastValue struct {
+	ItemID    string           `json:"itemid"`
+	ValueType zabbix.ValueType `json:"value_type,string"`
+	LastValue string           `json:"lastvalue"`
+	LastClock int64            `json:"lastclock,string"`
+}
+
+// History represent Zabbix history object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/history/object
+type History struct {
+	ItemID string `json:"itemid"`
+	Clock  int64  `json:"clock,string"`
+	Value  string `json:"value"`
+}
+
+// dataItemHistory terraform data handler
+func dataItemHistory() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataItemHistoryRead),
+
+		Schema: map[string]*schema.Schema{
+			"itemid": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Item ID",
+				Required:     true,
+			},
+			"limit": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(1, 1000),
+				Description:  "Number of history values to return, newest first",
+				Optional:     true,
+				Default:      1,
+			},
+			"lastvalue": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Last value collected for the item",
+				Computed:    true,
+			},
+			"lastclock": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "Time the last value was collected (unix timestamp)",
+				Computed:    true,
+			},
+			"values": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "History values, newest first",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"clock": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"value": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataItemHistoryRead terraform data resource read handler
+func dataItemHistoryRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	var items []itemLastValue
+	err := api.CallWithErrorParse("item.get", zabbix.Params{
+		"output":  []string{"itemid", "value_type", "lastvalue", "lastclock"},
+		"itemids": d.Get("itemid").(string),
+	}, &items)
+
+	if err != nil {
+		return err
+	}
+
+	if len(items) != 1 {
+		return errors.New("no item found")
+	}
+	item := items[0]
+
+	log.Debug("Got item last value: %s", item.ItemID)
+
+	values := []interface{}{}
+
+	// history.get is only needed when more than the last value is requested
+	if limit := d.Get("limit").(int); limit > 1 {
+		var history []History
+		err = api.CallWithErrorParse("history.get", zabbix.Params{
+			"output":    "extend",
+			"history":   int(item.ValueType),
+			"itemids":   item.ItemID,
+			"sortfield": "clock",
+			"sortorder": "DESC",
+			"limit":     limit,
+		}, &history)
+
+		if err != nil {
+			return err
+		}
+
+		for _, h := range history {
+			values = append(values, map[string]interface{}{
+				"clock": h.Clock,
+				"value": h.Value,
+			})
+		}
+	} else if item.LastClock > 0 {
+		values = append(values, map[string]interface{}{
+			"clock": item.LastClock,
+			"value": item.LastValue,
+		})
+	}
+
+	d.SetId(item.ItemID)
+	d.Set("lastvalue", item.LastValue)
+	d.Set("lastclock", item.LastClock)
+	d.Set("values", values)
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// Maintenance represent Zabbix maintenance object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/maintenance/object
+type Maintenance struct {
+	MaintenanceID   string              `json:"maintenanceid"`
+	Name            string              `json:"name"`
+	Description     string              `json:"description"`
+	ActiveSince     int64               `json:"active_since,string"`
+	ActiveTill      int64               `json:"active_till,string"`
+	MaintenanceType int                 `json:"maintenance_type,string"`
+	Hosts           []zabbix.Host       `json:"hosts,omitempty"`
+	Groups          zabbix.HostGroupIDs `json:"groups,omitempty"`
+	HostGroups      zabbix.HostGroupIDs `json:"hostgroups,omitempty"`
+}
+
+// Maintenances is an array of Maintenance
+type Maintenances []Maintenance
+
+// maintenancesGet wrapper for maintenance.get
+func maintenancesGet(api *zabbix.API, params zabbix.Params) (res Maintenances, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("maintenance.get", params, &res)
+	return
+}
+
+// dataMaintenance terraform data handler
+func dataMaintenance() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataMaintenanceRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the maintenance.",
+				Required:     true,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Description of the maintenance.",
+				Computed:    true,
+			},
+			"active_since": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "Time when the maintenance becomes active (unix timestamp).",
+				Computed:    true,
+			},
+			"active_till": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "Time when the maintenance stops being active (unix timestamp).",
+				Computed:    true,
+			},
+			"collect_data": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether data is collected during the maintenance.",
+				Computed:    true,
+			},
+			"hosts": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Host IDs under maintenance.",
+				Computed:    true,
+			},
+			"groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Host Group IDs under maintenance.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataMaintenanceRead terraform data resource read handler
+func dataMaintenanceRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"selectHosts": []string{"hostid"},
+		"filter": map[string]interface{}{
+			"name": d.Get("name"),
+		},
+	}
+
+	// host groups were renamed in 6.2
+	if api.Config.Version >= 60200 {
+		params["selectHostGroups"] = []string{"groupid"}
+	} else {
+		params["selectGroups"] = []string{"groupid"}
+	}
+
+	maintenances, err := maintenancesGet(api, params)
+
+	if err != nil {
+		return err
+	}
+
+	if len(maintenances) < 1 {
+		return errors.New("no maintenance found")
+	}
+	if len(maintenances) > 1 {
+		return errors.New("multiple maintenances found")
+	}
+	t := maintenances[0]
+
+	log.Debug("Got maintenance: %s", t.MaintenanceID)
+
+	hosts := schema.NewSet(schema.HashString, []interface{}{})
+	for _, v := range t.Hosts {
+		hosts.Add(v.HostID)
+	}
+
+	groups := t.Groups
+	if api.Config.Version >= 60200 {
+		groups = t.HostGroups
+	}
+
+	d.SetId(t.MaintenanceID)
+	d.Set("name", t.Name)
+	d.Set("description", t.Description)
+	d.Set("active_since", t.ActiveSince)
+	d.Set("active_till", t.ActiveTill)
+	d.Set("collect_data", t.MaintenanceType == 0)
+	d.Set("hosts", hosts)
+	d.Set("groups", flattenHostGroupIds(groups))
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// MediaType represent Zabbix media type object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/mediatype/object
+type MediaType struct {
+	MediaTypeID string `json:"mediatypeid"`
+	Name        string `json:"name"`
+	Type        int    `json:"type,string"`
+	Status      int    `json:"status,string"`
+}
+
+// dataMediaType terraform data handler
+func dataMediaType() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataMediaTypeRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the media type.",
+				Required:     true,
+			},
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Transport used by the media type, one of: email, script, sms, webhook.",
+				Computed:    true,
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the media type is enabled.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataMediaTypeRead terraform data resource read handler
+func dataMediaTypeRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	// media types were keyed on description before 4.4
+	field := "name"
+	if api.Config.Version < 40400 {
+		field = "description"
+	}
+
+	var t MediaType
+	if err := getByName(api, "mediatype.get", field, d.Get("name").(string), "media type", &t); err != nil {
+		return err
+	}
+
+	log.Debug("Got media type: %s", t.MediaTypeID)
+
+	d.SetId(t.MediaTypeID)
+	setEnum(d, "type", t.Type, MEDIA_TYPE, MEDIA_TYPE_REV)
+	d.Set("enabled", t.Status == 0)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// PROXY_GROUP_STATE states of a proxy group
+var PROXY_GROUP_STATE = map[string]int{
+	"unknown":    0,
+	"offline":    1,
+	"recovering": 2,
+	"online":     3,
+	"degrading":  4,
+}
+var PROXY_GROUP_STATE_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range PROXY_GROUP_STATE {
+		PROXY_GROUP_STATE_REV[v] = k
+	}
+	return false
+}()
+
+// ProxyGroup represent Zabbix proxy group object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/proxygroup/object
+type ProxyGroup struct {
+	ProxyGroupID  string `json:"proxy_groupid"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	FailoverDelay string `json:"failover_delay"`
+	MinOnline     string `json:"min_online"`
+	State         int    `json:"state,string"`
+}
+
+// dataProxyGroup terraform data handler
+func dataProxyGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataProxyGroupRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Name of the proxy group.",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Required:     true,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Description of the proxy group.",
+				Computed:    true,
+			},
+			"failover_delay": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Failover period for each proxy in the group to have online/offline state.",
+				Computed:    true,
+			},
+			"min_online": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Minimum number of online proxies required for the group to be online.",
+				Computed:    true,
+			},
+			"state": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "State of the proxy group, one of: unknown, offline, recovering, online, degrading.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataProxyGroupRead read handler for data resource
+func dataProxyGroupRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	if err := requireVersion(api, 70000, "proxy groups"); err != nil {
+		return err
+	}
+
+	var t ProxyGroup
+	if err := getByName(api, "proxygroup.get", "name", d.Get("name").(string), "proxy group", &t); err != nil {
+		return err
+	}
+
+	log.Debug("Got proxy group: %s", t.ProxyGroupID)
+
+	d.SetId(t.ProxyGroupID)
+	d.Set("name", t.Name)
+	d.Set("description", t.Description)
+	d.Set("failover_delay", t.FailoverDelay)
+	d.Set("min_online", t.MinOnline)
+	setEnum(d, "state", t.State, PROXY_GROUP_STATE, PROXY_GROUP_STATE_REV)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// ROLE_TYPE user types a role grants
+var ROLE_TYPE = map[string]int{
+	"user":        1,
+	"admin":       2,
+	"super_admin": 3,
+}
+var ROLE_TYPE_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range ROLE_TYPE {
+		ROLE_TYPE_REV[v] = k
+	}
+	return false
+}()
+
+// Role represent Zabbix role object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/role/object
+type Role struct {
+	RoleID   string `json:"roleid"`
+	Name     string `json:"name"`
+	Type     int    `json:"type,string"`
+	ReadOnly int    `json:"readonly,string"`
+}
+
+// Roles is an array of Role
+type Roles []Role
+
+// rolesGet wrapper for role.get
+func rolesGet(api *zabbix.API, params zabbix.Params) (res Roles, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("role.get", params, &res)
+	return
+}
+
+// dataRole terraform data handler
+func dataRole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataRoleRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the role.",
+				Required:     true,
+			},
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "User type, one of: user, admin, super_admin.",
+				Computed:    true,
+			},
+			"readonly": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the role is readonly.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataRoleRead terraform data resource read handler
+func dataRoleRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	var t Role
+	if err := getByName(api, "role.get", "name", d.Get("name").(string), "role", &t); err != nil {
+		return err
+	}
+
+	log.Debug("Got role: %s", t.RoleID)
+
+	d.SetId(t.RoleID)
+	d.Set("name", t.Name)
+	setEnum(d, "type", t.Type, ROLE_TYPE, ROLE_TYPE_REV)
+	d.Set("readonly", t.ReadOnly == 1)
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitDataByName(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	server.Seed("role", map[string]interface{}{"name": "tf-unit-role", "type": "3", "readonly": "0"})
+	server.Seed("action", map[string]interface{}{"name": "tf-unit-action", "eventsource": "1", "status": "0"})
+	server.Seed("role", map[string]interface{}{"name": "tf-unit-twice", "type": "1", "readonly": "0"})
+	server.Seed("role", map[string]interface{}{"name": "tf-unit-twice", "type": "2", "readonly": "0"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitDataByName(server.APIURL(), "tf-unit-role"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.zabbix_role.test", "type", "super_admin"),
+					resource.TestCheckResourceAttr("data.zabbix_action.test", "event_source", "discovery"),
+					resource.TestCheckResourceAttr("data.zabbix_action.test", "enabled", "true"),
+				),
+			},
+			{
+				Config:      testUnitDataByName(server.APIURL(), "tf-unit-twice"),
+				ExpectError: regexp.MustCompile("multiple roles found"),
+			},
+			{
+				Config:      testUnitDataByName(server.APIURL(), "tf-unit-missing"),
+				ExpectError: regexp.MustCompile("no role found"),
+			},
+		},
+	})
+}
+
+func testUnitDataByName(url, role string) string {
+	return fmt.Sprintf(`%s
+data "zabbix_role" "test" {
+	name = %q
+}
+data "zabbix_action" "test" {
+	name = "tf-unit-action"
+}
+`, testUnitProviderConfig(url), role)
+}
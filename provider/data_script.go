@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// SCRIPT_TYPE script types
+var SCRIPT_TYPE = map[string]int{
+	"script":  0,
+	"ipmi":    1,
+	"ssh":     2,
+	"telnet":  3,
+	"webhook": 5,
+	"url":     6,
+}
+var SCRIPT_TYPE_REV = map[int]string{}
+
+// SCRIPT_SCOPE where a script can be used
+var SCRIPT_SCOPE = map[string]int{
+	"action_operation":    1,
+	"manual_host_action":  2,
+	"manual_event_action": 4,
+}
+var SCRIPT_SCOPE_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range SCRIPT_TYPE {
+		SCRIPT_TYPE_REV[v] = k
+	}
+	for k, v := range SCRIPT_SCOPE {
+		SCRIPT_SCOPE_REV[v] = k
+	}
+	return false
+}()
+
+// Script represent Zabbix script object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/script/object
+type Script struct {
+	ScriptID string `json:"scriptid"`
+	Name     string `json:"name"`
+	Type     int    `json:"type,string"`
+	Scope    int    `json:"scope,string"`
+}
+
+// dataScript terraform data handler
+func dataScript() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataScriptRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the script.",
+				Required:     true,
+			},
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Script type, one of: script, ipmi, ssh, telnet, webhook, url.",
+				Computed:    true,
+			},
+			"scope": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Script scope, one of: action_operation, manual_host_action, manual_event_action.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataScriptRead terraform data resource read handler
+func dataScriptRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	var t Script
+	if err := getByName(api, "script.get", "name", d.Get("name").(string), "script", &t); err != nil {
+		return err
+	}
+
+	log.Debug("Got script: %s", t.ScriptID)
+
+	d.SetId(t.ScriptID)
+	d.Set("name", t.Name)
+	setEnum(d, "type", t.Type, SCRIPT_TYPE, SCRIPT_TYPE_REV)
+	setEnum(d, "scope", t.Scope, SCRIPT_SCOPE, SCRIPT_SCOPE_REV)
+
+	return nil
+}
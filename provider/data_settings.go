@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// Settings represent Zabbix settings object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/settings/object
+type Settings struct {
+	DefaultTimezone string `json:"default_timezone"`
+	DefaultLang     string `json:"default_lang"`
+	DefaultTheme    string `json:"default_theme"`
+	WorkPeriod      string `json:"work_period"`
+	SeverityName0   string `json:"severity_name_0"`
+	SeverityName1   string `json:"severity_name_1"`
+	SeverityName2   string `json:"severity_name_2"`
+	SeverityName3   string `json:"severity_name_3"`
+	SeverityName4   string `json:"severity_name_4"`
+	SeverityName5   string `json:"severity_name_5"`
+	URL             string `json:"url"`
+}
+
+// dataSettings terraform data handler
+func dataSettings() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataSettingsRead),
+
+		Schema: map[string]*schema.Schema{
+			"default_timezone": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "System default time zone.",
+				Computed:    true,
+			},
+			"default_lang": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "System default language.",
+				Computed:    true,
+			},
+			"default_theme": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "System default theme.",
+				Computed:    true,
+			},
+			"work_period": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Working time.",
+				Computed:    true,
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Frontend URL.",
+				Computed:    true,
+			},
+			"severity_names": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Custom severity names, indexed by severity level.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// dataSettingsRead terraform data resource read handler
+func dataSettingsRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	var settings Settings
+	err := api.CallWithErrorParse("settings.get", zabbix.Params{
+		"output": "extend",
+	}, &settings)
+
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Got settings: %+v", settings)
+
+	d.SetId("settings")
+	d.Set("default_timezone", settings.DefaultTimezone)
+	d.Set("default_lang", settings.DefaultLang)
+	d.Set("default_theme", settings.DefaultTheme)
+	d.Set("work_period", settings.WorkPeriod)
+	d.Set("url", settings.URL)
+	d.Set("severity_names", []string{
+		settings.SeverityName0,
+		settings.SeverityName1,
+		settings.SeverityName2,
+		settings.SeverityName3,
+		settings.SeverityName4,
+		settings.SeverityName5,
+	})
+
+	return nil
+}
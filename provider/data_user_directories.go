@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// UserDirectory represent Zabbix user directory object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/userdirectory/object
+type UserDirectory struct {
+	UserDirectoryID string `json:"userdirectoryid"`
+	Name            string `json:"name"`
+	IdpType         int    `json:"idp_type,string"`
+	ProvisionStatus int    `json:"provision_status,string"`
+}
+
+// UserDirectories is an array of UserDirectory
+type UserDirectories []UserDirectory
+
+// userDirectoriesGet wrapper for userdirectory.get
+func userDirectoriesGet(api *zabbix.API, params zabbix.Params) (res UserDirectories, err error) {
+	if err = requireVersion(api, 60200, "user directories"); err != nil {
+		return
+	}
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("userdirectory.get", params, &res)
+	return
+}
+
+// USER_DIRECTORY_SORT_FIELDS sort_field values of zabbix_user_directories and the userdirectory.get
+// sortfield of each, empty for those it can not sort by
+var USER_DIRECTORY_SORT_FIELDS = map[string]string{
+	"id":       "",
+	"name":     "name",
+	"idp_type": "",
+}
+
+// dataUserDirectories terraform plural data handler
+func dataUserDirectories() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataUserDirectoriesRead),
+
+		Schema: mergeSchemas(pagingSchema(), searchSchema(true), sortSchema(USER_DIRECTORY_SORT_FIELDS), map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the user directory to filter on, * may be used as a wildcard.",
+				Optional:     true,
+			},
+			"ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Matching user directory IDs.",
+				Computed:    true,
+			},
+			"user_directories": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Matching user directories.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "ID of the user directory.",
+							Computed:    true,
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Name of the user directory.",
+							Computed:    true,
+						},
+						"idp_type": &schema.Schema{
+							Type:        schema.TypeInt,
+							Description: "Type of the authentication protocol. Possible values: 1 - LDAP; 2 - SAML.",
+							Computed:    true,
+						},
+						"provisioning": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether JIT provisioning is enabled.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		}),
+	}
+}
+
+// dataUserDirectoriesRead terraform plural data resource read handler
+func dataUserDirectoriesRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{}
+
+	if v, ok := d.GetOk("name"); ok {
+		params["search"] = map[string]interface{}{
+			"name": v,
+		}
+	}
+	applySearch(d, params)
+	log.Debug("performing data lookup with params: %#v", params)
+
+	ids, list, err := pagedGet(d, "userdirectoryid", USER_DIRECTORY_SORT_FIELDS, params, func(params zabbix.Params) ([]map[string]interface{}, error) {
+		directories, err := userDirectoriesGet(api, params)
+		entries := make([]map[string]interface{}, len(directories))
+		for i, t := range directories {
+			// idp_type was added in 6.4, everything before is ldap
+			idpType := t.IdpType
+			if idpType == 0 {
+				idpType = 1
+			}
+
+			entries[i] = map[string]interface{}{
+				"id":           t.UserDirectoryID,
+				"name":         t.Name,
+				"idp_type":     idpType,
+				"provisioning": t.ProvisionStatus == 1,
+			}
+		}
+		return entries, err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(dataListID(params))
+	d.Set("ids", ids)
+	d.Set("user_directories", list)
+
+	return nil
+}
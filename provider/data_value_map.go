@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// ValueMapMapping represent Zabbix value map mapping object
+type ValueMapMapping struct {
+	Value    string `json:"value"`
+	NewValue string `json:"newvalue"`
+}
+
+// ValueMap represent Zabbix value map object
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/valuemap/object
+type ValueMap struct {
+	ValueMapID string            `json:"valuemapid"`
+	HostID     string            `json:"hostid,omitempty"`
+	Name       string            `json:"name"`
+	Mappings   []ValueMapMapping `json:"mappings,omitempty"`
+}
+
+// ValueMaps is an array of ValueMap
+type ValueMaps []ValueMap
+
+// valueMapsGet wrapper for valuemap.get
+func valueMapsGet(api *zabbix.API, params zabbix.Params) (res ValueMaps, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("valuemap.get", params, &res)
+	return
+}
+
+// dataValueMap terraform data handler
+func dataValueMap() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataValueMapRead),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the value map.",
+				Required:     true,
+			},
+			"hostid": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "ID of the host or template the value map belongs to (zabbix >= 5.4).",
+				Optional:     true,
+			},
+			"mapping": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Value mappings.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"value": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"newvalue": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataValueMapRead terraform data resource read handler
+func dataValueMapRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"selectMappings": "extend",
+		"filter": map[string]interface{}{
+			"name": d.Get("name"),
+		},
+	}
+
+	// value maps moved from global to host level in 5.4
+	if v, ok := d.GetOk("hostid"); ok {
+		if err := requireVersion(api, 50400, "hostid on value maps"); err != nil {
+			return errorAt("hostid", err)
+		}
+		params["hostids"] = v
+	}
+
+	valueMaps, err := valueMapsGet(api, params)
+
+	if err != nil {
+		return err
+	}
+
+	if len(valueMaps) < 1 {
+		return errors.New("no value map found")
+	}
+	if len(valueMaps) > 1 {
+		return errors.New("multiple value maps found")
+	}
+	t := valueMaps[0]
+
+	log.Debug("Got value map: %s", t.ValueMapID)
+
+	mappings := make([]interface{}, len(t.Mappings))
+	for i, v := range t.Mappings {
+		mappings[i] = map[string]interface{}{
+			"value":    v.Value,
+			"newvalue": v.NewValue,
+		}
+	}
+
+	d.SetId(t.ValueMapID)
+	d.Set("name", t.Name)
+	d.Set("mapping", mappings)
+
+	return nil
+}
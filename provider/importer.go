@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// nameLookup find the ids of objects with the given unique name
+type nameLookup func(api *zabbix.API, name string) ([]string, error)
+
+// LOCAL_DEFAULTS provider side attributes no read can fill, imported objects get
+// their defaults so generated configs and the next plan match the state
+var LOCAL_DEFAULTS = map[string]interface{}{
+	"deletion_protection": false,
+	"force":               false,
+	"safe_delete":         false,
+	"templates_clear":     true,
+}
+
+// setLocalDefaults set the defaults of the provider side attributes a resource has
+func setLocalDefaults(d *schema.ResourceData) {
+	for k, v := range LOCAL_DEFAULTS {
+		// fails for attributes the resource does not have
+		d.Set(k, v)
+	}
+}
+
+// importByName importer taking either an object id or name=<unique name>
+func importByName(lookup nameLookup) *schema.ResourceImporter {
+	return &schema.ResourceImporter{
+		StateContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+			setLocalDefaults(d)
+			if !strings.HasPrefix(d.Id(), "name=") {
+				return []*schema.ResourceData{d}, nil
+			}
+			name := strings.TrimPrefix(d.Id(), "name=")
+
+			api, release := apiWithContext(ctx, m)
+			defer release()
+
+			ids, err := lookup(api, name)
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) < 1 {
+				return nil, fmt.Errorf("no object named %q found", name)
+			}
+			if len(ids) > 1 {
+				return nil, fmt.Errorf("multiple objects named %q found", name)
+			}
+
+			log.Debug("Import of %q resolved to id %s", name, ids[0])
+			d.SetId(ids[0])
+
+			return []*schema.ResourceData{d}, nil
+		},
+	}
+}
+
+// lookupByField nameLookup filtering a get method on a name field
+func lookupByField(method, idField, nameField string) nameLookup {
+	return func(api *zabbix.API, name string) ([]string, error) {
+		var res []map[string]interface{}
+		err := api.CallWithErrorParse(method, zabbix.Params{
+			"output": []string{idField},
+			"filter": map[string]interface{}{
+				nameField: name,
+			},
+		}, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, len(res))
+		for i, r := range res {
+			ids[i] = r[idField].(string)
+		}
+		return ids, nil
+	}
+}
+
+// lookupProxyByName proxy names are stored as host before 7.0, proxiesGet translates
+func lookupProxyByName(api *zabbix.API, name string) ([]string, error) {
+	proxies, err := proxiesGet(api, zabbix.Params{
+		"output": []string{"proxyid"},
+		"filter": map[string]interface{}{
+			"name": name,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(proxies))
+	for i, p := range proxies {
+		ids[i] = p.ProxyID
+	}
+	return ids, nil
+}
+
+// lookupUserByName users were identified by alias before 5.4
+func lookupUserByName(api *zabbix.API, name string) ([]string, error) {
+	field := "username"
+	if api.Config.Version < 50400 {
+		field = "alias"
+	}
+	return lookupByField("user.get", "userid", field)(api, name)
+}
@@ -1,8 +1,11 @@
 package provider
 
 import (
+	"fmt"
+	"io"
 	logger "log"
 	"os"
+	"regexp"
 )
 
 // this changes and no longer works if accessed later
@@ -11,19 +14,66 @@ var stderr = os.Stderr
 type Log struct{}
 
 func (Log) Trace(msg string, args ...interface{}) {
-	logger.Printf("[TRACE] "+msg, args...)
+	logf("[TRACE] ", msg, args...)
 }
 func (Log) Debug(msg string, args ...interface{}) {
-	logger.Printf("[DEBUG] "+msg, args...)
+	logf("[DEBUG] ", msg, args...)
 }
 func (Log) Info(msg string, args ...interface{}) {
-	logger.Printf("[INFO] "+msg, args...)
+	logf("[INFO] ", msg, args...)
 }
 func (Log) Warn(msg string, args ...interface{}) {
-	logger.Printf("[WARN] "+msg, args...)
+	logf("[WARN] ", msg, args...)
 }
 func (Log) Error(msg string, args ...interface{}) {
-	logger.Printf("[ERROR] "+msg, args...)
+	logf("[ERROR] ", msg, args...)
+}
+
+// logf print a message of the provider itself, masking secrets like those of
+// the api client traffic
+func logf(level, msg string, args ...interface{}) {
+	logger.Print(string(redact([]byte(level + fmt.Sprintf(msg, args...)))))
 }
 
 var log = &Log{}
+
+// secret values are masked before api requests and responses reach the log
+var SECRET_PATTERNS = []*regexp.Regexp{
+	regexp.MustCompile(`("(?:auth|password|passwd|current_passwd|token|tls_psk|snmp3_authpassphrase|snmp3_privpassphrase|bind_password|ipmi_password|http_password|ssl_key_password)"\s*:\s*)"(?:[^"\\]|\\.)*"`),
+	// session ids and api tokens returned by user.login
+	regexp.MustCompile(`("result"\s*:\s*)"[0-9a-f]{32,64}"`),
+}
+
+// secret user macros (type 1) only differ from others by their type, their
+// flat objects are matched as a whole, strings may hold the braces of the name
+var (
+	MACRO_OBJECT      = regexp.MustCompile(`\{(?:[^{}"]|"(?:[^"\\]|\\.)*")*"macro"\s*:(?:[^{}"]|"(?:[^"\\]|\\.)*")*\}`)
+	SECRET_MACRO_TYPE = regexp.MustCompile(`"type"\s*:\s*"?1"?\s*[,}]`)
+	MACRO_VALUE       = regexp.MustCompile(`("value"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// redactWriter log writer masking secrets in the json-rpc traffic logged by the api client
+type redactWriter struct {
+	w io.Writer
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(redact(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redact mask secret fields and the values of secret macros in json
+func redact(p []byte) []byte {
+	out := p
+	for _, re := range SECRET_PATTERNS {
+		out = re.ReplaceAll(out, []byte(`$1"<redacted>"`))
+	}
+	return MACRO_OBJECT.ReplaceAllFunc(out, func(macro []byte) []byte {
+		if !SECRET_MACRO_TYPE.Match(macro) {
+			return macro
+		}
+		return MACRO_VALUE.ReplaceAll(macro, []byte(`$1"<redacted>"`))
+	})
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"bytes"
+	logger "log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUnitRedactWriter(t *testing.T) {
+	cases := map[string]string{
+		`{"ipmi_password":"hunter2","http_password":"hunter2","ssl_key_password":"hunter2"}`: `{"ipmi_password":"<redacted>","http_password":"<redacted>","ssl_key_password":"<redacted>"}`,
+
+		// secret macros are masked, text ones kept
+		`{"method":"host.create","params":{"macros":[{"macro":"{$SECRET}","value":"hunter2","type":"1"},{"macro":"{$TEXT}","value":"kept","type":"0"}]}}`: `{"method":"host.create","params":{"macros":[{"macro":"{$SECRET}","value":"<redacted>","type":"1"},{"macro":"{$TEXT}","value":"kept","type":"0"}]}}`,
+		`{"result":[{"hostmacroid":"1","macro":"{$SECRET}","value":"hunter2","type":1}]}`:                                                                 `{"result":[{"hostmacroid":"1","macro":"{$SECRET}","value":"<redacted>","type":1}]}`,
+
+		// other objects with a type 1 keep their value
+		`{"mappings":[{"type":"1","value":"10","newvalue":"high"}]}`: `{"mappings":[{"type":"1","value":"10","newvalue":"high"}]}`,
+	}
+
+	for in, want := range cases {
+		var out bytes.Buffer
+		if _, err := (redactWriter{w: &out}).Write([]byte(in)); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(out.String()); got != want {
+			t.Errorf("redacted %s\n got %s\nwant %s", in, got, want)
+		}
+	}
+}
+
+func TestUnitLogRedacted(t *testing.T) {
+	var out bytes.Buffer
+	logger.SetOutput(&out)
+	defer logger.SetOutput(os.Stderr)
+
+	log.Debug("request: %s", `{"method":"user.login","params":{"username":"Admin","password":"hunter2"}}`)
+
+	if strings.Contains(out.String(), "hunter2") || !strings.Contains(out.String(), `"password":"<redacted>"`) {
+		t.Errorf("expected the password to be redacted, got %s", out.String())
+	}
+}
@@ -1,11 +1,15 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	logger "log"
+	"net/url"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
-	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -35,9 +39,16 @@ func Provider() *schema.Provider {
 				Type:         schema.TypeString,
 				Required:     false,
 				Optional:     true,
-				Description:  "Zabbix API token",
+				Description:  "Zabbix API token (Zabbix 5.4+), used instead of username/password",
 				ValidateFunc: validation.StringIsNotWhiteSpace,
 				DefaultFunc:  schema.MultiEnvDefaultFunc([]string{"ZABBIX_TOKEN", "ZABBIX_API_TOKEN"}, nil),
+				Sensitive:    true,
+			},
+			"session_cache_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "File caching login sessions between runs, user.login is only called when the cached session is rejected",
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_SESSION_CACHE_FILE", ""),
 			},
 			"url": &schema.Schema{
 				Type:         schema.TypeString,
@@ -46,11 +57,24 @@ func Provider() *schema.Provider {
 				DefaultFunc:  schema.MultiEnvDefaultFunc([]string{"ZABBIX_URL", "ZABBIX_SERVER_URL"}, nil),
 				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
 			},
+			"http_basic_user": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "HTTP basic auth user for a proxy in front of the api, sent in addition to zabbix auth",
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_HTTP_BASIC_USER", ""),
+			},
+			"http_basic_password": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "HTTP basic auth password for a proxy in front of the api",
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_HTTP_BASIC_PASSWORD", ""),
+			},
 			"tls_insecure": &schema.Schema{
 				Type:        schema.TypeBool,
 				Description: "Disable TLS certificate checking (for testing use only)",
 				Optional:    true,
-				Default:     false,
+				DefaultFunc: schema.EnvDefaultFunc("ZABBIX_TLS_INSECURE", false),
 			},
 			"serialize": &schema.Schema{
 				Type:        schema.TypeBool,
@@ -58,21 +82,121 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "Serialize API requests, if required due to API race conditions",
 			},
+			"timeout": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0s",
+				ValidateFunc: validateDuration,
+				Description:  "Timeout of a single API call including its retries, 0s disables the timeout",
+			},
+			"parallel_requests": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of API calls in flight at once, 0 disables the limit",
+			},
+			"preflight_check": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Verify credentials and api access with an authenticated call when the provider is configured",
+			},
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Retries of API calls failing with connection errors, 5xx responses or an unavailable Zabbix server/database. Calls changing objects are only retried when the connection could not be made",
+			},
+			"retry_delay": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1s",
+				ValidateFunc: validateDuration,
+				Description:  "Base delay before retrying, doubled on every attempt",
+			},
+			"retry_jitter": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Add a random delay of up to retry_delay to every retry",
+			},
+			"cache_reads": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Answer repeated identical get calls from memory until the next call changing objects",
+			},
+			"skip_read_after_write": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Keep the configured values as state after create and update instead of reading the object back, when no computed attribute is unknown",
+			},
+			"batch_reads": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Coalesce concurrent reads of single objects of the same type into one get call",
+			},
+			"batch_window": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "20ms",
+				ValidateFunc: validateDuration,
+				Description:  "How long reads are collected before a batched get call is sent",
+			},
+			"requests_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Maximum API calls per second, 0 disables rate limiting",
+			},
+			"burst": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Number of API calls allowed to exceed requests_per_second at once",
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"zabbix_host":        dataHost(),
-			"zabbix_application": dataApplication(),
-			"zabbix_proxy":       dataProxy(),
-			"zabbix_hostgroup":   dataHostgroup(),
-			"zabbix_template":    dataTemplate(),
-			"zabbix_user":        dataUser(),
+			"zabbix_host":                 dataHost(),
+			"zabbix_application":          dataApplication(),
+			"zabbix_proxy":                dataProxy(),
+			"zabbix_proxies":              dataProxies(),
+			"zabbix_proxy_group":          dataProxyGroup(),
+			"zabbix_hostgroup":            dataHostgroup(),
+			"zabbix_template":             dataTemplate(),
+			"zabbix_templates":            dataTemplates(),
+			"zabbix_user":                 dataUser(),
+			"zabbix_users":                dataUsers(),
+			"zabbix_user_groups":          dataUserGroups(),
+			"zabbix_user_directories":     dataUserDirectories(),
+			"zabbix_trigger":              dataTrigger(),
+			"zabbix_item":                 dataItem(),
+			"zabbix_item_history":         dataItemHistory(),
+			"zabbix_role":                 dataRole(),
+			"zabbix_media_type":           dataMediaType(),
+			"zabbix_script":               dataScript(),
+			"zabbix_action":               dataAction(),
+			"zabbix_maintenance":          dataMaintenance(),
+			"zabbix_dashboard":            dataDashboard(),
+			"zabbix_value_map":            dataValueMap(),
+			"zabbix_lld_rule":             dataLLD(),
+			"zabbix_settings":             dataSettings(),
+			"zabbix_configuration_export": dataConfigurationExport(),
 		},
-		ResourcesMap: map[string]*schema.Resource{
+		ResourcesMap: withStateUpgraders(map[string]*schema.Resource{
 			"zabbix_trigger":       resourceTrigger(),
 			"zabbix_proto_trigger": resourceProtoTrigger(),
 			"zabbix_template":      resourceTemplate(),
+			"zabbix_template_yaml": resourceTemplateYAML(),
 			"zabbix_hostgroup":     resourceHostgroup(),
 			"zabbix_host":          resourceHost(),
+			"zabbix_proto_host":    resourceProtoHost(),
 			"zabbix_application":   resourceApplication(),
 
 			"zabbix_graph":       resourceGraph(),
@@ -119,41 +243,173 @@ func Provider() *schema.Provider {
 			"zabbix_proto_item_dependent": resourceProtoItemDependent(),
 			"zabbix_lld_dependent":        resourceLLDDependent(),
 
-			"zabbix_user":       resourceUser(),
-			"zabbix_user_group": resourceUserGroup(),
+			"zabbix_user":                 resourceUser(),
+			"zabbix_user_group":           resourceUserGroup(),
+			"zabbix_user_directory_group": resourceUserDirectoryGroup(),
 
 			"zabbix_proxy": resourceProxy(),
-		},
-		ConfigureFunc: providerConfigure,
+
+			"zabbix_media_type":  resourceMediaType(),
+			"zabbix_action":      resourceAction(),
+			"zabbix_maintenance": resourceMaintenance(),
+			"zabbix_service":     resourceService(),
+			"zabbix_dashboard":   resourceDashboard(),
+			"zabbix_map":         resourceMap(),
+
+			"zabbix_web_scenario": resourceWebScenario(),
+
+			"zabbix_global_macro": resourceGlobalMacro(),
+		}),
+		ConfigureContextFunc: providerConfigure,
 	}
 }
 
 // providerConfigure configure this provider
-func providerConfigure(d *schema.ResourceData) (meta interface{}, err error) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	meta, err := connectAPI(d)
+	return meta, errorDiagnostics(err)
+}
+
+// connectAPI connect and log in to the api configured in d
+func connectAPI(d *schema.ResourceData) (meta interface{}, err error) {
 	log.Trace("Started zabbix provider init")
-	l := logger.New(stderr, "[DEBUG] ", logger.LstdFlags)
+	l := logger.New(redactWriter{w: stderr}, "[DEBUG] ", logger.LstdFlags)
 
-	api, apierr := zabbix.NewAPI(zabbix.Config{
-		Url:         d.Get("url").(string),
+	// the version lookup in NewAPI bypasses our http client, so basic auth
+	// has to travel in the url for it
+	apiURL, err := url.Parse(d.Get("url").(string))
+	if err != nil {
+		return nil, errorAt("url", err)
+	}
+	if user := d.Get("http_basic_user").(string); user != "" {
+		apiURL.User = url.UserPassword(user, d.Get("http_basic_password").(string))
+	}
+
+	config := zabbix.Config{
+		Url:         apiURL.String(),
 		TlsNoVerify: d.Get("tls_insecure").(bool),
 		Log:         l,
-		Serialize:   d.Get("serialize").(bool),
-	})
+	}
+	api, apierr := zabbix.NewAPI(config)
 	if apierr != nil {
-		return nil, apierr
+		return nil, errorAt("url", fmt.Errorf("unable to reach zabbix api at %s, check url and tls_insecure: %s", d.Get("url").(string), apierr))
 	}
 
-	if d.Get("token").(string) != "" {
-		_, err = api.Token(d.Get("token").(string))
+	client, err := newHTTPClient(d)
+	if err != nil {
+		return nil, err
+	}
+	api.SetClient(client)
+	log.Debug("Connected to zabbix api version %s", versionString(api.Config.Version))
+
+	// an API token replaces the user.login session entirely
+	if token := d.Get("token").(string); token != "" {
+		if err := requireVersion(api, 50400, "API token authentication"); err != nil {
+			return nil, errorAt("token", err)
+		}
+		_, err = api.Token(token)
+	} else if d.Get("username").(string) != "" {
+		err = sessionLogin(api, d)
+		// expired sessions are renewed on the fly, api tokens can not be. From
+		// here on the transport owns the token, api.Auth is left as it is
+		transport := client.Transport.(*apiTransport)
+		transport.session.set(api.Auth)
+		transport.renewer = newSessionRenewer(api, d)
 	} else {
-		_, err = api.Login(d.Get("username").(string), d.Get("password").(string))
+		return nil, errors.New("either token or username and password must be configured")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("zabbix api login failed, check username and password: %s", err)
+	}
+
+	// a token is only checked on first use, a cheap authenticated call surfaces
+	// bad tokens and roles without api access before any resource is touched
+	if d.Get("preflight_check").(bool) {
+		_, err = api.CallWithError("user.get", zabbix.Params{
+			"output": []string{"userid"},
+			"limit":  1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("zabbix api pre-flight check failed, check the token and that the user role allows api access: %s", err)
+		}
 	}
+	setProviderOptions(api, providerOptions{
+		skipReadAfterWrite: d.Get("skip_read_after_write").(bool),
+		client:             client,
+		blank:              copyAPI(api),
+	})
 	meta = api
 	log.Trace("Started zabbix provider got error: %+v", err)
 
 	return
 }
 
+// TAG_OPERATOR get tags filter operators
+var TAG_OPERATOR = map[string]int{
+	"contains":   0,
+	"equals":     1,
+	"not_like":   2,
+	"not_equal":  3,
+	"exists":     4,
+	"not_exists": 5,
+}
+
+// schemaTags shared tag block of hosts, triggers and items
+func schemaTags() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Set:      hashTag,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:         schema.TypeString,
+					Optional:     true,
+					Computed:     true,
+					ValidateFunc: validation.StringIsNotWhiteSpace,
+					Description:  "Tag Name",
+				},
+				"key": &schema.Schema{
+					Type:         schema.TypeString,
+					Optional:     true,
+					Computed:     true,
+					ValidateFunc: validation.StringIsNotWhiteSpace,
+					Description:  "Tag Key",
+					Deprecated:   "key was renamed to name",
+				},
+				"value": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Tag Value",
+				},
+			},
+		},
+	}
+}
+
+// dataTagsSchema tag block of data sources, narrowing the lookup when set
+func dataTagsSchema() *schema.Schema {
+	s := schemaTags()
+	s.Computed = true
+	return s
+}
+
+// tagName name of a tag block, falling back to the deprecated key
+func tagName(m map[string]interface{}) string {
+	if name, _ := m["name"].(string); name != "" {
+		return name
+	}
+	key, _ := m["key"].(string)
+	return key
+}
+
+// hashTag tags are equal when name and value are, whichever spelling of the name is used
+func hashTag(i interface{}) int {
+	m := i.(map[string]interface{})
+	value, _ := m["value"].(string)
+	return hashString(tagName(m) + "V" + value)
+}
+
 // tagGenerate build tag structs from terraform inputs
 func tagGenerate(d *schema.ResourceData) (tags zabbix.Tags) {
 	set := d.Get("tag").(*schema.Set).List()
@@ -162,7 +418,7 @@ func tagGenerate(d *schema.ResourceData) (tags zabbix.Tags) {
 	for i := 0; i < len(set); i++ {
 		current := set[i].(map[string]interface{})
 		tags[i] = zabbix.Tag{
-			Tag:   current["key"].(string),
+			Tag:   tagName(current),
 			Value: current["value"].(string),
 		}
 	}
@@ -170,14 +426,33 @@ func tagGenerate(d *schema.ResourceData) (tags zabbix.Tags) {
 	return
 }
 
+// tagFilter build a get tags filter matching all configured tags, a tag without
+// value only has to exist
+func tagFilter(d *schema.ResourceData) []map[string]interface{} {
+	set := d.Get("tag").(*schema.Set).List()
+	filter := make([]map[string]interface{}, len(set))
+
+	for i, raw := range set {
+		current := raw.(map[string]interface{})
+		filter[i] = map[string]interface{}{
+			"tag":      tagName(current),
+			"value":    current["value"].(string),
+			"operator": TAG_OPERATOR["equals"],
+		}
+		if current["value"].(string) == "" {
+			filter[i]["operator"] = TAG_OPERATOR["exists"]
+		}
+	}
+
+	return filter
+}
+
 // flattenTags convert response to terraform input
 func flattenTags(list zabbix.Tags) *schema.Set {
-	set := schema.NewSet(func(i interface{}) int {
-		m := i.(map[string]interface{})
-		return hashcode.String(m["key"].(string) + "V" + m["value"].(string))
-	}, []interface{}{})
+	set := schema.NewSet(hashTag, []interface{}{})
 	for i := 0; i < len(list); i++ {
 		set.Add(map[string]interface{}{
+			"name":  list[i].Tag,
 			"key":   list[i].Tag,
 			"value": list[i].Value,
 		})
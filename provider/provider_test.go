@@ -1,20 +1,17 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/terraform-provider-zabbix/internal/mockzabbix"
 	"os"
 	"testing"
 )
 
-var testAccProviders map[string]terraform.ResourceProvider
-var testAccProvider *schema.Provider
-
-func init() {
-	testAccProvider = Provider()
-	testAccProviders = map[string]terraform.ResourceProvider{
-		"zabbix": testAccProvider,
-	}
+var testAccProviderFactories = map[string]func() (*schema.Provider, error){
+	"zabbix": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
 }
 
 func TestProvider(t *testing.T) {
@@ -24,7 +21,7 @@ func TestProvider(t *testing.T) {
 }
 
 func TestProvider_impl(t *testing.T) {
-	var _ terraform.ResourceProvider = Provider()
+	var _ *schema.Provider = Provider()
 }
 
 func testAccPreCheck(t *testing.T) {
@@ -37,3 +34,21 @@ func testAccPreCheck(t *testing.T) {
 		}
 	}
 }
+
+// testUnitServer mock zabbix server of the given version, closed with the test
+func testUnitServer(t *testing.T, version string) *mockzabbix.Server {
+	server := mockzabbix.New(version)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testUnitProviderConfig provider block of unit tests, talking to the mock server at url
+func testUnitProviderConfig(url string) string {
+	return fmt.Sprintf(`
+provider "zabbix" {
+	url      = %q
+	username = "Admin"
+	password = "zabbix"
+}
+`, url)
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// providerOptions provider settings resources need beyond the api client, meta
+// stays a *zabbix.API so they are looked up by it
+type providerOptions struct {
+	skipReadAfterWrite bool
+
+	// client of the api and a copy of it no call is sent through, both used to
+	// build clients with other timeouts
+	client *http.Client
+	blank  *zabbix.API
+
+	// context of the terraform operation the api is bound to, see apiWithContext
+	ctx context.Context
+}
+
+var (
+	optionsMu    sync.Mutex
+	optionsByAPI = map[*zabbix.API]providerOptions{}
+)
+
+// setProviderOptions record the settings of a configured provider
+func setProviderOptions(api *zabbix.API, o providerOptions) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	optionsByAPI[api] = o
+}
+
+// forgetProviderOptions drop the settings of an api no longer used
+func forgetProviderOptions(api *zabbix.API) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	delete(optionsByAPI, api)
+}
+
+// getProviderOptions settings of the provider owning meta, defaults if unknown
+func getProviderOptions(m interface{}) providerOptions {
+	api, ok := m.(*zabbix.API)
+	if !ok {
+		return providerOptions{}
+	}
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	return optionsByAPI[api]
+}
+
+// readAfterWrite finish a create or update with a read, unless skip_read_after_write
+// is set and the state is already complete: every computed attribute, nested
+// ones included, holds a value from the config or the previous state
+func readAfterWrite(d *schema.ResourceData, m interface{}, s map[string]*schema.Schema, read crudFunc) error {
+	if !getProviderOptions(m).skipReadAfterWrite || d.Id() == "" {
+		return read(d, m)
+	}
+
+	for k, field := range s {
+		v, ok := d.GetOk(k)
+		if field.Computed && !ok {
+			log.Debug("Reading %s after write, computed %s is unknown", d.Id(), k)
+			return read(d, m)
+		}
+		if ok && !nestedKnown(field, v) {
+			log.Debug("Reading %s after write, computed attributes of %s are unknown", d.Id(), k)
+			return read(d, m)
+		}
+	}
+	log.Trace("Skipping read of %s after write", d.Id())
+	return nil
+}
+
+// nestedKnown whether all computed attributes of the blocks of a list or set have a value
+func nestedKnown(field *schema.Schema, v interface{}) bool {
+	elem, ok := field.Elem.(*schema.Resource)
+	if !ok {
+		return true
+	}
+
+	var blocks []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		blocks = v
+	case *schema.Set:
+		blocks = v.List()
+	}
+	for _, raw := range blocks {
+		block, _ := raw.(map[string]interface{})
+		for k, sub := range elem.Schema {
+			if sub.Computed && isZeroValue(block[k]) {
+				return false
+			}
+			if !nestedKnown(sub, block[k]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isZeroValue whether a decoded attribute is unset or empty
+func isZeroValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case *schema.Set:
+		return v.Len() == 0
+	}
+	return false
+}
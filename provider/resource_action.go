@@ -0,0 +1,702 @@
+package provider
+
+import (
+	"context"
+
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// ACTION_EVENT_SOURCE event sources of actions with escalations, discovery and
+// autoregistration actions have operations the resource does not manage
+var ACTION_EVENT_SOURCE = map[string]int{
+	"trigger":  0,
+	"internal": 3,
+	"service":  4,
+}
+var ACTION_EVENT_SOURCE_REV = map[int]string{}
+
+// ACTION_EVAL_TYPE how the conditions of an action are combined
+var ACTION_EVAL_TYPE = map[string]int{
+	"and_or": 0,
+	"and":    1,
+	"or":     2,
+}
+var ACTION_EVAL_TYPE_REV = map[int]string{}
+
+// ACTION_CONDITION action condition types
+var ACTION_CONDITION = map[string]int{
+	"host_group":              0,
+	"host":                    1,
+	"trigger":                 2,
+	"event_name":              3,
+	"trigger_severity":        4,
+	"time_period":             6,
+	"host_ip":                 7,
+	"discovered_service_type": 8,
+	"discovered_service_port": 9,
+	"discovery_status":        10,
+	"uptime":                  11,
+	"received_value":          12,
+	"template":                13,
+	"problem_suppressed":      16,
+	"discovery_rule":          18,
+	"discovery_check":         19,
+	"proxy":                   20,
+	"discovery_object":        21,
+	"host_name":               22,
+	"event_type":              23,
+	"host_metadata":           24,
+	"event_tag":               25,
+	"event_tag_value":         26,
+	"service":                 27,
+	"service_name":            28,
+}
+var ACTION_CONDITION_REV = map[int]string{}
+
+// ACTION_CONDITION_OPERATOR action condition operators
+var ACTION_CONDITION_OPERATOR = map[string]int{
+	"equals":       0,
+	"not_equals":   1,
+	"contains":     2,
+	"not_contains": 3,
+	"in":           4,
+	"gte":          5,
+	"lte":          6,
+	"not_in":       7,
+	"matches":      8,
+	"not_matches":  9,
+	"yes":          10,
+	"no":           11,
+}
+var ACTION_CONDITION_OPERATOR_REV = map[int]string{}
+
+// ACTION_OPERATION action operation types
+var ACTION_OPERATION = map[string]int{
+	"send_message":   0,
+	"remote_command": 1,
+}
+var ACTION_OPERATION_REV = map[int]string{}
+
+// ACTION_RECOVERY_OPERATION operation types run once the problem is resolved
+var ACTION_RECOVERY_OPERATION = map[string]int{
+	"send_message":   0,
+	"remote_command": 1,
+	"notify_all":     11,
+}
+var ACTION_RECOVERY_OPERATION_REV = map[int]string{}
+
+// ACTION_UPDATE_OPERATION operation types run when the problem is updated
+var ACTION_UPDATE_OPERATION = map[string]int{
+	"send_message":   0,
+	"remote_command": 1,
+	"notify_all":     12,
+}
+var ACTION_UPDATE_OPERATION_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range ACTION_EVENT_SOURCE {
+		ACTION_EVENT_SOURCE_REV[v] = k
+	}
+	for k, v := range ACTION_EVAL_TYPE {
+		ACTION_EVAL_TYPE_REV[v] = k
+	}
+	for k, v := range ACTION_CONDITION {
+		ACTION_CONDITION_REV[v] = k
+	}
+	for k, v := range ACTION_CONDITION_OPERATOR {
+		ACTION_CONDITION_OPERATOR_REV[v] = k
+	}
+	for k, v := range ACTION_OPERATION {
+		ACTION_OPERATION_REV[v] = k
+	}
+	for k, v := range ACTION_RECOVERY_OPERATION {
+		ACTION_RECOVERY_OPERATION_REV[v] = k
+	}
+	for k, v := range ACTION_UPDATE_OPERATION {
+		ACTION_UPDATE_OPERATION_REV[v] = k
+	}
+	return false
+}()
+
+// actionObject action with its filter and operations
+type actionObject struct {
+	Action
+	EscPeriod  string            `json:"esc_period"`
+	Filter     actionFilter      `json:"filter"`
+	Operations []actionOperation `json:"operations"`
+	Recovery   []actionOperation `json:"recovery_operations"`
+	Update     []actionOperation `json:"update_operations"`
+}
+
+// actionFilter conditions of an action
+type actionFilter struct {
+	EvalType   int               `json:"evaltype,string"`
+	Conditions []actionCondition `json:"conditions"`
+}
+
+// actionCondition single condition of an action filter
+type actionCondition struct {
+	ConditionType int    `json:"conditiontype,string"`
+	Operator      int    `json:"operator,string"`
+	Value         string `json:"value"`
+	Value2        string `json:"value2"`
+}
+
+// actionOperation operation of an action, message and command settings are only
+// returned for operations of their type, escalation steps only for operations
+type actionOperation struct {
+	OperationType int    `json:"operationtype,string"`
+	EscPeriod     string `json:"esc_period"`
+	EscStepFrom   int    `json:"esc_step_from,string"`
+	EscStepTo     int    `json:"esc_step_to,string"`
+	OpMessage     *struct {
+		DefaultMsg  int    `json:"default_msg,string"`
+		Subject     string `json:"subject"`
+		Message     string `json:"message"`
+		MediaTypeID string `json:"mediatypeid"`
+	} `json:"opmessage"`
+	OpMessageGrp []struct {
+		UsrGrpID string `json:"usrgrpid"`
+	} `json:"opmessage_grp"`
+	OpMessageUsr []struct {
+		UserID string `json:"userid"`
+	} `json:"opmessage_usr"`
+	OpCommand *struct {
+		ScriptID string `json:"scriptid"`
+	} `json:"opcommand"`
+	OpCommandHst []struct {
+		HostID string `json:"hostid"`
+	} `json:"opcommand_hst"`
+	OpCommandGrp []struct {
+		GroupID string `json:"groupid"`
+	} `json:"opcommand_grp"`
+}
+
+// resourceAction terraform resource handler
+func resourceAction() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceActionCreate),
+		ReadContext:   contextCRUD(resourceActionRead),
+		UpdateContext: contextCRUD(resourceActionUpdate),
+		DeleteContext: contextCRUD(resourceActionDelete),
+		Importer:      importByName(lookupByField("action.get", "actionid", "name")),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the action.",
+				Required:     true,
+			},
+			"event_source": &schema.Schema{
+				Type:             schema.TypeString,
+				ValidateFunc:     validateEnum(ACTION_EVENT_SOURCE),
+				DiffSuppressFunc: suppressEnumDiff(ACTION_EVENT_SOURCE),
+				Description:      "Type of events handled. Possible values: trigger (0, default), internal (3), service (4).",
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "trigger",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the action is enabled.",
+				Optional:    true,
+				Default:     true,
+			},
+			"esc_period": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateTimeSuffix,
+				Description:  "Default duration of an escalation step, at least 60s.",
+				Optional:     true,
+				Default:      "1h",
+			},
+			"eval_type": &schema.Schema{
+				Type:             schema.TypeString,
+				ValidateFunc:     validateEnum(ACTION_EVAL_TYPE),
+				DiffSuppressFunc: suppressEnumDiff(ACTION_EVAL_TYPE),
+				Description:      "How conditions are combined. Possible values: and_or (0, default), and (1), or (2).",
+				Optional:         true,
+				Default:          "and_or",
+			},
+			"condition": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Conditions events have to match.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(ACTION_CONDITION),
+							DiffSuppressFunc: suppressEnumDiff(ACTION_CONDITION),
+							Description:      "Condition type, e.g. host_group, trigger_severity or event_tag.",
+							Required:         true,
+						},
+						"operator": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(ACTION_CONDITION_OPERATOR),
+							DiffSuppressFunc: suppressEnumDiff(ACTION_CONDITION_OPERATOR),
+							Description:      "Condition operator, e.g. equals, contains or gte.",
+							Optional:         true,
+							Default:          "equals",
+						},
+						"value": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Value to compare with, an id for host, group and template conditions.",
+							Optional:    true,
+						},
+						"value2": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Tag name of event_tag_value conditions.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"operation": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Operations run as the escalation reaches their steps.",
+				Optional:    true,
+				Elem:        actionOperationResource(ACTION_OPERATION, true),
+			},
+			"recovery_operation": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Operations run once the problem is resolved.",
+				Optional:    true,
+				Elem:        actionOperationResource(ACTION_RECOVERY_OPERATION, false),
+			},
+			"update_operation": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Operations run when the problem is updated, e.g. acknowledged. Not available for internal actions.",
+				Optional:    true,
+				Elem:        actionOperationResource(ACTION_UPDATE_OPERATION, false),
+			},
+		},
+		CustomizeDiff: actionCustomizeDiff,
+	}
+}
+
+// actionOperationResource schema of an operation of the given types, with its
+// escalation steps when escalation is set
+func actionOperationResource(types map[string]int, escalation bool) *schema.Resource {
+	s := map[string]*schema.Schema{
+		"type": &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateFunc:     validateEnum(types),
+			DiffSuppressFunc: suppressEnumDiff(types),
+			Description:      "Operation type, send_message (0, default), remote_command (1) or, for recovery and update operations, notify_all.",
+			Optional:         true,
+			Default:          "send_message",
+		},
+		"user_ids": &schema.Schema{
+			Type:        schema.TypeSet,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Users to send the message to.",
+			Optional:    true,
+		},
+		"user_group_ids": &schema.Schema{
+			Type:        schema.TypeSet,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "User groups to send the message to.",
+			Optional:    true,
+		},
+		"media_type_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Media type used to send the message, 0 for all media types.",
+			Optional:    true,
+			Default:     "0",
+		},
+		"default_message": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "Use the message templates of the media type instead of subject and message.",
+			Optional:    true,
+			Default:     true,
+		},
+		"subject": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Subject of the custom message.",
+			Optional:    true,
+		},
+		"message": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Body of the custom message.",
+			Optional:    true,
+		},
+		"script_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Global script run by remote_command operations.",
+			Optional:    true,
+		},
+		"current_host": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "Run the script on the host of the event.",
+			Optional:    true,
+			Default:     false,
+		},
+		"host_ids": &schema.Schema{
+			Type:        schema.TypeSet,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Hosts to run the script on.",
+			Optional:    true,
+		},
+		"host_group_ids": &schema.Schema{
+			Type:        schema.TypeSet,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Host groups to run the script on.",
+			Optional:    true,
+		},
+	}
+
+	if escalation {
+		s["esc_step_from"] = &schema.Schema{
+			Type:         schema.TypeInt,
+			ValidateFunc: validation.IntAtLeast(1),
+			Description:  "Escalation step the operation starts at.",
+			Optional:     true,
+			Default:      1,
+		}
+		s["esc_step_to"] = &schema.Schema{
+			Type:         schema.TypeInt,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Escalation step the operation ends at, 0 for infinitely.",
+			Optional:     true,
+			Default:      1,
+		}
+		s["esc_period"] = &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validateTimeSuffix,
+			Description:  "Duration of the escalation steps of the operation, 0 for the action esc_period.",
+			Optional:     true,
+			Default:      "0",
+		}
+	}
+
+	return &schema.Resource{Schema: s}
+}
+
+// actionCustomizeDiff check the settings each operation type needs
+func actionCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	for i, raw := range d.Get("operation").([]interface{}) {
+		op := raw.(map[string]interface{})
+		if err := validateActionOperation(op, ACTION_OPERATION); err != nil {
+			return fmt.Errorf("operation %d: %s", i, err)
+		}
+		from, to := op["esc_step_from"].(int), op["esc_step_to"].(int)
+		if to != 0 && to < from {
+			return fmt.Errorf("operation %d: esc_step_to %d is before esc_step_from %d", i, to, from)
+		}
+	}
+	for i, raw := range d.Get("recovery_operation").([]interface{}) {
+		if err := validateActionOperation(raw.(map[string]interface{}), ACTION_RECOVERY_OPERATION); err != nil {
+			return fmt.Errorf("recovery_operation %d: %s", i, err)
+		}
+	}
+
+	updates := d.Get("update_operation").([]interface{})
+	if len(updates) > 0 && enumValue(d.Get("event_source").(string), ACTION_EVENT_SOURCE) == ACTION_EVENT_SOURCE["internal"] {
+		return fmt.Errorf("internal actions have no update_operation")
+	}
+	for i, raw := range updates {
+		if err := validateActionOperation(raw.(map[string]interface{}), ACTION_UPDATE_OPERATION); err != nil {
+			return fmt.Errorf("update_operation %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// validateActionOperation check an operation has the targets of its type
+func validateActionOperation(op map[string]interface{}, types map[string]int) error {
+	if op["default_message"].(bool) && (op["subject"].(string) != "" || op["message"].(string) != "") {
+		return fmt.Errorf("subject and message need default_message = false")
+	}
+
+	switch enumName(op["type"].(string), types) {
+	case "send_message":
+		if op["user_ids"].(*schema.Set).Len() == 0 && op["user_group_ids"].(*schema.Set).Len() == 0 {
+			return fmt.Errorf("send_message needs user_ids or user_group_ids")
+		}
+	case "remote_command":
+		if op["script_id"].(string) == "" {
+			return fmt.Errorf("remote_command needs script_id")
+		}
+		if !op["current_host"].(bool) && op["host_ids"].(*schema.Set).Len() == 0 && op["host_group_ids"].(*schema.Set).Len() == 0 {
+			return fmt.Errorf("remote_command needs current_host, host_ids or host_group_ids")
+		}
+	case "notify_all":
+		// everyone notified by the operations receives it, through the same media
+		if op["user_ids"].(*schema.Set).Len() > 0 || op["user_group_ids"].(*schema.Set).Len() > 0 || op["media_type_id"].(string) != "0" {
+			return fmt.Errorf("notify_all takes no user_ids, user_group_ids or media_type_id")
+		}
+	}
+	return nil
+}
+
+// idObjects turn a set of ids into the [{field: id}] lists of the api
+func idObjects(field string, s *schema.Set) []map[string]string {
+	list := []map[string]string{}
+	for _, id := range s.List() {
+		list = append(list, map[string]string{field: id.(string)})
+	}
+	return list
+}
+
+// buildActionOperations api operations from the terraform blocks of key
+func buildActionOperations(d *schema.ResourceData, key string, types map[string]int, escalation bool) []map[string]interface{} {
+	operations := []map[string]interface{}{}
+	for _, raw := range d.Get(key).([]interface{}) {
+		operations = append(operations, buildActionOperation(raw.(map[string]interface{}), types, escalation))
+	}
+	return operations
+}
+
+// buildActionOperation api operation from a terraform block, escalation settings
+// are left out when escalation is false
+func buildActionOperation(op map[string]interface{}, types map[string]int, escalation bool) map[string]interface{} {
+	res := map[string]interface{}{
+		"operationtype": enumValue(op["type"].(string), types),
+	}
+	if escalation {
+		res["esc_step_from"] = op["esc_step_from"].(int)
+		res["esc_step_to"] = op["esc_step_to"].(int)
+		res["esc_period"] = op["esc_period"].(string)
+	}
+
+	message := map[string]interface{}{
+		"default_msg": 1,
+	}
+	if !op["default_message"].(bool) {
+		message["default_msg"] = 0
+		message["subject"] = op["subject"].(string)
+		message["message"] = op["message"].(string)
+	}
+
+	switch enumName(op["type"].(string), types) {
+	case "send_message":
+		message["mediatypeid"] = op["media_type_id"].(string)
+		res["opmessage"] = message
+		res["opmessage_usr"] = idObjects("userid", op["user_ids"].(*schema.Set))
+		res["opmessage_grp"] = idObjects("usrgrpid", op["user_group_ids"].(*schema.Set))
+	case "remote_command":
+		hosts := idObjects("hostid", op["host_ids"].(*schema.Set))
+		// hostid 0 stands for the host of the event
+		if op["current_host"].(bool) {
+			hosts = append(hosts, map[string]string{"hostid": "0"})
+		}
+		res["opcommand"] = map[string]interface{}{"scriptid": op["script_id"].(string)}
+		res["opcommand_hst"] = hosts
+		res["opcommand_grp"] = idObjects("groupid", op["host_group_ids"].(*schema.Set))
+	case "notify_all":
+		res["opmessage"] = message
+	}
+	return res
+}
+
+// flattenActionOperations terraform blocks of api operations
+func flattenActionOperations(ops []actionOperation, rev map[int]string, escalation bool) []interface{} {
+	list := []interface{}{}
+	for _, op := range ops {
+		list = append(list, flattenActionOperation(op, rev, escalation))
+	}
+	return list
+}
+
+// flattenActionOperation terraform block of an api operation
+func flattenActionOperation(op actionOperation, rev map[int]string, escalation bool) map[string]interface{} {
+	res := map[string]interface{}{
+		"type":            rev[op.OperationType],
+		"media_type_id":   "0",
+		"default_message": true,
+		"current_host":    false,
+	}
+	if res["type"] == "" {
+		res["type"] = strconv.Itoa(op.OperationType)
+	}
+	if escalation {
+		res["esc_step_from"] = op.EscStepFrom
+		res["esc_step_to"] = op.EscStepTo
+		res["esc_period"] = op.EscPeriod
+	}
+
+	if m := op.OpMessage; m != nil {
+		if m.MediaTypeID != "" {
+			res["media_type_id"] = m.MediaTypeID
+		}
+		res["default_message"] = m.DefaultMsg == 1
+		if m.DefaultMsg != 1 {
+			res["subject"] = m.Subject
+			res["message"] = m.Message
+		}
+	}
+	users := []interface{}{}
+	for _, u := range op.OpMessageUsr {
+		users = append(users, u.UserID)
+	}
+	groups := []interface{}{}
+	for _, g := range op.OpMessageGrp {
+		groups = append(groups, g.UsrGrpID)
+	}
+	res["user_ids"] = schema.NewSet(schema.HashString, users)
+	res["user_group_ids"] = schema.NewSet(schema.HashString, groups)
+
+	if op.OpCommand != nil {
+		res["script_id"] = op.OpCommand.ScriptID
+	}
+	hosts := []interface{}{}
+	for _, h := range op.OpCommandHst {
+		if h.HostID == "0" {
+			res["current_host"] = true
+			continue
+		}
+		hosts = append(hosts, h.HostID)
+	}
+	hostGroups := []interface{}{}
+	for _, g := range op.OpCommandGrp {
+		hostGroups = append(hostGroups, g.GroupID)
+	}
+	res["host_ids"] = schema.NewSet(schema.HashString, hosts)
+	res["host_group_ids"] = schema.NewSet(schema.HashString, hostGroups)
+
+	return res
+}
+
+// buildActionParams action create/update params from terraform data
+func buildActionParams(d *schema.ResourceData) zabbix.Params {
+	conditions := []map[string]interface{}{}
+	for _, raw := range d.Get("condition").([]interface{}) {
+		c := raw.(map[string]interface{})
+		condition := map[string]interface{}{
+			"conditiontype": enumValue(c["type"].(string), ACTION_CONDITION),
+			"operator":      enumValue(c["operator"].(string), ACTION_CONDITION_OPERATOR),
+			"value":         c["value"].(string),
+		}
+		// value2 is rejected by conditions other than event_tag_value
+		if v := c["value2"].(string); v != "" {
+			condition["value2"] = v
+		}
+		conditions = append(conditions, condition)
+	}
+
+	params := zabbix.Params{
+		"name":        d.Get("name").(string),
+		"eventsource": enumValue(d.Get("event_source").(string), ACTION_EVENT_SOURCE),
+		"status":      1,
+		"esc_period":  d.Get("esc_period").(string),
+		"filter": map[string]interface{}{
+			"evaltype":   enumValue(d.Get("eval_type").(string), ACTION_EVAL_TYPE),
+			"conditions": conditions,
+		},
+		"operations":          buildActionOperations(d, "operation", ACTION_OPERATION, true),
+		"recovery_operations": buildActionOperations(d, "recovery_operation", ACTION_RECOVERY_OPERATION, false),
+	}
+	// internal events are never updated
+	if params["eventsource"] != ACTION_EVENT_SOURCE["internal"] {
+		params["update_operations"] = buildActionOperations(d, "update_operation", ACTION_UPDATE_OPERATION, false)
+	}
+	if d.Get("enabled").(bool) {
+		params["status"] = 0
+	}
+	return params
+}
+
+// resourceActionCreate terraform resource create handler
+func resourceActionCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	// operations run global scripts since 5.4
+	if err := requireVersion(api, 50400, "zabbix_action"); err != nil {
+		return err
+	}
+
+	response, err := api.CallWithError("action.create", buildActionParams(d))
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	d.SetId(result["actionids"].([]interface{})[0].(string))
+	log.Trace("created action: %s", d.Id())
+
+	return readAfterWrite(d, m, resourceAction().Schema, resourceActionRead)
+}
+
+// resourceActionRead terraform resource read handler
+func resourceActionRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of action with id %s", d.Id())
+
+	var actions []actionObject
+	err := api.CallWithErrorParse("action.get", zabbix.Params{
+		"output":                   "extend",
+		"actionids":                d.Id(),
+		"selectFilter":             "extend",
+		"selectOperations":         "extend",
+		"selectRecoveryOperations": "extend",
+		"selectUpdateOperations":   "extend",
+	}, &actions)
+	if err != nil {
+		return err
+	}
+
+	if len(actions) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := actions[0]
+
+	log.Debug("Got action: %s", t.Action.ActionID)
+
+	conditions := []interface{}{}
+	for _, c := range t.Filter.Conditions {
+		condition := map[string]interface{}{
+			"type":     ACTION_CONDITION_REV[c.ConditionType],
+			"operator": ACTION_CONDITION_OPERATOR_REV[c.Operator],
+			"value":    c.Value,
+			"value2":   c.Value2,
+		}
+		if condition["type"] == "" {
+			condition["type"] = strconv.Itoa(c.ConditionType)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	d.Set("name", t.Name)
+	setEnum(d, "event_source", t.EventSource, ACTION_EVENT_SOURCE, ACTION_EVENT_SOURCE_REV)
+	d.Set("enabled", t.Status == 0)
+	d.Set("esc_period", t.EscPeriod)
+	setEnum(d, "eval_type", t.Filter.EvalType, ACTION_EVAL_TYPE, ACTION_EVAL_TYPE_REV)
+	d.Set("condition", conditions)
+	d.Set("operation", flattenActionOperations(t.Operations, ACTION_OPERATION_REV, true))
+	d.Set("recovery_operation", flattenActionOperations(t.Recovery, ACTION_RECOVERY_OPERATION_REV, false))
+	d.Set("update_operation", flattenActionOperations(t.Update, ACTION_UPDATE_OPERATION_REV, false))
+
+	return nil
+}
+
+// resourceActionUpdate terraform resource update handler
+func resourceActionUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := buildActionParams(d)
+	params["actionid"] = d.Id()
+	// the event source can not change
+	delete(params, "eventsource")
+
+	if _, err := api.CallWithError("action.update", params); err != nil {
+		return err
+	}
+
+	return readAfterWrite(d, m, resourceAction().Schema, resourceActionRead)
+}
+
+// resourceActionDelete terraform resource delete handler
+func resourceActionDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("action.delete", []string{d.Id()})
+	return err
+}
@@ -1,7 +1,7 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -17,12 +17,12 @@ var schemaAgent = map[string]*schema.Schema{
 // resourceItemAgent terraform resource for agent items
 func resourceItemAgent() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemAgentModFunc, itemAgentReadFunc),
-		Read:   itemGetReadWrapper(itemAgentReadFunc),
-		Update: itemGetUpdateWrapper(itemAgentModFunc, itemAgentReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemAgentModFunc, itemAgentReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemAgentReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemAgentModFunc, itemAgentReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaAgent),
@@ -30,12 +30,12 @@ func resourceItemAgent() *schema.Resource {
 }
 func resourceProtoItemAgent() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemAgentModFunc, itemAgentReadFunc),
-		Read:   protoItemGetReadWrapper(itemAgentReadFunc),
-		Update: protoItemGetUpdateWrapper(itemAgentModFunc, itemAgentReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemAgentModFunc, itemAgentReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemAgentReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemAgentModFunc, itemAgentReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaAgent),
@@ -43,12 +43,13 @@ func resourceProtoItemAgent() *schema.Resource {
 }
 func resourceLLDAgent() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldAgentModFunc, lldAgentReadFunc),
-		Read:   lldGetReadWrapper(lldAgentReadFunc),
-		Update: lldGetUpdateWrapper(lldAgentModFunc, lldAgentReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldAgentModFunc, lldAgentReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldAgentReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldAgentModFunc, lldAgentReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaAgent),
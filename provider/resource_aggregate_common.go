@@ -1,19 +1,19 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // terraform resource handler for item type
 func resourceItemAggregate() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemAggregateModFunc, itemAggregateReadFunc),
-		Read:   itemGetReadWrapper(itemAggregateReadFunc),
-		Update: itemGetUpdateWrapper(itemAggregateModFunc, itemAggregateReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemAggregateModFunc, itemAggregateReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemAggregateReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemAggregateModFunc, itemAggregateReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema),
@@ -21,12 +21,12 @@ func resourceItemAggregate() *schema.Resource {
 }
 func resourceProtoItemAggregate() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemAggregateModFunc, itemAggregateReadFunc),
-		Read:   protoItemGetReadWrapper(itemAggregateReadFunc),
-		Update: protoItemGetUpdateWrapper(itemAggregateModFunc, itemAggregateReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemAggregateModFunc, itemAggregateReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemAggregateReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemAggregateModFunc, itemAggregateReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemPrototypeSchema),
@@ -3,19 +3,19 @@ package provider
 import (
 	"errors"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // resourceApplication terraform resource handler
 func resourceApplication() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceApplicationCreate,
-		Read:   resourceApplicationRead,
-		Delete: resourceApplicationDelete,
+		CreateContext: contextCRUD(resourceApplicationCreate),
+		ReadContext:   contextCRUD(resourceApplicationRead),
+		DeleteContext: contextCRUD(resourceApplicationDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -40,7 +40,7 @@ func resourceApplication() *schema.Resource {
 // dataApplication terraform data handler
 func dataApplication() *schema.Resource {
 	return &schema.Resource{
-		Read: dataApplicationRead,
+		ReadContext: contextCRUD(dataApplicationRead),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -76,7 +76,7 @@ func resourceApplicationCreate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	log.Trace("created Application: %+v", items[0])
+	log.Trace("created Application: %s", items[0].ApplicationID)
 
 	d.SetId(items[0].ApplicationID)
 
@@ -106,7 +106,7 @@ func ApplicationRead(d *schema.ResourceData, m interface{}, params zabbix.Params
 	}
 	t := Applications[0]
 
-	log.Debug("Got Application: %+v", t)
+	log.Debug("Got Application: %s", t.ApplicationID)
 
 	d.SetId(t.ApplicationID)
 	d.Set("name", t.Name)
@@ -1,8 +1,8 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -18,12 +18,12 @@ var schemaCalculated = map[string]*schema.Schema{
 // terraform resource handler for item type
 func resourceItemCalculated() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc),
-		Read:   itemGetReadWrapper(itemCalculatedReadFunc),
-		Update: itemGetUpdateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemCalculatedReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, schemaCalculated),
@@ -31,12 +31,12 @@ func resourceItemCalculated() *schema.Resource {
 }
 func resourceProtoItemCalculated() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc),
-		Read:   protoItemGetReadWrapper(itemCalculatedReadFunc),
-		Update: protoItemGetUpdateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemCalculatedReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemPrototypeSchema, schemaCalculated),
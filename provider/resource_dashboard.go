@@ -0,0 +1,547 @@
+package provider
+
+import (
+	"context"
+
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// DASHBOARD_WIDGET_FIELD types of widget fields, the value of object types is an id
+var DASHBOARD_WIDGET_FIELD = map[string]int{
+	"integer":         0,
+	"string":          1,
+	"host_group":      2,
+	"host":            3,
+	"item":            4,
+	"item_prototype":  5,
+	"graph":           6,
+	"graph_prototype": 7,
+	"map":             8,
+	"service":         9,
+	"sla":             10,
+	"user":            11,
+	"action":          12,
+	"media_type":      13,
+}
+var DASHBOARD_WIDGET_FIELD_REV = map[int]string{}
+
+// DASHBOARD_PERMISSION access given by dashboard shares
+var DASHBOARD_PERMISSION = map[string]int{
+	"read":       2,
+	"read_write": 3,
+}
+var DASHBOARD_PERMISSION_REV = map[int]string{}
+
+// DASHBOARD_DISPLAY_PERIODS seconds a dashboard or page can be shown in a slideshow
+var DASHBOARD_DISPLAY_PERIODS = []int{10, 30, 60, 120, 600, 1800, 3600}
+
+// dashboardRows height of the dashboard grid
+const dashboardRows = 64
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range DASHBOARD_WIDGET_FIELD {
+		DASHBOARD_WIDGET_FIELD_REV[v] = k
+	}
+	for k, v := range DASHBOARD_PERMISSION {
+		DASHBOARD_PERMISSION_REV[v] = k
+	}
+	return false
+}()
+
+// dashboardObject dashboard with its pages and shares
+type dashboardObject struct {
+	Dashboard
+	DisplayPeriod int             `json:"display_period,string"`
+	AutoStart     int             `json:"auto_start,string"`
+	Pages         []dashboardPage `json:"pages"`
+	Users         []struct {
+		UserID     string `json:"userid"`
+		Permission int    `json:"permission,string"`
+	} `json:"users"`
+	UserGroups []struct {
+		UsrGrpID   string `json:"usrgrpid"`
+		Permission int    `json:"permission,string"`
+	} `json:"userGroups"`
+}
+
+// dashboardPage page of a dashboard
+type dashboardPage struct {
+	Name          string `json:"name"`
+	DisplayPeriod int    `json:"display_period,string"`
+	Widgets       []struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		X        int    `json:"x,string"`
+		Y        int    `json:"y,string"`
+		Width    int    `json:"width,string"`
+		Height   int    `json:"height,string"`
+		ViewMode int    `json:"view_mode,string"`
+		Fields   []struct {
+			Type  int    `json:"type,string"`
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"widgets"`
+}
+
+// resourceDashboard terraform resource handler
+func resourceDashboard() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceDashboardCreate),
+		ReadContext:   contextCRUD(resourceDashboardRead),
+		UpdateContext: contextCRUD(resourceDashboardUpdate),
+		DeleteContext: contextCRUD(resourceDashboardDelete),
+		Importer:      importByName(lookupByField("dashboard.get", "dashboardid", "name")),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the dashboard.",
+				Required:     true,
+			},
+			"display_period": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntInSlice(DASHBOARD_DISPLAY_PERIODS),
+				Description:  "Seconds each page is shown in slideshows: 10, 30, 60, 120, 600, 1800 or 3600.",
+				Optional:     true,
+				Default:      30,
+			},
+			"auto_start": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Start the slideshow when the dashboard is opened.",
+				Optional:    true,
+				Default:     true,
+			},
+			"userid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "ID of the dashboard owner, the provider user by default.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"private": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether only the owner and the users and groups it is shared with see the dashboard.",
+				Optional:    true,
+				Default:     true,
+			},
+			"user":       dashboardShareSchema("userid", "User"),
+			"user_group": dashboardShareSchema("usrgrpid", "User group"),
+			"page": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Pages of the dashboard.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Name of the page.",
+							Optional:    true,
+						},
+						"display_period": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntInSlice(append([]int{0}, DASHBOARD_DISPLAY_PERIODS...)),
+							Description:  "Seconds the page is shown in slideshows, 0 for the dashboard display_period.",
+							Optional:     true,
+							Default:      0,
+						},
+						"widget": &schema.Schema{
+							Type:        schema.TypeList,
+							Description: "Widgets of the page.",
+							Optional:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"type": &schema.Schema{
+										Type:         schema.TypeString,
+										ValidateFunc: validation.StringIsNotWhiteSpace,
+										Description:  "Widget type, e.g. clock, graph, problems or svggraph.",
+										Required:     true,
+									},
+									"name": &schema.Schema{
+										Type:        schema.TypeString,
+										Description: "Name of the widget, empty for the default name.",
+										Optional:    true,
+									},
+									"x": &schema.Schema{
+										Type:         schema.TypeInt,
+										ValidateFunc: validation.IntAtLeast(0),
+										Description:  "Column of the left edge of the widget.",
+										Optional:     true,
+										Default:      0,
+									},
+									"y": &schema.Schema{
+										Type:         schema.TypeInt,
+										ValidateFunc: validation.IntBetween(0, dashboardRows-1),
+										Description:  "Row of the top edge of the widget.",
+										Optional:     true,
+										Default:      0,
+									},
+									"width": &schema.Schema{
+										Type:         schema.TypeInt,
+										ValidateFunc: validation.IntAtLeast(1),
+										Description:  "Width in columns, the grid is 24 columns wide, 72 since zabbix 7.0.",
+										Required:     true,
+									},
+									"height": &schema.Schema{
+										Type:         schema.TypeInt,
+										ValidateFunc: validation.IntBetween(1, 32),
+										Description:  "Height in rows, 1-32.",
+										Required:     true,
+									},
+									"hide_header": &schema.Schema{
+										Type:        schema.TypeBool,
+										Description: "Hide the header of the widget.",
+										Optional:    true,
+										Default:     false,
+									},
+									"field": &schema.Schema{
+										Type:        schema.TypeSet,
+										Description: "Widget settings.",
+										Optional:    true,
+										Set:         hashDashboardWidgetField,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"type": &schema.Schema{
+													Type:             schema.TypeString,
+													ValidateFunc:     validateEnum(DASHBOARD_WIDGET_FIELD),
+													DiffSuppressFunc: suppressEnumDiff(DASHBOARD_WIDGET_FIELD),
+													Description:      "Field type, e.g. integer, string, host or item.",
+													Required:         true,
+												},
+												"name": &schema.Schema{
+													Type:         schema.TypeString,
+													ValidateFunc: validation.StringIsNotWhiteSpace,
+													Description:  "Field name, e.g. itemid or ds.0.color.",
+													Required:     true,
+												},
+												"value": &schema.Schema{
+													Type:        schema.TypeString,
+													Description: "Field value, the id for object types.",
+													Required:    true,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		CustomizeDiff: dashboardCustomizeDiff,
+	}
+}
+
+// dashboardShareSchema users or user groups a dashboard is shared with
+func dashboardShareSchema(idField, kind string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Description: kind + "s the dashboard is shared with.",
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				idField: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsNotWhiteSpace,
+					Description:  kind + " ID.",
+					Required:     true,
+				},
+				"permission": &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"read", "read_write"}, false),
+					Description:  "Access given, read or read_write.",
+					Optional:     true,
+					Default:      "read",
+				},
+			},
+		},
+	}
+}
+
+// buildDashboardShares api shares from a share set
+func buildDashboardShares(s *schema.Set, idField string) []map[string]interface{} {
+	shares := []map[string]interface{}{}
+	for _, raw := range s.List() {
+		share := raw.(map[string]interface{})
+		shares = append(shares, map[string]interface{}{
+			idField:      share[idField].(string),
+			"permission": DASHBOARD_PERMISSION[share["permission"].(string)],
+		})
+	}
+	return shares
+}
+
+// hashDashboardWidgetField hash a widget field by the number of its type, so
+// naming the type or giving its number is the same field
+func hashDashboardWidgetField(v interface{}) int {
+	f := v.(map[string]interface{})
+	return schema.HashString(fmt.Sprintf("%d-%s-%s", enumValue(f["type"].(string), DASHBOARD_WIDGET_FIELD), f["name"], f["value"]))
+}
+
+// dashboardColumns width of the dashboard grid
+func dashboardColumns(api *zabbix.API) int {
+	if api != nil && api.Config.Version >= 70000 {
+		return 72
+	}
+	return 24
+}
+
+// dashboardCustomizeDiff check the widgets of each page fit the grid without
+// overlapping, widgets with a position or size only known at apply are skipped
+func dashboardCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	api, _ := m.(*zabbix.API)
+	columns := dashboardColumns(api)
+
+	for p, raw := range d.Get("page").([]interface{}) {
+		page := raw.(map[string]interface{})
+		widgets := page["widget"].([]interface{})
+		for i := range widgets {
+			for _, k := range []string{"x", "y", "width", "height"} {
+				if !d.NewValueKnown(fmt.Sprintf("page.%d.widget.%d.%s", p, i, k)) {
+					widgets[i] = nil
+					break
+				}
+			}
+		}
+		if err := validateDashboardLayout(widgets, columns); err != nil {
+			return fmt.Errorf("page %d: %s", p, err)
+		}
+	}
+	return nil
+}
+
+// validateDashboardLayout check widgets fit a grid of the given width without
+// overlapping, nil widgets are skipped
+func validateDashboardLayout(widgets []interface{}, columns int) error {
+	for i, raw := range widgets {
+		w, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		x, y, width, height := w["x"].(int), w["y"].(int), w["width"].(int), w["height"].(int)
+		if x+width > columns {
+			return fmt.Errorf("widget %d: x + width is %d, the grid has %d columns", i, x+width, columns)
+		}
+		if y+height > dashboardRows {
+			return fmt.Errorf("widget %d: y + height is %d, the grid has %d rows", i, y+height, dashboardRows)
+		}
+
+		for j := 0; j < i; j++ {
+			o, ok := widgets[j].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if x < o["x"].(int)+o["width"].(int) && o["x"].(int) < x+width &&
+				y < o["y"].(int)+o["height"].(int) && o["y"].(int) < y+height {
+				return fmt.Errorf("widgets %d and %d overlap", j, i)
+			}
+		}
+	}
+	return nil
+}
+
+// buildDashboardPages api pages from the terraform blocks
+func buildDashboardPages(d *schema.ResourceData) []map[string]interface{} {
+	pages := []map[string]interface{}{}
+	for _, raw := range d.Get("page").([]interface{}) {
+		page := raw.(map[string]interface{})
+
+		widgets := []map[string]interface{}{}
+		for _, rawWidget := range page["widget"].([]interface{}) {
+			w := rawWidget.(map[string]interface{})
+
+			fields := []map[string]interface{}{}
+			for _, rawField := range w["field"].(*schema.Set).List() {
+				f := rawField.(map[string]interface{})
+				fields = append(fields, map[string]interface{}{
+					"type":  enumValue(f["type"].(string), DASHBOARD_WIDGET_FIELD),
+					"name":  f["name"].(string),
+					"value": f["value"].(string),
+				})
+			}
+
+			widget := map[string]interface{}{
+				"type":      w["type"].(string),
+				"name":      w["name"].(string),
+				"x":         w["x"].(int),
+				"y":         w["y"].(int),
+				"width":     w["width"].(int),
+				"height":    w["height"].(int),
+				"view_mode": 0,
+				"fields":    fields,
+			}
+			if w["hide_header"].(bool) {
+				widget["view_mode"] = 1
+			}
+			widgets = append(widgets, widget)
+		}
+
+		pages = append(pages, map[string]interface{}{
+			"name":           page["name"].(string),
+			"display_period": page["display_period"].(int),
+			"widgets":        widgets,
+		})
+	}
+	return pages
+}
+
+// flattenDashboardPages terraform blocks of api pages
+func flattenDashboardPages(pages []dashboardPage) []interface{} {
+	list := []interface{}{}
+	for _, page := range pages {
+		widgets := []interface{}{}
+		for _, w := range page.Widgets {
+			fields := []interface{}{}
+			for _, f := range w.Fields {
+				fields = append(fields, map[string]interface{}{
+					"type":  DASHBOARD_WIDGET_FIELD_REV[f.Type],
+					"name":  f.Name,
+					"value": f.Value,
+				})
+			}
+			widgets = append(widgets, map[string]interface{}{
+				"type":        w.Type,
+				"name":        w.Name,
+				"x":           w.X,
+				"y":           w.Y,
+				"width":       w.Width,
+				"height":      w.Height,
+				"hide_header": w.ViewMode == 1,
+				"field":       fields,
+			})
+		}
+		list = append(list, map[string]interface{}{
+			"name":           page.Name,
+			"display_period": page.DisplayPeriod,
+			"widget":         widgets,
+		})
+	}
+	return list
+}
+
+// buildDashboardParams dashboard create/update params from terraform data
+func buildDashboardParams(d *schema.ResourceData) zabbix.Params {
+	params := zabbix.Params{
+		"name":           d.Get("name").(string),
+		"display_period": d.Get("display_period").(int),
+		"auto_start":     0,
+		"pages":          buildDashboardPages(d),
+		"private":        0,
+		"users":          buildDashboardShares(d.Get("user").(*schema.Set), "userid"),
+		"userGroups":     buildDashboardShares(d.Get("user_group").(*schema.Set), "usrgrpid"),
+	}
+	if d.Get("auto_start").(bool) {
+		params["auto_start"] = 1
+	}
+	if d.Get("private").(bool) {
+		params["private"] = 1
+	}
+	// left out, the dashboard belongs to the user creating it
+	if owner, ok := d.GetOk("userid"); ok {
+		params["userid"] = owner.(string)
+	}
+	return params
+}
+
+// resourceDashboardCreate terraform resource create handler
+func resourceDashboardCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	// pages came with 5.4
+	if err := requireVersion(api, 50400, "zabbix_dashboard"); err != nil {
+		return err
+	}
+
+	response, err := api.CallWithError("dashboard.create", buildDashboardParams(d))
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	d.SetId(result["dashboardids"].([]interface{})[0].(string))
+	log.Trace("created dashboard: %s", d.Id())
+
+	return readAfterWrite(d, m, resourceDashboard().Schema, resourceDashboardRead)
+}
+
+// resourceDashboardRead terraform resource read handler
+func resourceDashboardRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of dashboard with id %s", d.Id())
+
+	var dashboards []dashboardObject
+	err := api.CallWithErrorParse("dashboard.get", zabbix.Params{
+		"output":           "extend",
+		"dashboardids":     d.Id(),
+		"selectPages":      "extend",
+		"selectUsers":      "extend",
+		"selectUserGroups": "extend",
+	}, &dashboards)
+	if err != nil {
+		return err
+	}
+
+	if len(dashboards) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := dashboards[0]
+
+	log.Debug("Got dashboard: %s", t.Dashboard.DashboardID)
+
+	d.Set("name", t.Name)
+	d.Set("display_period", t.DisplayPeriod)
+	d.Set("auto_start", t.AutoStart == 1)
+	d.Set("page", flattenDashboardPages(t.Pages))
+
+	users := []interface{}{}
+	for _, u := range t.Users {
+		users = append(users, map[string]interface{}{
+			"userid":     u.UserID,
+			"permission": DASHBOARD_PERMISSION_REV[u.Permission],
+		})
+	}
+	groups := []interface{}{}
+	for _, g := range t.UserGroups {
+		groups = append(groups, map[string]interface{}{
+			"usrgrpid":   g.UsrGrpID,
+			"permission": DASHBOARD_PERMISSION_REV[g.Permission],
+		})
+	}
+	d.Set("userid", t.UserID)
+	d.Set("private", t.Private == 1)
+	d.Set("user", users)
+	d.Set("user_group", groups)
+
+	return nil
+}
+
+// resourceDashboardUpdate terraform resource update handler, pages and widgets
+// are replaced
+func resourceDashboardUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := buildDashboardParams(d)
+	params["dashboardid"] = d.Id()
+
+	if _, err := api.CallWithError("dashboard.update", params); err != nil {
+		return err
+	}
+
+	return readAfterWrite(d, m, resourceDashboard().Schema, resourceDashboardRead)
+}
+
+// resourceDashboardDelete terraform resource delete handler
+func resourceDashboardDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("dashboard.delete", []string{d.Id()})
+	return err
+}
@@ -0,0 +1,34 @@
+package provider
+
+import "testing"
+
+func TestUnitDashboardLayout(t *testing.T) {
+	widget := func(x, y, width, height int) interface{} {
+		return map[string]interface{}{"x": x, "y": y, "width": width, "height": height}
+	}
+
+	cases := map[string]struct {
+		widgets []interface{}
+		valid   bool
+	}{
+		"side by side": {[]interface{}{widget(0, 0, 12, 5), widget(12, 0, 12, 5)}, true},
+		"stacked":      {[]interface{}{widget(0, 0, 24, 5), widget(0, 5, 24, 5)}, true},
+		"overlap":      {[]interface{}{widget(0, 0, 12, 5), widget(6, 4, 12, 5)}, false},
+		"contained":    {[]interface{}{widget(0, 0, 24, 10), widget(4, 2, 2, 2)}, false},
+		"too wide":     {[]interface{}{widget(20, 0, 6, 5)}, false},
+		"too low":      {[]interface{}{widget(0, 60, 6, 5)}, false},
+		"unknown":      {[]interface{}{widget(0, 0, 12, 5), nil, widget(12, 0, 12, 5)}, true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateDashboardLayout(c.widgets, 24)
+			if c.valid && err != nil {
+				t.Errorf("expected a valid layout, got %s", err)
+			}
+			if !c.valid && err == nil {
+				t.Error("expected an invalid layout")
+			}
+		})
+	}
+}
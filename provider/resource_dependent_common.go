@@ -1,8 +1,12 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"context"
+
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -15,44 +19,125 @@ var schemaDependent = map[string]*schema.Schema{
 	},
 }
 
+// schemaProtoDependent master of a dependent prototype, another prototype of the
+// same rule or an item of the host, given by id or key
+var schemaProtoDependent = map[string]*schema.Schema{
+	"master_itemid": &schema.Schema{
+		Type:         schema.TypeString,
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Description:  "Master Item or Item Prototype ID",
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"master_itemid", "master_item_key"},
+	},
+	"master_item_key": &schema.Schema{
+		Type:         schema.TypeString,
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Description:  "Key of the master item prototype in the same LLD rule, or of the master item on the host",
+		Optional:     true,
+		ExactlyOneOf: []string{"master_itemid", "master_item_key"},
+	},
+}
+
 // resourceItemDependent terraform resource for agent items
 func resourceItemDependent() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemDependentModFunc, itemDependentReadFunc),
-		Read:   itemGetReadWrapper(itemDependentReadFunc),
-		Update: itemGetUpdateWrapper(itemDependentModFunc, itemDependentReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemDependentModFunc, itemDependentReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemDependentReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemDependentModFunc, itemDependentReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Schema: mergeSchemas(itemCommonSchema, schemaDependent),
 	}
 }
 func resourceProtoItemDependent() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemDependentModFunc, itemDependentReadFunc),
-		Read:   protoItemGetReadWrapper(itemDependentReadFunc),
-		Update: protoItemGetUpdateWrapper(itemDependentModFunc, itemDependentReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemDependentCreate),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(protoItemDependentReadFunc)),
+		UpdateContext: contextCRUD(protoItemDependentUpdate),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
+		CustomizeDiff: protoItemDependentCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
-		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema, schemaDependent),
+		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema, schemaProtoDependent),
 	}
 }
 func resourceLLDDependent() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldDependentModFunc, lldDependentReadFunc),
-		Read:   lldGetReadWrapper(lldDependentReadFunc),
-		Update: lldGetUpdateWrapper(lldDependentModFunc, lldDependentReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldDependentModFunc, lldDependentReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldDependentReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldDependentModFunc, lldDependentReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Schema: mergeSchemas(lldCommonSchema, schemaDependent),
 	}
 }
 
+// protoItemDependentCreate resolve a master given by key before creating
+func protoItemDependentCreate(d *schema.ResourceData, m interface{}) error {
+	if err := resolveMasterItemKey(d, m.(*zabbix.API)); err != nil {
+		return err
+	}
+	return resourceItemCreate(d, m, itemDependentModFunc, protoItemDependentReadFunc, true)
+}
+
+// protoItemDependentUpdate resolve a master given by key before updating
+func protoItemDependentUpdate(d *schema.ResourceData, m interface{}) error {
+	if err := resolveMasterItemKey(d, m.(*zabbix.API)); err != nil {
+		return err
+	}
+	return resourceItemUpdate(d, m, itemDependentModFunc, protoItemDependentReadFunc, true)
+}
+
+// protoItemDependentCustomizeDiff the master id is only known after resolving a changed key
+func protoItemDependentCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.HasChange("master_item_key") && d.Get("master_item_key").(string) != "" {
+		return d.SetNewComputed("master_itemid")
+	}
+	return nil
+}
+
+// resolveMasterItemKey set master_itemid from master_item_key, looking for a
+// prototype of the same rule first and an item of the host second
+func resolveMasterItemKey(d *schema.ResourceData, api *zabbix.API) error {
+	key := d.Get("master_item_key").(string)
+	if key == "" {
+		return nil
+	}
+
+	lookups := []struct {
+		method string
+		params zabbix.Params
+	}{
+		{"itemprototype.get", zabbix.Params{"discoveryids": d.Get("ruleid").(string)}},
+		{"item.get", zabbix.Params{"hostids": d.Get("hostid").(string)}},
+	}
+	for _, lookup := range lookups {
+		lookup.params["output"] = []string{"itemid"}
+		lookup.params["filter"] = map[string]interface{}{"key_": key}
+
+		var items []zabbix.Item
+		if err := api.CallWithErrorParse(lookup.method, lookup.params, &items); err != nil {
+			return err
+		}
+		if len(items) > 1 {
+			return fmt.Errorf("master_item_key %q matches multiple items", key)
+		}
+		if len(items) == 1 {
+			d.Set("master_itemid", items[0].ItemID)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("master_item_key %q matches no item prototype of rule %s or item of host %s", key, d.Get("ruleid"), d.Get("hostid"))
+}
+
 func itemDependentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
 	item.Type = zabbix.Dependent
 	item.MasterItemID = d.Get("master_itemid").(string)
@@ -65,6 +150,32 @@ func lldDependentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLD
 func itemDependentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
 	d.Set("master_itemid", item.MasterItemID)
 }
+
+// protoItemDependentReadFunc also read back the key of a master given by key, so
+// pointing the prototype at another master outside of terraform shows as a change
+func protoItemDependentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+	d.Set("master_itemid", item.MasterItemID)
+	if d.Get("master_item_key").(string) == "" {
+		return
+	}
+
+	api := m.(*zabbix.API)
+	for _, method := range []string{"itemprototype.get", "item.get"} {
+		var items []zabbix.Item
+		err := api.CallWithErrorParse(method, zabbix.Params{
+			"output":  []string{"itemid", "key_"},
+			"itemids": []string{item.MasterItemID},
+		}, &items)
+		if err != nil {
+			log.Debug("lookup of master item %s failed: %s", item.MasterItemID, err)
+			return
+		}
+		if len(items) > 0 {
+			d.Set("master_item_key", items[0].Key)
+			return
+		}
+	}
+}
 func lldDependentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
 	d.Set("master_itemid", item.MasterItemID)
 }
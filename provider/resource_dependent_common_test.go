@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceProtoItemDependentMasterKey(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	hostID := server.Seed("host", map[string]interface{}{"host": "tf-unit-host"})
+	ruleID := server.Seed("discoveryrule", map[string]interface{}{"name": "tf-unit-rule"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceProtoItemDependentMasterKey(server.APIURL(), hostID, ruleID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_proto_item_dependent.test", "master_item_key", "json.raw[{#NAME}]"),
+					func(s *terraform.State) error {
+						master := s.RootModule().Resources["zabbix_proto_item_trapper.master"].Primary.ID
+						got := s.RootModule().Resources["zabbix_proto_item_dependent.test"].Primary.Attributes["master_itemid"]
+						if got != master {
+							return fmt.Errorf("master_itemid %s, expected the master prototype %s", got, master)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceProtoItemDependentMasterKey(url, hostID, ruleID string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_proto_item_trapper" "master" {
+	hostid    = %[2]q
+	ruleid    = %[3]q
+	key       = "json.raw[{#NAME}]"
+	name      = "Raw {#NAME}"
+	valuetype = "text"
+}
+resource "zabbix_proto_item_dependent" "test" {
+	hostid          = %[2]q
+	ruleid          = %[3]q
+	key             = "json.value[{#NAME}]"
+	name            = "Value {#NAME}"
+	valuetype       = "unsigned"
+	master_item_key = zabbix_proto_item_trapper.master.key
+}
+`, testUnitProviderConfig(url), hostID, ruleID)
+}
@@ -1,19 +1,19 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // terraform resource handler for item type
 func resourceItemExternal() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemExternalModFunc, itemExternalReadFunc),
-		Read:   itemGetReadWrapper(itemExternalReadFunc),
-		Update: itemGetUpdateWrapper(itemExternalModFunc, itemExternalReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemExternalModFunc, itemExternalReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemExternalReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemExternalModFunc, itemExternalReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema),
@@ -21,12 +21,12 @@ func resourceItemExternal() *schema.Resource {
 }
 func resourceProtoItemExternal() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemExternalModFunc, itemExternalReadFunc),
-		Read:   protoItemGetReadWrapper(itemExternalReadFunc),
-		Update: protoItemGetUpdateWrapper(itemExternalModFunc, itemExternalReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemExternalModFunc, itemExternalReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemExternalReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemExternalModFunc, itemExternalReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema),
@@ -34,12 +34,13 @@ func resourceProtoItemExternal() *schema.Resource {
 }
 func resourceLLDExternal() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldExternalModFunc, lldExternalReadFunc),
-		Read:   lldGetReadWrapper(lldExternalReadFunc),
-		Update: lldGetUpdateWrapper(lldExternalModFunc, lldExternalReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldExternalModFunc, lldExternalReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldExternalReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldExternalModFunc, lldExternalReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema),
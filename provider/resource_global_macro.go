@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// GlobalMacro represent a Zabbix global macro
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/usermacro/object#global-macro
+type GlobalMacro struct {
+	GlobalMacroID string `json:"globalmacroid,omitempty"`
+	MacroName     string `json:"macro"`
+	Value         string `json:"value"`
+	Type          int    `json:"type,string,omitempty"`
+	Description   string `json:"description"`
+}
+
+// resourceGlobalMacro terraform resource handler
+func resourceGlobalMacro() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceGlobalMacroCreate),
+		ReadContext:   contextCRUD(resourceGlobalMacroRead),
+		UpdateContext: contextCRUD(resourceGlobalMacroUpdate),
+		DeleteContext: contextCRUD(resourceGlobalMacroDelete),
+		Importer:      importByName(lookupGlobalMacro),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Macro Name (key)",
+			},
+			"value":        macroValueSchema(),
+			"secret_value": macroSecretValueSchema(),
+			"type":         macroTypeSchema(),
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Macro Description",
+			},
+		},
+	}
+}
+
+// globalMacroOutput global macro fields read back, types came with 5.0
+func globalMacroOutput(api *zabbix.API) []string {
+	output := []string{"globalmacroid", "macro", "value", "description"}
+	if api.Config.Version >= 50000 {
+		output = append(output, "type")
+	}
+	return output
+}
+
+// lookupGlobalMacro nameLookup of global macros, usermacro.get only returns them
+// with globalmacro set
+func lookupGlobalMacro(api *zabbix.API, name string) ([]string, error) {
+	var res []GlobalMacro
+	err := api.CallWithErrorParse("usermacro.get", zabbix.Params{
+		"output":      []string{"globalmacroid"},
+		"globalmacro": true,
+		"filter": map[string]interface{}{
+			"macro": name,
+		},
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(res))
+	for i, r := range res {
+		ids[i] = r.GlobalMacroID
+	}
+	return ids, nil
+}
+
+// buildGlobalMacro create a global macro from terraform data
+func buildGlobalMacro(d *schema.ResourceData, api *zabbix.API) (GlobalMacro, error) {
+	name := d.Get("name").(string)
+	macroType := enumValue(d.Get("type").(string), MACRO_TYPE)
+
+	value, err := macroValue(api, name, d.Get("value").(string), d.Get("secret_value").(string), macroType)
+	if err != nil {
+		return GlobalMacro{}, errorAt("value", err)
+	}
+
+	return GlobalMacro{
+		GlobalMacroID: d.Id(),
+		MacroName:     name,
+		Value:         value,
+		Type:          macroType,
+		Description:   d.Get("description").(string),
+	}, nil
+}
+
+// resourceGlobalMacroCreate terraform resource create handler
+func resourceGlobalMacroCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	item, err := buildGlobalMacro(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		GlobalMacroIDs []string `json:"globalmacroids"`
+	}
+	err = api.CallWithErrorParse("usermacro.createglobal", item, &res)
+
+	if err != nil {
+		return err
+	}
+	if len(res.GlobalMacroIDs) < 1 {
+		return errors.New("usermacro.createglobal returned no id")
+	}
+
+	log.Trace("created global macro %s: %s", res.GlobalMacroIDs[0], item.MacroName)
+
+	d.SetId(res.GlobalMacroIDs[0])
+
+	return readAfterWrite(d, m, resourceGlobalMacro().Schema, resourceGlobalMacroRead)
+}
+
+// resourceGlobalMacroRead terraform resource read handler
+func resourceGlobalMacroRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of global macro with id %s", d.Id())
+
+	var macros []GlobalMacro
+	err := api.CallWithErrorParse("usermacro.get", zabbix.Params{
+		"output":         globalMacroOutput(api),
+		"globalmacro":    true,
+		"globalmacroids": d.Id(),
+	}, &macros)
+
+	if err != nil {
+		return err
+	}
+
+	if len(macros) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := macros[0]
+
+	value, secretValue := flattenMacroValue(UserMacro{Value: t.Value, Type: t.Type}, d.Get("value").(string), d.Get("secret_value").(string))
+
+	d.Set("name", t.MacroName)
+	d.Set("value", value)
+	d.Set("secret_value", secretValue)
+	setEnum(d, "type", t.Type, MACRO_TYPE, MACRO_TYPE_REV)
+	d.Set("description", t.Description)
+
+	return nil
+}
+
+// resourceGlobalMacroUpdate terraform resource update handler
+func resourceGlobalMacroUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	item, err := buildGlobalMacro(d, api)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = api.CallWithError("usermacro.updateglobal", item)
+
+	if err != nil {
+		return err
+	}
+
+	return readAfterWrite(d, m, resourceGlobalMacro().Schema, resourceGlobalMacroRead)
+}
+
+// resourceGlobalMacroDelete terraform resource delete handler
+func resourceGlobalMacroDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("usermacro.deleteglobal", []string{d.Id()})
+	return err
+}
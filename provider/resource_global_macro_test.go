@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitResourceGlobalMacro(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceGlobalMacro(server.APIURL(), `secret_value = "tf-unit-secret"`, "first"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCallParam(server, "usermacro.createglobal", "value", "tf-unit-secret"),
+					testUnitCheckCallParam(server, "usermacro.createglobal", "type", "1"),
+					resource.TestCheckResourceAttr("zabbix_global_macro.test", "secret_value", "tf-unit-secret"),
+					resource.TestCheckResourceAttr("zabbix_global_macro.test", "value", ""),
+				),
+			},
+			{
+				Config: testUnitResourceGlobalMacro(server.APIURL(), `secret_value = "tf-unit-secret"`, "second"),
+				Check:  testUnitCheckCallParam(server, "usermacro.updateglobal", "description", "second"),
+			},
+			{
+				Config:      testUnitResourceGlobalMacro(server.APIURL(), `value = "tf-unit-secret"`+"\n"+`secret_value = "tf-unit-secret"`, "second"),
+				ExpectError: regexp.MustCompile("sets both value and secret_value"),
+			},
+		},
+	})
+}
+
+func testUnitResourceGlobalMacro(url, value, description string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_global_macro" "test" {
+	name        = "{$TF_UNIT_SECRET}"
+	type        = "secret"
+	description = %q
+	%s
+}
+`, testUnitProviderConfig(url), description, value)
+}
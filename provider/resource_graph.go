@@ -1,12 +1,16 @@
 package provider
 
 import (
+	"context"
+
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -102,8 +106,8 @@ var schemaGraphItem = &schema.Schema{
 			"color": &schema.Schema{
 				Type:         schema.TypeString,
 				Required:     true,
-				Description:  "color",
-				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "color, as rrggbb",
+				ValidateFunc: validation.StringMatch(hexColor, "must be a color like 00FF00"),
 			},
 			"itemid": &schema.Schema{
 				Type:         schema.TypeString,
@@ -130,7 +134,7 @@ var schemaGraphItem = &schema.Schema{
 				Optional:     true,
 				Description:  "sort order",
 				Default:      "0",
-				ValidateFunc: validation.StringIsNotWhiteSpace,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
 			},
 			"type": &schema.Schema{
 				Type:         schema.TypeString,
@@ -177,18 +181,20 @@ var schemaGraph = map[string]*schema.Schema{
 		Optional:     true,
 	},
 	"percent_left": &schema.Schema{
-		Type:         schema.TypeString,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "Left percentile",
-		Default:      "0",
-		Optional:     true,
+		Type:             schema.TypeString,
+		ValidateFunc:     validateGraphPercentile,
+		DiffSuppressFunc: suppressNumericDiff,
+		Description:      "Left percentile, 0 to disable",
+		Default:          "0",
+		Optional:         true,
 	},
 	"percent_right": &schema.Schema{
-		Type:         schema.TypeString,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "Right percentile",
-		Default:      "0",
-		Optional:     true,
+		Type:             schema.TypeString,
+		ValidateFunc:     validateGraphPercentile,
+		DiffSuppressFunc: suppressNumericDiff,
+		Description:      "Right percentile, 0 to disable",
+		Default:          "0",
+		Optional:         true,
 	},
 	"do3d": &schema.Schema{
 		Type:        schema.TypeBool,
@@ -209,11 +215,12 @@ var schemaGraph = map[string]*schema.Schema{
 		Optional:    true,
 	},
 	"ymax": &schema.Schema{
-		Type:         schema.TypeString,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "Y Axis Max",
-		Default:      "100",
-		Optional:     true,
+		Type:             schema.TypeString,
+		ValidateFunc:     validation.StringIsNotWhiteSpace,
+		DiffSuppressFunc: suppressNumericDiff,
+		Description:      "Y Axis Max, used by the fixed type",
+		Default:          "100",
+		Optional:         true,
 	},
 	"ymax_itemid": &schema.Schema{
 		Type:         schema.TypeString,
@@ -229,11 +236,12 @@ var schemaGraph = map[string]*schema.Schema{
 		ValidateFunc: validation.StringInSlice(GRAPH_AXIS_LOOKUP_ARR, false),
 	},
 	"ymin": &schema.Schema{
-		Type:         schema.TypeString,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "Y Axis Min",
-		Default:      "0",
-		Optional:     true,
+		Type:             schema.TypeString,
+		ValidateFunc:     validation.StringIsNotWhiteSpace,
+		DiffSuppressFunc: suppressNumericDiff,
+		Description:      "Y Axis Min, used by the fixed type",
+		Default:          "0",
+		Optional:         true,
 	},
 	"ymin_itemid": &schema.Schema{
 		Type:         schema.TypeString,
@@ -254,33 +262,78 @@ var schemaGraph = map[string]*schema.Schema{
 // resourceGraph terraform resource handler
 func resourceGraph() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceGraphCreate(false),
-		Read:   resourceGraphRead(false),
-		Update: resourceGraphUpdate(false),
-		Delete: resourceGraphDelete(false),
+		CreateContext: contextCRUD(resourceGraphCreate(false)),
+		ReadContext:   contextCRUD(resourceGraphRead(false)),
+		UpdateContext: contextCRUD(resourceGraphUpdate(false)),
+		DeleteContext: contextCRUD(resourceGraphDelete(false)),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Schema: schemaGraph,
+		Schema:        schemaGraph,
+		CustomizeDiff: graphCustomizeDiff,
 	}
 }
 func resourceProtoGraph() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceGraphCreate(true),
-		Read:   resourceGraphRead(true),
-		Update: resourceGraphUpdate(true),
-		Delete: resourceGraphDelete(true),
+		CreateContext: contextCRUD(resourceGraphCreate(true)),
+		ReadContext:   contextCRUD(resourceGraphRead(true)),
+		UpdateContext: contextCRUD(resourceGraphUpdate(true)),
+		DeleteContext: contextCRUD(resourceGraphDelete(true)),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Schema: schemaGraph,
+		Schema:        schemaGraph,
+		CustomizeDiff: graphCustomizeDiff,
 	}
 }
 
+// validateGraphPercentile percentile lines are drawn at 0 to 100 percent
+func validateGraphPercentile(v interface{}, k string) (ws []string, es []error) {
+	f, err := strconv.ParseFloat(v.(string), 64)
+	if err != nil || f < 0 || f > 100 {
+		es = append(es, fmt.Errorf("%q: must be a number from 0 to 100, got %q", k, v))
+	}
+	return
+}
+
+// graphCustomizeDiff reject item and axis settings the api refuses for the graph type
+func graphCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	graphType := d.Get("type").(string)
+	pie := graphType == "pie" || graphType == "exploded"
+
+	for i, raw := range d.Get("item").([]interface{}) {
+		item := raw.(map[string]interface{})
+		switch {
+		case item["function"] == "last" && !pie:
+			return fmt.Errorf("item.%d: function last is only supported on pie and exploded graphs", i)
+		case item["function"] == "all" && pie:
+			return fmt.Errorf("item.%d: function all is not supported on pie and exploded graphs", i)
+		case item["type"] == "sum" && !pie:
+			return fmt.Errorf("item.%d: type sum is only supported on pie and exploded graphs", i)
+		}
+	}
+
+	for _, axis := range []string{"ymin", "ymax"} {
+		if d.Get(axis+"_type").(string) == "item" && d.Get(axis+"_itemid").(string) == "" {
+			return fmt.Errorf("%s_itemid is required when %s_type is item", axis, axis)
+		}
+	}
+
+	if graphType != "normal" {
+		for _, key := range []string{"percent_left", "percent_right"} {
+			if f, _ := strconv.ParseFloat(d.Get(key).(string), 64); f != 0 {
+				return fmt.Errorf("%s is only supported on normal graphs", key)
+			}
+		}
+	}
+
+	return nil
+}
+
 // terraform Graph create function
-func resourceGraphCreate(prototype bool) schema.CreateFunc {
+func resourceGraphCreate(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
@@ -299,7 +352,7 @@ func resourceGraphCreate(prototype bool) schema.CreateFunc {
 			return err
 		}
 
-		log.Trace("created Graph: %+v", items[0])
+		log.Trace("created Graph: %s", items[0].GraphID)
 
 		d.SetId(items[0].GraphID)
 
@@ -385,7 +438,7 @@ func flattenGraphItems(items zabbix.GraphItems) []interface{} {
 }
 
 // resourceGraphRead terraform resource read handler
-func resourceGraphRead(prototype bool) schema.ReadFunc {
+func resourceGraphRead(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
@@ -417,7 +470,7 @@ func resourceGraphRead(prototype bool) schema.ReadFunc {
 		}
 		t := graphs[0]
 
-		log.Debug("Got Graph: %+v", t)
+		log.Debug("Got Graph: %s", t.GraphID)
 
 		d.SetId(t.GraphID)
 		d.Set("name", t.Name)
@@ -443,7 +496,7 @@ func resourceGraphRead(prototype bool) schema.ReadFunc {
 }
 
 // resourceGraphUpdate terraform resource update handler
-func resourceGraphUpdate(prototype bool) schema.UpdateFunc {
+func resourceGraphUpdate(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
@@ -468,7 +521,7 @@ func resourceGraphUpdate(prototype bool) schema.UpdateFunc {
 }
 
 // resourceGraphDelete terraform resource delete handler
-func resourceGraphDelete(prototype bool) schema.DeleteFunc {
+func resourceGraphDelete(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
@@ -1,14 +1,19 @@
 package provider
 
 import (
+	"context"
+
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/hoonii2/go-zabbix-api"
 )
@@ -30,15 +35,23 @@ var HINV_LOOKUP_REV = map[zabbix.InventoryMode]string{}
 var HINV_LOOKUP_ARR = []string{}
 
 var HSNMP_AUTHPROTO = map[string]string{
-	"md5": "0",
-	"sha": "1",
+	"md5":    "0",
+	"sha":    "1",
+	"sha224": "2",
+	"sha256": "3",
+	"sha384": "4",
+	"sha512": "5",
 }
 var HSNMP_AUTHPROTO_REV = map[string]string{}
 var HSNMP_AUTHPROTO_ARR = []string{}
 
 var HSNMP_PRIVPROTO = map[string]string{
-	"des": "0",
-	"aes": "1",
+	"des":     "0",
+	"aes":     "1",
+	"aes192":  "2",
+	"aes256":  "3",
+	"aes192c": "4",
+	"aes256c": "5",
 }
 var HSNMP_PRIVPROTO_REV = map[string]string{}
 var HSNMP_PRIVPROTO_ARR = []string{}
@@ -144,6 +157,36 @@ var INVENTORY_KEYS = []string{
 	"vendor",
 }
 
+// HOST_MONITORED_BY what monitors a host, zabbix 7.0+
+var HOST_MONITORED_BY = map[string]int{
+	"server":      0,
+	"proxy":       1,
+	"proxy_group": 2,
+}
+var HOST_MONITORED_BY_REV = map[int]string{}
+
+// HOST_IPMI_AUTHTYPE ipmi authentication algorithms
+var HOST_IPMI_AUTHTYPE = map[string]int{
+	"default":  -1,
+	"none":     0,
+	"md2":      1,
+	"md5":      2,
+	"straight": 4,
+	"oem":      5,
+	"rmcp+":    6,
+}
+var HOST_IPMI_AUTHTYPE_REV = map[int]string{}
+
+// HOST_IPMI_PRIVILEGE ipmi privilege levels
+var HOST_IPMI_PRIVILEGE = map[string]int{
+	"callback": 1,
+	"user":     2,
+	"operator": 3,
+	"admin":    4,
+	"oem":      5,
+}
+var HOST_IPMI_PRIVILEGE_REV = map[int]string{}
+
 var inventorySchema = &schema.Schema{
 	Type: schema.TypeList,
 	Elem: &schema.Resource{
@@ -173,6 +216,15 @@ var _ = func() bool {
 		HSNMP_SECLEVEL_REV[v] = k
 		HSNMP_SECLEVEL_ARR = append(HSNMP_SECLEVEL_ARR, k)
 	}
+	for k, v := range HOST_MONITORED_BY {
+		HOST_MONITORED_BY_REV[v] = k
+	}
+	for k, v := range HOST_IPMI_AUTHTYPE {
+		HOST_IPMI_AUTHTYPE_REV[v] = k
+	}
+	for k, v := range HOST_IPMI_PRIVILEGE {
+		HOST_IPMI_PRIVILEGE_REV[v] = k
+	}
 	for _, v := range INVENTORY_KEYS {
 		inventorySchema.Elem.(*schema.Resource).Schema[v] = &schema.Schema{
 			Type:        schema.TypeString,
@@ -201,6 +253,14 @@ var hostSchemaBase = map[string]*schema.Schema{
 		Type:        schema.TypeString,
 		Description: "ID of proxy to monitor this host",
 	},
+	"monitored_by": &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "What monitors the host, one of: server, proxy, proxy_group (zabbix 7.0+); derived from proxyid and proxy_groupid if unset",
+	},
+	"proxy_groupid": &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "ID of the proxy group to monitor this host, zabbix 7.0+",
+	},
 	"enabled": &schema.Schema{
 		Type:        schema.TypeBool,
 		Optional:    true,
@@ -284,13 +344,14 @@ var hostSchemaBase = map[string]*schema.Schema{
 					Type:         schema.TypeString,
 					Optional:     true,
 					Description:  "Authentication Passphrase (v3 only)",
+					Sensitive:    true,
 					ValidateFunc: validation.StringIsNotWhiteSpace,
 					Default:      "{$SNMP3_AUTHPASSPHRASE}",
 				},
 				"snmp3_authprotocol": &schema.Schema{
 					Type:         schema.TypeString,
 					Optional:     true,
-					Description:  "Authentication Protocol (v3 only), one of: " + strings.Join(HSNMP_AUTHPROTO_ARR, ", "),
+					Description:  "Authentication Protocol (v3 only), one of: " + strings.Join(HSNMP_AUTHPROTO_ARR, ", ") + ", the sha2 variants need zabbix 5.4+",
 					ValidateFunc: validation.StringInSlice(HSNMP_AUTHPROTO_ARR, false),
 					Default:      "sha",
 				},
@@ -305,13 +366,14 @@ var hostSchemaBase = map[string]*schema.Schema{
 					Type:         schema.TypeString,
 					Optional:     true,
 					Description:  "Priv Passphrase (v3 only)",
+					Sensitive:    true,
 					ValidateFunc: validation.StringIsNotWhiteSpace,
 					Default:      "{$SNMP3_PRIVPASSPHRASE}",
 				},
 				"snmp3_privprotocol": &schema.Schema{
 					Type:         schema.TypeString,
 					Optional:     true,
-					Description:  "Priv Protocol (v3 only), one of: " + strings.Join(HSNMP_PRIVPROTO_ARR, ", "),
+					Description:  "Priv Protocol (v3 only), one of: " + strings.Join(HSNMP_PRIVPROTO_ARR, ", ") + ", all but des and aes need zabbix 5.4+",
 					ValidateFunc: validation.StringInSlice(HSNMP_PRIVPROTO_ARR, false),
 					Default:      "aes",
 				},
@@ -349,37 +411,23 @@ var hostSchemaBase = map[string]*schema.Schema{
 		},
 	},
 	"macro": macroListSchema,
-	"tag": &schema.Schema{
-		Type:     schema.TypeSet,
-		Optional: true,
-		Elem: &schema.Resource{
-			Schema: map[string]*schema.Schema{
-				"key": &schema.Schema{
-					Type:         schema.TypeString,
-					Required:     true,
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Description:  "Tag Key",
-				},
-				"value": &schema.Schema{
-					Type:        schema.TypeString,
-					Optional:    true,
-					Description: "Tag Value",
-				},
-			},
-		},
-	},
+	"tag":   schemaTags(),
 }
 
 // resourceHost terraform host resource entrypoint
 func resourceHost() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceHostCreate,
-		Read:   resourceHostRead,
-		Update: resourceHostUpdate,
-		Delete: resourceHostDelete,
-		Schema: hostResourceSchema(hostSchemaBase),
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+		CreateContext: contextCRUD(resourceHostCreate),
+		ReadContext:   contextCRUD(resourceHostRead),
+		UpdateContext: contextCRUD(resourceHostUpdate),
+		DeleteContext: contextCRUD(resourceHostDelete),
+		Schema:        hostResourceSchema(hostSchemaBase),
+		CustomizeDiff: customdiff.All(validateMacroNames, hostMonitoringCustomizeDiff, tlsCustomizeDiff, tlsPSKCustomizeDiff),
+		Importer:      importByName(lookupByField("host.get", "hostid", "host")),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 	}
 }
@@ -387,8 +435,8 @@ func resourceHost() *schema.Resource {
 // dataHost terraform host resource entrypoint
 func dataHost() *schema.Resource {
 	return &schema.Resource{
-		Read:   dataHostRead,
-		Schema: hostDataSchema(hostSchemaBase),
+		ReadContext: contextCRUD(dataHostRead),
+		Schema:      hostDataSchema(hostSchemaBase),
 	}
 }
 
@@ -402,7 +450,7 @@ func hostResourceSchema(m map[string]*schema.Schema) (o map[string]*schema.Schem
 		switch k {
 		case "host", "interface", "groups":
 			schema.Required = true
-		case "templates", "proxyid", "inventory":
+		case "templates", "proxyid", "monitored_by", "proxy_groupid", "inventory":
 			schema.Optional = true
 		}
 
@@ -411,7 +459,46 @@ func hostResourceSchema(m map[string]*schema.Schema) (o map[string]*schema.Schem
 
 	o["proxyid"].ValidateFunc = validation.StringIsNotWhiteSpace
 	o["proxyid"].Default = "0"
-	return o
+	o["monitored_by"].ValidateFunc = validateEnum(HOST_MONITORED_BY)
+	o["monitored_by"].DiffSuppressFunc = suppressEnumDiff(HOST_MONITORED_BY)
+	o["proxy_groupid"].ValidateFunc = validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be a numeric string")
+	o["deletion_protection"] = deletionProtectionSchema()
+	o["templates_clear"] = templatesClearSchema()
+	o["macro_override"] = macroOverrideSchema
+	return mergeSchemas(o, hostIPMISchema(), tlsSchema(), safeDeleteSchema())
+}
+
+// hostIPMISchema ipmi access of the host, used by its ipmi interface
+func hostIPMISchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"ipmi_authtype": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "IPMI authentication algorithm, one of: default, none, md2, md5, straight, oem, rmcp+",
+			ValidateFunc:     validateEnum(HOST_IPMI_AUTHTYPE),
+			DiffSuppressFunc: suppressEnumDiff(HOST_IPMI_AUTHTYPE),
+			Optional:         true,
+			Default:          "default",
+		},
+		"ipmi_privilege": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "IPMI privilege level, one of: callback, user, operator, admin, oem",
+			ValidateFunc:     validateEnum(HOST_IPMI_PRIVILEGE),
+			DiffSuppressFunc: suppressEnumDiff(HOST_IPMI_PRIVILEGE),
+			Optional:         true,
+			Default:          "user",
+		},
+		"ipmi_username": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "IPMI username",
+			Optional:    true,
+		},
+		"ipmi_password": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "IPMI password",
+			Optional:    true,
+			Sensitive:   true,
+		},
+	}
 }
 
 // hostDataSchema adjust a base schema for data usage
@@ -422,10 +509,10 @@ func hostDataSchema(m map[string]*schema.Schema) (o map[string]*schema.Schema) {
 
 		// computed
 		switch k {
-		case "host", "templates":
+		case "host", "templates", "tag":
 			schema.Optional = true
 			fallthrough
-		case "interface", "groups", "macro", "proxyid", "inventory":
+		case "interface", "groups", "macro", "proxyid", "monitored_by", "proxy_groupid", "inventory":
 			schema.Computed = true
 		}
 
@@ -485,36 +572,48 @@ func hostGenerateInterfaces(d *schema.ResourceData, m interface{}) (interfaces z
 			interfaces[i].InterfaceID = str
 		}
 
-		log.Debug("interface config abc: %+v", api.Config)
-		// version 5 and snmp
-		if api.Config.Version >= 50000 && typeId == zabbix.SNMP {
-			details := zabbix.HostInterfaceDetail{}
-			details.Version = d.Get(prefix + "snmp_version").(string)
-			details.Bulk = "0"
-			if d.Get(prefix + "snmp_bulk").(bool) {
-				details.Bulk = "1"
-			}
-
-			// only pull relevent params
-			//if details.Version == "3" {
-			details.SecurityName = d.Get(prefix + "snmp3_securityname").(string)
-			details.SecurityLevel = HSNMP_SECLEVEL[d.Get(prefix+"snmp3_securitylevel").(string)]
-			details.AuthPassphrase = d.Get(prefix + "snmp3_authpassphrase").(string)
-			details.PrivPassphrase = d.Get(prefix + "snmp3_privpassphrase").(string)
-			details.AuthProtocol = HSNMP_AUTHPROTO[d.Get(prefix+"snmp3_authprotocol").(string)]
-			details.PrivProtocol = HSNMP_PRIVPROTO[d.Get(prefix+"snmp3_privprotocol").(string)]
-			details.ContextName = d.Get(prefix + "snmp3_contextname").(string)
-			//} else {
-			details.Community = d.Get(prefix + "snmp_community").(string)
-			//}
-			//interfaces[i].Details = zabbix.HostInterfaceDetails{details}
-			interfaces[i].Details = &details
+		if interfaces[i].Details, err = hostInterfaceDetails(d, api, prefix); err != nil {
+			return
 		}
 	}
 
 	return
 }
 
+// hostInterfaceDetails snmp details of the interface at prefix, nil for other
+// interface types and servers before 5.0
+func hostInterfaceDetails(d *schema.ResourceData, api *zabbix.API, prefix string) (*zabbix.HostInterfaceDetail, error) {
+	if api.Config.Version < 50000 || HOST_IFACE_TYPES[d.Get(prefix+"type").(string)] != zabbix.SNMP {
+		return nil, nil
+	}
+
+	details := zabbix.HostInterfaceDetail{}
+	details.Version = d.Get(prefix + "snmp_version").(string)
+	details.Bulk = "0"
+	if d.Get(prefix + "snmp_bulk").(bool) {
+		details.Bulk = "1"
+	}
+
+	// only pull relevent params
+	//if details.Version == "3" {
+	details.SecurityName = d.Get(prefix + "snmp3_securityname").(string)
+	details.SecurityLevel = HSNMP_SECLEVEL[d.Get(prefix+"snmp3_securitylevel").(string)]
+	details.AuthPassphrase = d.Get(prefix + "snmp3_authpassphrase").(string)
+	details.PrivPassphrase = d.Get(prefix + "snmp3_privpassphrase").(string)
+	details.AuthProtocol = HSNMP_AUTHPROTO[d.Get(prefix+"snmp3_authprotocol").(string)]
+	details.PrivProtocol = HSNMP_PRIVPROTO[d.Get(prefix+"snmp3_privprotocol").(string)]
+	details.ContextName = d.Get(prefix + "snmp3_contextname").(string)
+	if details.Version == "3" && (details.AuthProtocol > "1" || details.PrivProtocol > "1") {
+		if err := requireVersion(api, 50400, "snmpv3 protocols other than md5, sha, des and aes"); err != nil {
+			return nil, errorAt(prefix+"snmp3_authprotocol", err)
+		}
+	}
+	//} else {
+	details.Community = d.Get(prefix + "snmp_community").(string)
+	//}
+	return &details, nil
+}
+
 func hostGenerateInventory(d *schema.ResourceData) (zabbix.Inventory, error) {
 
 	inventoryCount := d.Get("inventory.#").(int)
@@ -525,6 +624,8 @@ func hostGenerateInventory(d *schema.ResourceData) (zabbix.Inventory, error) {
 		return nil, nil
 	}
 
+	// only send configured fields, and fields removed from the configuration to
+	// clear them, leaving the ones populated by items alone
 	inventory := zabbix.Inventory{}
 	for i := 0; i < inventoryCount; i++ {
 		prefix := fmt.Sprintf("inventory.%d.", i)
@@ -532,6 +633,8 @@ func hostGenerateInventory(d *schema.ResourceData) (zabbix.Inventory, error) {
 		for _, k := range INVENTORY_KEYS {
 			if val, ok := d.GetOk(prefix + k); ok {
 				inventory[k] = val.(string)
+			} else if !d.IsNewResource() && d.HasChange(prefix+k) {
+				inventory[k] = ""
 			}
 		}
 	}
@@ -563,7 +666,6 @@ func buildHostObject(d *schema.ResourceData, m interface{}) (*zabbix.Host, error
 	}
 
 	item.Interfaces = interfaces
-	item.UserMacros = macroGenerate(d)
 	item.Tags = tagGenerate(d)
 	item.Inventory, err = hostGenerateInventory(d)
 
@@ -581,6 +683,200 @@ func buildHostObject(d *schema.ResourceData, m interface{}) (*zabbix.Host, error
 	return &item, nil
 }
 
+// hostParams request params of a host with its typed macros, doing the raw field
+// preparation HostsCreate/HostsUpdate would otherwise do
+func hostParams(d *schema.ResourceData, api *zabbix.API, host *zabbix.Host) (map[string]interface{}, error) {
+	macros, err := macroGenerate(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templateIDs := make([]string, len(host.TemplateIDs))
+	for i, t := range host.TemplateIDs {
+		templateIDs[i] = t.TemplateID
+	}
+	overrides, err := macroOverrideGenerate(d, api, templateIDs)
+
+	if err != nil {
+		return nil, err
+	}
+	macros = append(macros, overrides...)
+
+	for i, in := range host.Interfaces {
+		if in.Details != nil {
+			raw, _ := json.Marshal(in.Details)
+			host.Interfaces[i].RawDetails = json.RawMessage(raw)
+		}
+	}
+	if host.Inventory != nil {
+		raw, _ := json.Marshal(host.Inventory)
+		host.RawInventory = json.RawMessage(raw)
+	}
+	mode := host.InventoryMode
+	host.RawInventoryMode = &mode
+
+	params, err := withMacros(api, host, macros)
+
+	if err != nil {
+		return nil, err
+	}
+	hostSecurityParams(d, params)
+
+	return params, hostMonitoringParams(d, api, params)
+}
+
+// hostDetails host fields zabbix.Host lacks, the proxy ones are 7.0+
+type hostDetails struct {
+	MonitoredBy    int    `json:"monitored_by,string"`
+	ProxyID        string `json:"proxyid"`
+	ProxyGroupID   string `json:"proxy_groupid"`
+	IPMIAuthType   int    `json:"ipmi_authtype,string"`
+	IPMIPrivilege  int    `json:"ipmi_privilege,string"`
+	IPMIUsername   string `json:"ipmi_username"`
+	IPMIPassword   string `json:"ipmi_password"`
+	TLSConnect     int    `json:"tls_connect,string"`
+	TLSAccept      int    `json:"tls_accept,string"`
+	TLSIssuer      string `json:"tls_issuer"`
+	TLSSubject     string `json:"tls_subject"`
+	TLSPSKIdentity string `json:"tls_psk_identity"`
+}
+
+// hostMonitoredBy monitored_by as configured, or derived from the proxy fields
+func hostMonitoredBy(d resourceGetter) int {
+	if v := d.Get("monitored_by").(string); v != "" {
+		return enumValue(v, HOST_MONITORED_BY)
+	}
+	if d.Get("proxy_groupid").(string) != "" {
+		return HOST_MONITORED_BY["proxy_group"]
+	}
+	if id := d.Get("proxyid").(string); id != "" && id != "0" {
+		return HOST_MONITORED_BY["proxy"]
+	}
+	return HOST_MONITORED_BY["server"]
+}
+
+// hostMonitoringCustomizeDiff check the proxy fields match monitored_by, ids
+// only known after apply are left to the server
+func hostMonitoringCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("proxyid") || !d.NewValueKnown("proxy_groupid") {
+		return nil
+	}
+
+	proxyID := d.Get("proxyid").(string)
+	groupID := d.Get("proxy_groupid").(string)
+
+	switch hostMonitoredBy(d) {
+	case HOST_MONITORED_BY["proxy"]:
+		if proxyID == "" || proxyID == "0" {
+			return errors.New("proxyid is required when monitored_by is proxy")
+		}
+	case HOST_MONITORED_BY["proxy_group"]:
+		if groupID == "" {
+			return errors.New("proxy_groupid is required when monitored_by is proxy_group")
+		}
+	}
+	if groupID != "" && hostMonitoredBy(d) != HOST_MONITORED_BY["proxy_group"] {
+		return errors.New("proxy_groupid is only used when monitored_by is proxy_group")
+	}
+
+	return nil
+}
+
+// hostMonitoringParams set the proxy fields of the host params, monitored_by with
+// the proxy or proxy group on 7.0+, the classic proxy_hostid on older servers
+func hostMonitoringParams(d *schema.ResourceData, api *zabbix.API, params map[string]interface{}) error {
+	monitoredBy := hostMonitoredBy(d)
+
+	if api.Config.Version < 70000 {
+		switch monitoredBy {
+		case HOST_MONITORED_BY["proxy_group"]:
+			return requireVersion(api, 70000, "monitoring hosts by proxy groups")
+		case HOST_MONITORED_BY["server"]:
+			params["proxy_hostid"] = "0"
+		}
+		return nil
+	}
+
+	delete(params, "proxy_hostid")
+	params["monitored_by"] = strconv.Itoa(monitoredBy)
+
+	switch monitoredBy {
+	case HOST_MONITORED_BY["proxy"]:
+		params["proxyid"] = d.Get("proxyid").(string)
+	case HOST_MONITORED_BY["proxy_group"]:
+		params["proxy_groupid"] = d.Get("proxy_groupid").(string)
+	}
+	return nil
+}
+
+// hostSecurityParams set the ipmi and encryption fields of the host params
+func hostSecurityParams(d *schema.ResourceData, params map[string]interface{}) {
+	params["ipmi_authtype"] = strconv.Itoa(enumValue(d.Get("ipmi_authtype").(string), HOST_IPMI_AUTHTYPE))
+	params["ipmi_privilege"] = strconv.Itoa(enumValue(d.Get("ipmi_privilege").(string), HOST_IPMI_PRIVILEGE))
+	params["ipmi_username"] = d.Get("ipmi_username").(string)
+	params["ipmi_password"] = d.Get("ipmi_password").(string)
+
+	params["tls_connect"] = strconv.Itoa(enumValue(d.Get("tls_connect").(string), PROXY_TLS))
+	params["tls_accept"] = strconv.Itoa(buildTLSAccept(d))
+	params["tls_issuer"] = d.Get("tls_issuer").(string)
+	params["tls_subject"] = d.Get("tls_subject").(string)
+
+	// the psk is write-only, only send it when it is new, any other time the
+	// state only holds its hash and the server keeps the current one
+	if d.IsNewResource() || d.HasChange("tls_psk") {
+		if v := d.Get("tls_psk").(string); v != "" {
+			params["tls_psk_identity"] = d.Get("tls_psk_identity").(string)
+			params["tls_psk"] = v
+		}
+	}
+}
+
+// hostObject host read back along with the fields zabbix.Host lacks
+type hostObject struct {
+	zabbix.Host
+	hostDetails
+
+	// macros including their type, zabbix.Host reads them without
+	Macros []UserMacro `json:"macros"`
+}
+
+// hostsGet host.get into hostObjects, unpacking interface details and the
+// inventory the way api.HostsGet does
+func hostsGet(api *zabbix.API, params zabbix.Params) ([]hostObject, error) {
+	var hosts []hostObject
+	if err := api.CallWithErrorParse("host.get", params, &hosts); err != nil {
+		return nil, err
+	}
+
+	for i := range hosts {
+		h := &hosts[i]
+		for j, in := range h.Interfaces {
+			h.Interfaces[j].Details = nil
+			if len(in.RawDetails) > 0 && in.RawDetails[0] == '{' {
+				details := &zabbix.HostInterfaceDetail{}
+				if err := json.Unmarshal(in.RawDetails, details); err != nil {
+					return nil, fmt.Errorf("unreadable details of interface %s: %s", in.InterfaceID, err)
+				}
+				h.Interfaces[j].Details = details
+			}
+		}
+
+		// omitted = disabled
+		h.InventoryMode = zabbix.InventoryDisabled
+		if h.RawInventoryMode != nil {
+			h.InventoryMode = *h.RawInventoryMode
+		}
+
+		if len(h.RawInventory) > 0 && h.RawInventory[0] == '{' {
+			if err := json.Unmarshal(h.RawInventory, &h.Inventory); err != nil {
+				return nil, fmt.Errorf("unreadable inventory of host %s: %s", h.HostID, err)
+			}
+		}
+	}
+	return hosts, nil
+}
+
 // resourceHostCreate terraform create handler
 func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
@@ -591,29 +887,41 @@ func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	items := []zabbix.Host{*item}
+	params, err := hostParams(d, api, item)
+
+	if err != nil {
+		return err
+	}
 
-	err = api.HostsCreate(items)
+	var response zabbix.Response
+	err = retryTransientCreate(api, d.Timeout(schema.TimeoutCreate), func(api *zabbix.API) (err error) {
+		response, err = api.CallWithError("host.create", params)
+		return
+	})
 
 	if err != nil {
 		return err
 	}
 
-	log.Trace("created host: %+v", items[0])
+	result := response.Result.(map[string]interface{})
+	item.HostID = result["hostids"].([]interface{})[0].(string)
 
-	d.SetId(items[0].HostID)
+	log.Trace("created host: %s", item.HostID)
 
-	return resourceHostRead(d, m)
+	d.SetId(item.HostID)
+
+	return readAfterWrite(d, m, resourceHost().Schema, resourceHostRead)
 }
 
 // dataHostRead read handler for data resource
 func dataHostRead(d *schema.ResourceData, m interface{}) error {
 	params := zabbix.Params{
+		"output":                hostOutput(m.(*zabbix.API)),
 		"selectInterfaces":      "extend",
-		"selectParentTemplates": "extend",
-		"selectGroups":          "extend",
-		"selectMacros":          "extend",
-		"selectTags":            "extend",
+		"selectParentTemplates": []string{"templateid"},
+		"selectGroups":          []string{"groupid"},
+		"selectTags":            []string{"tag", "value"},
+		"selectMacros":          macroOutput(m.(*zabbix.API)),
 		"selectInventory":       "extend",
 		"filter":                map[string]interface{}{},
 	}
@@ -625,36 +933,69 @@ func dataHostRead(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
-	if len(params["filter"].(map[string]interface{})) < 1 {
+	if v := d.Get("tag").(*schema.Set); v.Len() > 0 {
+		params["tags"] = tagFilter(d)
+	}
+
+	if len(params["filter"].(map[string]interface{})) < 1 && params["tags"] == nil {
 		return errors.New("no host lookup attribute")
 	}
 	log.Debug("performing data lookup with params: %#v", params)
 
-	return hostRead(d, m, params)
+	return hostRead(d, m, params, false)
 }
 
 // resourceHostRead read handler for resource
 func resourceHostRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of hostgroup with id %s", d.Id())
 
-	return hostRead(d, m, zabbix.Params{
+	params := zabbix.Params{
+		"output":                hostOutput(m.(*zabbix.API)),
 		"selectInterfaces":      "extend",
-		"selectParentTemplates": "extend",
-		"selectGroups":          "extend",
-		"selectMacros":          "extend",
-		"selectTags":            "extend",
-		"selectInventory":       "extend",
+		"selectParentTemplates": []string{"templateid"},
+		"selectGroups":          []string{"groupid"},
+		"selectTags":            []string{"tag", "value"},
+		"selectMacros":          macroOutput(m.(*zabbix.API)),
 		"hostids":               d.Id(),
-	})
+	}
+
+	// only the managed inventory fields are read back
+	fields := []string{}
+	for _, k := range INVENTORY_KEYS {
+		if _, ok := d.GetOk("inventory.0." + k); ok {
+			fields = append(fields, k)
+		}
+	}
+	if len(fields) > 0 {
+		params["selectInventory"] = fields
+	}
+
+	return hostRead(d, m, params, true)
+}
+
+// hostOutput host fields read back, the proxy field was renamed in 7.0
+func hostOutput(api *zabbix.API) []string {
+	fields := []string{
+		"hostid", "host", "name", "status", "inventory_mode",
+		"ipmi_authtype", "ipmi_privilege", "ipmi_username", "ipmi_password",
+		"tls_connect", "tls_accept", "tls_issuer", "tls_subject", "tls_psk_identity",
+	}
+	if api.Config.Version < 70000 {
+		fields = append(fields, "proxy_hostid")
+	} else {
+		fields = append(fields, "monitored_by", "proxyid", "proxy_groupid")
+	}
+	return fields
 }
 
-// hostRead common host read function
-func hostRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
+// hostRead common host read function, managed limits the inventory to the
+// fields set in the configuration
+func hostRead(d *schema.ResourceData, m interface{}, params zabbix.Params, managed bool) error {
 	api := m.(*zabbix.API)
 
 	log.Debug("Lookup of host with params %#v", params)
 
-	hosts, err := api.HostsGet(params)
+	hosts, err := hostsGet(api, params)
 
 	if err != nil {
 		return err
@@ -669,25 +1010,90 @@ func hostRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error
 	}
 	host := hosts[0]
 
-	log.Debug("Got host: %+v", host)
+	log.Debug("Got host: %s", host.HostID)
 
 	d.SetId(host.HostID)
 	d.Set("name", host.Name)
-	d.Set("host", host.Host)
-	d.Set("proxyid", host.ProxyID)
+	d.Set("host", host.Host.Host)
+	setHostMonitoring(d, api, host.Host, &host.hostDetails, managed)
+	if managed {
+		setHostSecurity(d, &host.hostDetails)
+	}
 	d.Set("enabled", host.Status == 0)
 	d.Set("inventory_mode", HINV_LOOKUP_REV[host.InventoryMode])
 
-	d.Set("interface", flattenHostInterfaces(host, d, m))
+	d.Set("interface", flattenHostInterfaces(host.Host, d, m))
 	d.Set("templates", flattenTemplateIds(host.ParentTemplateIDs))
-	d.Set("inventory", flattenInventory(host))
+	if managed {
+		d.Set("inventory", flattenManagedInventory(host.Host, d))
+	} else {
+		d.Set("inventory", flattenInventory(host.Host))
+	}
 	d.Set("groups", flattenHostGroupIds(host.GroupIds))
-	d.Set("macro", flattenMacros(host.UserMacros))
+	macros := host.Macros
+
+	// selectMacros only returns host level macros, values inherited from the
+	// templates never show up here, so overrides that are gone are planned again
+	if managed {
+		var overrides []UserMacro
+		macros, overrides = splitMacroOverrides(macros, d)
+		d.Set("macro_override", flattenMacroList(overrides, d, "macro_override"))
+	}
+	d.Set("macro", flattenMacros(macros, d))
 	d.Set("tag", flattenTags(host.Tags))
 
 	return nil
 }
 
+// setHostMonitoring read the proxy fields, monitored_by is only set on resources
+// configuring it, it is derived from the other fields otherwise
+func setHostMonitoring(d *schema.ResourceData, api *zabbix.API, host zabbix.Host, details *hostDetails, managed bool) {
+	monitor := details
+	if api.Config.Version < 70000 {
+		monitor = &hostDetails{ProxyID: host.ProxyID, ProxyGroupID: "0"}
+		if monitor.ProxyID != "" && monitor.ProxyID != "0" {
+			monitor.MonitoredBy = HOST_MONITORED_BY["proxy"]
+		}
+	}
+
+	proxyID := monitor.ProxyID
+	if proxyID == "" {
+		proxyID = "0"
+	}
+	groupID := monitor.ProxyGroupID
+	if groupID == "0" {
+		groupID = ""
+	}
+
+	// the server picks the proxy of a proxy group, that is not configuration
+	if managed && monitor.MonitoredBy == HOST_MONITORED_BY["proxy_group"] {
+		proxyID = d.Get("proxyid").(string)
+	}
+
+	d.Set("proxyid", proxyID)
+	d.Set("proxy_groupid", groupID)
+	if !managed || d.Get("monitored_by").(string) != "" {
+		setEnum(d, "monitored_by", monitor.MonitoredBy, HOST_MONITORED_BY, HOST_MONITORED_BY_REV)
+	}
+}
+
+// setHostSecurity read the ipmi and encryption fields, the psk is write-only and
+// newer servers do not return the psk identity either, so both are kept
+func setHostSecurity(d *schema.ResourceData, details *hostDetails) {
+	setEnum(d, "ipmi_authtype", details.IPMIAuthType, HOST_IPMI_AUTHTYPE, HOST_IPMI_AUTHTYPE_REV)
+	setEnum(d, "ipmi_privilege", details.IPMIPrivilege, HOST_IPMI_PRIVILEGE, HOST_IPMI_PRIVILEGE_REV)
+	d.Set("ipmi_username", details.IPMIUsername)
+	d.Set("ipmi_password", details.IPMIPassword)
+
+	setEnum(d, "tls_connect", details.TLSConnect, PROXY_TLS, PROXY_TLS_REV)
+	setTLSAccept(d, details.TLSAccept)
+	d.Set("tls_issuer", details.TLSIssuer)
+	d.Set("tls_subject", details.TLSSubject)
+	if details.TLSPSKIdentity != "" {
+		d.Set("tls_psk_identity", details.TLSPSKIdentity)
+	}
+}
+
 // flattenInventory converts API response into terraform structs
 func flattenInventory(host zabbix.Host) []interface{} {
 	if host.Inventory == nil {
@@ -700,6 +1106,22 @@ func flattenInventory(host zabbix.Host) []interface{} {
 	return []interface{}{obj}
 }
 
+// flattenManagedInventory converts API response into terraform structs, keeping
+// only the fields already in the configuration or state, so fields populated by
+// items in automatic mode don't show up as a diff
+func flattenManagedInventory(host zabbix.Host, d *schema.ResourceData) []interface{} {
+	if host.Inventory == nil || d.Get("inventory.#").(int) < 1 {
+		return []interface{}{}
+	}
+	obj := map[string]interface{}{}
+	for _, k := range INVENTORY_KEYS {
+		if _, ok := d.GetOk("inventory.0." + k); ok {
+			obj[k] = host.Inventory[k]
+		}
+	}
+	return []interface{}{obj}
+}
+
 // flattenHostInterfaces convert API response into terraform structs
 func flattenHostInterfaces(host zabbix.Host, d *schema.ResourceData, m interface{}) []interface{} {
 	api := m.(*zabbix.API)
@@ -714,88 +1136,128 @@ func flattenHostInterfaces(host zabbix.Host, d *schema.ResourceData, m interface
 			"port": port,
 			"type": HOST_IFACE_TYPES_REV[host.Interfaces[i].Type],
 		}
+		flattenInterfaceDetails(params, host.Interfaces[i].Details, d, fmt.Sprintf("interface.%d.", i), api)
 
-		// Set defaults, as these may or may not be bounced back
-		arr := []string{
-			"snmp_version",
-			"snmp_community",
-			"snmp3_authpassphrase",
-			"snmp3_authprotocol",
-			"snmp3_contextname",
-			"snmp3_privpassphrase",
-			"snmp3_privprotocol",
-			"snmp3_securitylevel",
-			"snmp3_securityname",
-			"snmp_bulk",
-		}
+		log.Debug("Got host interface: %s", host.Interfaces[i].InterfaceID)
+		val[i] = params
+	}
+	return val
+}
 
-		for _, v := range arr {
-			params[v] = hostSchemaBase["interface"].Elem.(*schema.Resource).Schema[v].Default
-		}
+// flattenInterfaceDetails add the snmp fields of an interface to its flattened
+// form, with defaults for the ones the api does not bounce back
+func flattenInterfaceDetails(params map[string]interface{}, details *zabbix.HostInterfaceDetail, d *schema.ResourceData, prefix string, api *zabbix.API) {
+	// Set defaults, as these may or may not be bounced back
+	arr := []string{
+		"snmp_version",
+		"snmp_community",
+		"snmp3_authpassphrase",
+		"snmp3_authprotocol",
+		"snmp3_contextname",
+		"snmp3_privpassphrase",
+		"snmp3_privprotocol",
+		"snmp3_securitylevel",
+		"snmp3_securityname",
+		"snmp_bulk",
+	}
+
+	for _, v := range arr {
+		params[v] = hostSchemaBase["interface"].Elem.(*schema.Resource).Schema[v].Default
+	}
 
-		// need to handle detail
-		details := host.Interfaces[i].Details
-		log.Debug("got details: %+v", details)
-		if api.Config.Version >= 50000 && params["type"] == "snmp" && details != nil {
-			log.Debug("interface new logic")
-			params["snmp_version"] = details.Version
-			params["snmp_bulk"] = details.Bulk == "1"
-
-			if params["snmp_version"] != "3" {
-				params["snmp_community"] = details.Community
-			} else {
-				params["snmp3_securityname"] = details.SecurityName
-				params["snmp3_securitylevel"] = HSNMP_SECLEVEL_REV[details.SecurityLevel]
-				params["snmp3_authpassphrase"] = details.AuthPassphrase
-				params["snmp3_privpassphrase"] = details.PrivPassphrase
-				params["snmp3_authprotocol"] = HSNMP_AUTHPROTO_REV[details.AuthProtocol]
-				params["snmp3_privprotocol"] = HSNMP_PRIVPROTO_REV[details.PrivProtocol]
-				params["snmp3_contextname"] = details.ContextName
+	// need to handle detail
+	if api.Config.Version >= 50000 && params["type"] == "snmp" && details != nil {
+		log.Debug("interface new logic")
+		params["snmp_version"] = details.Version
+		params["snmp_bulk"] = details.Bulk == "1"
+
+		if params["snmp_version"] != "3" {
+			params["snmp_community"] = details.Community
+		} else {
+			params["snmp3_securityname"] = details.SecurityName
+			params["snmp3_securitylevel"] = HSNMP_SECLEVEL_REV[details.SecurityLevel]
+			params["snmp3_authpassphrase"] = details.AuthPassphrase
+			params["snmp3_privpassphrase"] = details.PrivPassphrase
+			// passphrases the api does not return are kept from the configuration
+			for _, key := range []string{"snmp3_authpassphrase", "snmp3_privpassphrase"} {
+				if params[key] == "" {
+					params[key] = d.Get(prefix + key)
+				}
 			}
+			params["snmp3_authprotocol"] = HSNMP_AUTHPROTO_REV[details.AuthProtocol]
+			params["snmp3_privprotocol"] = HSNMP_PRIVPROTO_REV[details.PrivProtocol]
+			params["snmp3_contextname"] = details.ContextName
 		}
-
-		log.Debug("Got host interface: %+v", params)
-		val[i] = params
 	}
-	return val
 }
 
 // resourceHostUpdate terraform update resource handler
 func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
+	statusOnly := hostStatusOnly(d)
 	item, err := buildHostObject(d, m)
 
 	if err != nil {
 		return err
 	}
 
-	// templates may need a bit extra effort
-	if d.HasChange("templates") {
-		old, new := d.GetChange("templates")
-		diff := old.(*schema.Set).Difference(new.(*schema.Set))
-
-		// removals, we need to unlink and clear
-		if diff.Len() > 0 {
-			item.TemplateIDsClear = buildTemplateIds(diff)
-		}
-	}
+	// removed templates are unlinked, and cleared unless disabled
+	item.TemplateIDsClear = buildTemplateIdsClear(d)
 
 	item.HostID = d.Id()
 
-	items := []zabbix.Host{*item}
+	params := map[string]interface{}{
+		"hostid":         item.HostID,
+		"status":         strconv.Itoa(int(item.Status)),
+		"inventory_mode": strconv.Itoa(int(item.InventoryMode)),
+	}
+	if !statusOnly {
+		params, err = hostParams(d, api, item)
+	}
 
-	err = api.HostsUpdate(items)
+	if err != nil {
+		return err
+	}
+
+	err = retryTransient(api, d.Timeout(schema.TimeoutUpdate), func(api *zabbix.API) error {
+		_, err := api.CallWithError("host.update", params)
+		return err
+	})
 
 	if err != nil {
 		return err
 	}
 
-	return resourceHostRead(d, m)
+	return readAfterWrite(d, m, resourceHost().Schema, resourceHostRead)
+}
+
+// hostStatusOnly whether enabled and inventory_mode are the only changes, those
+// are sent on their own so pausing a host leaves its interfaces and templates alone
+func hostStatusOnly(d *schema.ResourceData) bool {
+	for k := range resourceHost().Schema {
+		switch k {
+		case "enabled", "inventory_mode":
+			continue
+		}
+		if d.HasChange(k) {
+			return false
+		}
+	}
+	return d.HasChanges("enabled", "inventory_mode")
 }
 
 // resourceHostDelete terraform delete resource handler
 func resourceHostDelete(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
-	return api.HostsDeleteByIds([]string{d.Id()})
+
+	if err := checkDeletionProtection(d, "host"); err != nil {
+		return err
+	}
+	if err := checkSafeDelete(d, api, "host", hostDependents(d.Id())); err != nil {
+		return err
+	}
+	return retryTransient(api, d.Timeout(schema.TimeoutDelete), func(api *zabbix.API) error {
+		return api.HostsDeleteByIds([]string{d.Id()})
+	})
 }
@@ -1,8 +1,13 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccResourceHost(t *testing.T) {
@@ -11,12 +16,12 @@ func TestAccResourceHost(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		Providers: testAccProviders,
+		ProviderFactories: testAccProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: testAccResourceHostBasic(),
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("zabbix_host.testhost", "host", "test-host"),
+					resource.TestCheckResourceAttr("zabbix_host.testhost", "host", "tf-acc-host"),
 				),
 			},
 			{
@@ -38,10 +43,10 @@ func TestAccResourceHost(t *testing.T) {
 func testAccResourceHostBasic() string {
 	return `
 resource "zabbix_hostgroup" "testgrp" {
-	name = "test-group" 
+	name = "tf-acc-group" 
 }
 resource "zabbix_host" "testhost" {
-	host   = "test-host"
+	host   = "tf-acc-host"
 	groups = [zabbix_hostgroup.testgrp.id]
 	interface {
 		type = "snmp"
@@ -54,10 +59,10 @@ resource "zabbix_host" "testhost" {
 func testAccResourceHostWithInventory() string {
 	return `
 resource "zabbix_hostgroup" "testgrp2" {
-	name = "test-group2" 
+	name = "tf-acc-group2" 
 }
 resource "zabbix_host" "testhost2" {
-	host   = "test-host2"
+	host   = "tf-acc-host2"
 	groups = [zabbix_hostgroup.testgrp2.id]
 	interface {
 		type = "snmp"
@@ -71,10 +76,10 @@ resource "zabbix_host" "testhost2" {
 func testAccResourceHostWithInventoryUpdate() string {
 	return `
 resource "zabbix_hostgroup" "testgrp2" {
-	name = "test-group2" 
+	name = "tf-acc-group2" 
 }
 resource "zabbix_host" "testhost2" {
-	host   = "test-host2"
+	host   = "tf-acc-host2"
 	groups = [zabbix_hostgroup.testgrp2.id]
 	interface {
 		type = "snmp"
@@ -84,3 +89,127 @@ resource "zabbix_host" "testhost2" {
 }
 `
 }
+
+func TestUnitResourceHostStatus(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	groupID := server.Seed("hostgroup", map[string]interface{}{"name": "tf-unit-group"})
+
+	var hostID string
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceHostStatus(server.APIURL(), groupID, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.test", "enabled", "true"),
+					func(s *terraform.State) error {
+						hostID = s.RootModule().Resources["zabbix_host.test"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				// pausing the host is an update of its status alone
+				Config: testUnitResourceHostStatus(server.APIURL(), groupID, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host.test", "enabled", "false"),
+					func(s *terraform.State) error {
+						if id := s.RootModule().Resources["zabbix_host.test"].Primary.ID; id != hostID {
+							return fmt.Errorf("host was replaced, id %s became %s", hostID, id)
+						}
+						calls := server.Calls("host.update")
+						if len(calls) != 1 {
+							return fmt.Errorf("expected 1 host.update call, got %d", len(calls))
+						}
+
+						var params map[string]interface{}
+						if err := json.Unmarshal(calls[0].Params, &params); err != nil {
+							return err
+						}
+						if _, ok := params["interfaces"]; ok || len(params) != 3 || params["status"] != "1" {
+							return fmt.Errorf("unexpected host.update params: %s", calls[0].Params)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceHostStatus(url, groupID string, enabled bool) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_host" "test" {
+	host    = "tf-unit-host"
+	groups  = [%q]
+	enabled = %t
+	interface {
+		ip = "127.0.0.1"
+	}
+}
+`, testUnitProviderConfig(url), groupID, enabled)
+}
+
+func TestUnitResourceHostPSK(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	groupID := server.Seed("hostgroup", map[string]interface{}{"name": "tf-unit-group"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceHostPSK(server.APIURL(), groupID, "tf-unit-identity", testUnitPSK1, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCallParam(server, "host.create", "tls_psk", testUnitPSK1),
+					resource.TestCheckResourceAttr("zabbix_host.test", "macro.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_host.test", "macro.0.value", "tf-unit-value"),
+					func(*terraform.State) error {
+						// macros come with the host.get of the read
+						if calls := server.Calls("usermacro.get"); len(calls) != 0 {
+							return fmt.Errorf("expected no usermacro.get call, got %d", len(calls))
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config:      testUnitResourceHostPSK(server.APIURL(), groupID, "tf-unit-identity", testUnitPSK1, 2),
+				ExpectError: regexp.MustCompile("tls_psk_version changed but tls_psk did not"),
+			},
+			{
+				Config:      testUnitResourceHostPSK(server.APIURL(), groupID, "tf-unit-renamed", testUnitPSK1, 1),
+				ExpectError: regexp.MustCompile("tls_psk_identity changed but tls_psk did not"),
+			},
+			{
+				Config: testUnitResourceHostPSK(server.APIURL(), groupID, "tf-unit-renamed", testUnitPSK2, 2),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCallParam(server, "host.update", "tls_psk", testUnitPSK2),
+					testUnitCheckCallParam(server, "host.update", "tls_psk_identity", "tf-unit-renamed"),
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceHostPSK(url, groupID, identity, psk string, version int) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_host" "test" {
+	host             = "tf-unit-host"
+	groups           = [%q]
+	tls_connect      = "psk"
+	tls_accept_modes = ["psk"]
+	tls_psk_identity = %q
+	tls_psk          = %q
+	tls_psk_version  = %d
+	interface {
+		ip = "127.0.0.1"
+	}
+	macro {
+		name  = "{$TF_UNIT}"
+		value = "tf-unit-value"
+	}
+}
+`, testUnitProviderConfig(url), groupID, identity, psk, version)
+}
@@ -3,21 +3,19 @@ package provider
 import (
 	"errors"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // resourceHostgroup terraform resource handler
 func resourceHostgroup() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceHostgroupCreate,
-		Read:   resourceHostgroupRead,
-		Update: resourceHostgroupUpdate,
-		Delete: resourceHostgroupDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
+		CreateContext: contextCRUD(resourceHostgroupCreate),
+		ReadContext:   contextCRUD(resourceHostgroupRead),
+		UpdateContext: contextCRUD(resourceHostgroupUpdate),
+		DeleteContext: contextCRUD(resourceHostgroupDelete),
+		Importer:      importByName(lookupByField("hostgroup.get", "groupid", "name")),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -33,7 +31,7 @@ func resourceHostgroup() *schema.Resource {
 // dataHostgroup terraform data handler
 func dataHostgroup() *schema.Resource {
 	return &schema.Resource{
-		Read: dataHostgroupRead,
+		ReadContext: contextCRUD(dataHostgroupRead),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -62,11 +60,11 @@ func resourceHostgroupCreate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	log.Trace("created hostgroup: %+v", items[0])
+	log.Trace("created hostgroup: %s", items[0].GroupID)
 
 	d.SetId(items[0].GroupID)
 
-	return resourceHostgroupRead(d, m)
+	return readAfterWrite(d, m, resourceHostgroup().Schema, resourceHostgroupRead)
 }
 
 // hostgroupRead terraform hostgroup read function
@@ -88,7 +86,7 @@ func hostgroupRead(d *schema.ResourceData, m interface{}, params zabbix.Params)
 	}
 	t := hostgroups[0]
 
-	log.Debug("Got hostgroup: %+v", t)
+	log.Debug("Got hostgroup: %s", t.GroupID)
 
 	d.SetId(t.GroupID)
 	d.Set("name", t.Name)
@@ -99,6 +97,7 @@ func hostgroupRead(d *schema.ResourceData, m interface{}, params zabbix.Params)
 // dataHostgroupRead terraform data resource read handler
 func dataHostgroupRead(d *schema.ResourceData, m interface{}) error {
 	return hostgroupRead(d, m, zabbix.Params{
+		"output": []string{"groupid", "name"},
 		"filter": map[string]interface{}{
 			"name": d.Get("name"),
 		},
@@ -110,6 +109,7 @@ func resourceHostgroupRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of hostgroup with id %s", d.Id())
 
 	return hostgroupRead(d, m, zabbix.Params{
+		"output":   []string{"groupid", "name"},
 		"groupids": d.Id(),
 	})
 }
@@ -131,7 +131,7 @@ func resourceHostgroupUpdate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	return resourceHostgroupRead(d, m)
+	return readAfterWrite(d, m, resourceHostgroup().Schema, resourceHostgroupRead)
 }
 
 // resourceHostgroupDelete terraform resource delete handler
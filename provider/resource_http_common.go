@@ -3,8 +3,8 @@ package provider
 import (
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -137,10 +137,11 @@ var schemaHttp = map[string]*schema.Schema{
 		Description: "http status code",
 	},
 	"timeout": &schema.Schema{
-		Type:        schema.TypeString,
-		Optional:    true,
-		Description: "http request timeout",
-		Default:     "3s",
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "http request timeout",
+		ValidateFunc: validateTimeSuffix,
+		Default:      "3s",
 	},
 	"verify_host": &schema.Schema{
 		Type:        schema.TypeBool,
@@ -165,12 +166,12 @@ var schemaHttp = map[string]*schema.Schema{
 // resourceItemHttp Http item resource handler
 func resourceItemHttp() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemHttpModFunc, itemHttpReadFunc),
-		Read:   itemGetReadWrapper(itemHttpReadFunc),
-		Update: itemGetUpdateWrapper(itemHttpModFunc, itemHttpReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemHttpModFunc, itemHttpReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemHttpReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemHttpModFunc, itemHttpReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaHttp),
@@ -178,12 +179,12 @@ func resourceItemHttp() *schema.Resource {
 }
 func resourceProtoItemHttp() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemHttpModFunc, itemHttpReadFunc),
-		Read:   protoItemGetReadWrapper(itemHttpReadFunc),
-		Update: protoItemGetUpdateWrapper(itemHttpModFunc, itemHttpReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemHttpModFunc, itemHttpReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemHttpReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemHttpModFunc, itemHttpReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaHttp),
@@ -191,12 +192,13 @@ func resourceProtoItemHttp() *schema.Resource {
 }
 func resourceLLDHttp() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldHttpModFunc, lldHttpReadFunc),
-		Read:   lldGetReadWrapper(lldHttpReadFunc),
-		Update: lldGetUpdateWrapper(lldHttpModFunc, lldHttpReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldHttpModFunc, lldHttpReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldHttpReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldHttpModFunc, lldHttpReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema, schemaHttp),
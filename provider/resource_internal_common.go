@@ -1,19 +1,19 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // terraform resource handler for item type
 func resourceItemInternal() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemInternalModFunc, itemInternalReadFunc),
-		Read:   itemGetReadWrapper(itemInternalReadFunc),
-		Update: itemGetUpdateWrapper(itemInternalModFunc, itemInternalReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemInternalModFunc, itemInternalReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemInternalReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemInternalModFunc, itemInternalReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema),
@@ -21,12 +21,12 @@ func resourceItemInternal() *schema.Resource {
 }
 func resourceProtoItemInternal() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemInternalModFunc, itemInternalReadFunc),
-		Read:   protoItemGetReadWrapper(itemInternalReadFunc),
-		Update: protoItemGetUpdateWrapper(itemInternalModFunc, itemInternalReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemInternalModFunc, itemInternalReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemInternalReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemInternalModFunc, itemInternalReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema),
@@ -34,12 +34,13 @@ func resourceProtoItemInternal() *schema.Resource {
 }
 func resourceLLDInternal() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldInternalModFunc, lldInternalReadFunc),
-		Read:   lldGetReadWrapper(lldInternalReadFunc),
-		Update: lldGetUpdateWrapper(lldInternalModFunc, lldInternalReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldInternalModFunc, lldInternalReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldInternalReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldInternalModFunc, lldInternalReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema),
@@ -0,0 +1,577 @@
+package provider
+
+import (
+	"context"
+
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// MAINTENANCE_PERIOD maintenance time period types
+var MAINTENANCE_PERIOD = map[string]int{
+	"one_time": 0,
+	"daily":    2,
+	"weekly":   3,
+	"monthly":  4,
+}
+var MAINTENANCE_PERIOD_REV = map[int]string{}
+
+// MAINTENANCE_TAGS_EVAL_TYPE how the problem tags of a maintenance are combined
+var MAINTENANCE_TAGS_EVAL_TYPE = map[string]int{
+	"and_or": 0,
+	"or":     2,
+}
+var MAINTENANCE_TAGS_EVAL_TYPE_REV = map[int]string{}
+
+// PROBLEM_TAG_OPERATOR how problem tag values are matched by maintenances and services
+var PROBLEM_TAG_OPERATOR = map[string]int{
+	"equals":   0,
+	"contains": 2,
+}
+var PROBLEM_TAG_OPERATOR_REV = map[int]string{}
+
+// MAINTENANCE_WEEKDAYS bits of the dayofweek mask
+var MAINTENANCE_WEEKDAYS = map[string]int{
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 4,
+	"thursday":  8,
+	"friday":    16,
+	"saturday":  32,
+	"sunday":    64,
+}
+var MAINTENANCE_WEEKDAYS_ARR = []string{}
+
+// MAINTENANCE_MONTHS bits of the month mask
+var MAINTENANCE_MONTHS = map[string]int{
+	"january":   1,
+	"february":  2,
+	"march":     4,
+	"april":     8,
+	"may":       16,
+	"june":      32,
+	"july":      64,
+	"august":    128,
+	"september": 256,
+	"october":   512,
+	"november":  1024,
+	"december":  2048,
+}
+var MAINTENANCE_MONTHS_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range MAINTENANCE_PERIOD {
+		MAINTENANCE_PERIOD_REV[v] = k
+	}
+	for k, v := range MAINTENANCE_TAGS_EVAL_TYPE {
+		MAINTENANCE_TAGS_EVAL_TYPE_REV[v] = k
+	}
+	for k, v := range PROBLEM_TAG_OPERATOR {
+		PROBLEM_TAG_OPERATOR_REV[v] = k
+	}
+	for k := range MAINTENANCE_WEEKDAYS {
+		MAINTENANCE_WEEKDAYS_ARR = append(MAINTENANCE_WEEKDAYS_ARR, k)
+	}
+	for k := range MAINTENANCE_MONTHS {
+		MAINTENANCE_MONTHS_ARR = append(MAINTENANCE_MONTHS_ARR, k)
+	}
+	return false
+}()
+
+// maintenanceObject maintenance with its time periods and problem tags
+type maintenanceObject struct {
+	Maintenance
+	TimePeriods  []maintenanceTimePeriod `json:"timeperiods"`
+	TagsEvalType int                     `json:"tags_evaltype,string"`
+	Tags         []maintenanceTag        `json:"tags"`
+}
+
+// maintenanceTag problem tag a maintenance is limited to
+type maintenanceTag struct {
+	Tag      string `json:"tag"`
+	Operator int    `json:"operator,string"`
+	Value    string `json:"value"`
+}
+
+// maintenanceTimePeriod time period of a maintenance
+type maintenanceTimePeriod struct {
+	TimePeriodType int   `json:"timeperiod_type,string"`
+	Period         int   `json:"period,string"`
+	StartDate      int64 `json:"start_date,string"`
+	StartTime      int   `json:"start_time,string"`
+	Every          int   `json:"every,string"`
+	DayOfWeek      int   `json:"dayofweek,string"`
+	Day            int   `json:"day,string"`
+	Month          int   `json:"month,string"`
+}
+
+// resourceMaintenance terraform resource handler
+func resourceMaintenance() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceMaintenanceCreate),
+		ReadContext:   contextCRUD(resourceMaintenanceRead),
+		UpdateContext: contextCRUD(resourceMaintenanceUpdate),
+		DeleteContext: contextCRUD(resourceMaintenanceDelete),
+		Importer:      importByName(lookupByField("maintenance.get", "maintenanceid", "name")),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the maintenance.",
+				Required:     true,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Description of the maintenance.",
+				Optional:    true,
+			},
+			"active_since": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "Time when the maintenance becomes active (unix timestamp).",
+				Required:    true,
+			},
+			"active_till": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "Time when the maintenance stops being active (unix timestamp).",
+				Required:    true,
+			},
+			"collect_data": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether data is collected during the maintenance.",
+				Optional:    true,
+				Default:     true,
+			},
+			"hosts": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Host IDs under maintenance.",
+				Optional:    true,
+			},
+			"groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Host Group IDs under maintenance.",
+				Optional:    true,
+			},
+			"tags_evaltype": &schema.Schema{
+				Type:             schema.TypeString,
+				ValidateFunc:     validateEnum(MAINTENANCE_TAGS_EVAL_TYPE),
+				DiffSuppressFunc: suppressEnumDiff(MAINTENANCE_TAGS_EVAL_TYPE),
+				Description:      "How tags are combined. Possible values: and_or (0, default), or (2).",
+				Optional:         true,
+				Default:          "and_or",
+			},
+			"tag": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Problem tags the maintenance is limited to, problems without matching tags are not suppressed.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag": &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "Tag name.",
+							Required:     true,
+						},
+						"operator": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(PROBLEM_TAG_OPERATOR),
+							DiffSuppressFunc: suppressEnumDiff(PROBLEM_TAG_OPERATOR),
+							Description:      "How the value is matched. Possible values: equals (0), contains (2, default).",
+							Optional:         true,
+							Default:          "contains",
+						},
+						"value": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Tag value.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"timeperiod": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Periods the maintenance is in effect within active_since and active_till.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(MAINTENANCE_PERIOD),
+							DiffSuppressFunc: suppressEnumDiff(MAINTENANCE_PERIOD),
+							Description:      "Period type. Possible values: one_time (0, default), daily (2), weekly (3), monthly (4).",
+							Optional:         true,
+							Default:          "one_time",
+						},
+						"period": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntAtLeast(300),
+							Description:  "Duration of the period in seconds, at least 300.",
+							Optional:     true,
+							Default:      3600,
+						},
+						"start_date": &schema.Schema{
+							Type:        schema.TypeInt,
+							Description: "Start of a one_time period (unix timestamp).",
+							Optional:    true,
+						},
+						"start_time": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntBetween(0, 86399),
+							Description:  "Start of daily, weekly and monthly periods in seconds since midnight.",
+							Optional:     true,
+							Default:      0,
+						},
+						"every": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "Every how many days or weeks daily and weekly periods recur. For monthly periods on day_of_week, the week of the month: 1-4, or 5 for the last one.",
+							Optional:     true,
+							Default:      1,
+						},
+						"day_of_week": &schema.Schema{
+							Type:        schema.TypeSet,
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(MAINTENANCE_WEEKDAYS_ARR, false)},
+							Description: "Days of weekly periods, or of monthly periods in the week set by every.",
+							Optional:    true,
+						},
+						"day": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntBetween(1, 31),
+							Description:  "Day of the month of monthly periods not using day_of_week.",
+							Optional:     true,
+						},
+						"months": &schema.Schema{
+							Type:        schema.TypeSet,
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(MAINTENANCE_MONTHS_ARR, false)},
+							Description: "Months of monthly periods.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+		},
+		CustomizeDiff: maintenanceCustomizeDiff,
+	}
+}
+
+// maintenanceCustomizeDiff check the combinations of time period settings the api accepts
+func maintenanceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	// values known only after apply, e.g. ids of hosts created in the same run,
+	// are checked by the server. Sets and lists with unknown elements only
+	// report it on their count
+	if d.NewValueKnown("hosts.#") && d.NewValueKnown("groups.#") &&
+		d.Get("hosts").(*schema.Set).Len() == 0 && d.Get("groups").(*schema.Set).Len() == 0 {
+		return fmt.Errorf("a maintenance needs hosts or groups")
+	}
+	if d.NewValueKnown("active_since") && d.NewValueKnown("active_till") {
+		if since, till := d.Get("active_since").(int), d.Get("active_till").(int); till <= since {
+			return fmt.Errorf("active_till has to be after active_since")
+		}
+	}
+	// without data collection there are no problems to scope
+	if d.NewValueKnown("tag.#") && d.NewValueKnown("collect_data") &&
+		len(d.Get("tag").([]interface{})) > 0 && !d.Get("collect_data").(bool) {
+		return fmt.Errorf("tag needs collect_data")
+	}
+
+	if !d.NewValueKnown("timeperiod.#") {
+		return nil
+	}
+	for i, raw := range d.Get("timeperiod").([]interface{}) {
+		period := raw.(map[string]interface{})
+		known := true
+		for k := range period {
+			known = known && d.NewValueKnown(fmt.Sprintf("timeperiod.%d.%s", i, k))
+		}
+		if !known {
+			continue
+		}
+		if err := validateMaintenanceTimePeriod(period); err != nil {
+			return fmt.Errorf("timeperiod %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// validateMaintenanceTimePeriod check a period sets the fields of its type and no others
+func validateMaintenanceTimePeriod(p map[string]interface{}) error {
+	kind := enumName(p["type"].(string), MAINTENANCE_PERIOD)
+	weekdays := p["day_of_week"].(*schema.Set).Len()
+	months := p["months"].(*schema.Set).Len()
+	day := p["day"].(int)
+
+	if kind != "one_time" && p["start_date"].(int) != 0 {
+		return fmt.Errorf("start_date is only used by one_time periods")
+	}
+	if kind != "monthly" && (months > 0 || day != 0) {
+		return fmt.Errorf("months and day are only used by monthly periods")
+	}
+
+	switch kind {
+	case "one_time":
+		if p["start_date"].(int) == 0 {
+			return fmt.Errorf("one_time periods need start_date")
+		}
+		if weekdays > 0 {
+			return fmt.Errorf("day_of_week is not used by one_time periods")
+		}
+	case "daily":
+		if weekdays > 0 {
+			return fmt.Errorf("day_of_week is not used by daily periods")
+		}
+	case "weekly":
+		if weekdays == 0 {
+			return fmt.Errorf("weekly periods need day_of_week")
+		}
+	case "monthly":
+		if months == 0 {
+			return fmt.Errorf("monthly periods need months")
+		}
+		if (day == 0) == (weekdays == 0) {
+			return fmt.Errorf("monthly periods need either day or day_of_week")
+		}
+		if weekdays > 0 && p["every"].(int) > 5 {
+			return fmt.Errorf("every is the week of the month for monthly periods, 1-5")
+		}
+	}
+	return nil
+}
+
+// buildMask or the bits of the names in a set
+func buildMask(s *schema.Set, bits map[string]int) int {
+	mask := 0
+	for _, name := range s.List() {
+		mask |= bits[name.(string)]
+	}
+	return mask
+}
+
+// flattenMask names of the bits set in a mask
+func flattenMask(mask int, bits map[string]int) *schema.Set {
+	names := []interface{}{}
+	for name, bit := range bits {
+		if mask&bit != 0 {
+			names = append(names, name)
+		}
+	}
+	return schema.NewSet(schema.HashString, names)
+}
+
+// buildMaintenanceTimePeriods api time periods from the terraform blocks, fields
+// the type does not use are left out
+func buildMaintenanceTimePeriods(d *schema.ResourceData) []map[string]interface{} {
+	periods := []map[string]interface{}{}
+	for _, raw := range d.Get("timeperiod").([]interface{}) {
+		p := raw.(map[string]interface{})
+		kind := enumName(p["type"].(string), MAINTENANCE_PERIOD)
+		period := map[string]interface{}{
+			"timeperiod_type": MAINTENANCE_PERIOD[kind],
+			"period":          p["period"].(int),
+		}
+
+		switch kind {
+		case "one_time":
+			period["start_date"] = p["start_date"].(int)
+		case "daily":
+			period["start_time"] = p["start_time"].(int)
+			period["every"] = p["every"].(int)
+		case "weekly":
+			period["start_time"] = p["start_time"].(int)
+			period["every"] = p["every"].(int)
+			period["dayofweek"] = buildMask(p["day_of_week"].(*schema.Set), MAINTENANCE_WEEKDAYS)
+		case "monthly":
+			period["start_time"] = p["start_time"].(int)
+			period["month"] = buildMask(p["months"].(*schema.Set), MAINTENANCE_MONTHS)
+			if day := p["day"].(int); day != 0 {
+				period["day"] = day
+			} else {
+				period["every"] = p["every"].(int)
+				period["dayofweek"] = buildMask(p["day_of_week"].(*schema.Set), MAINTENANCE_WEEKDAYS)
+			}
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// flattenMaintenanceTimePeriods terraform blocks of api time periods
+func flattenMaintenanceTimePeriods(periods []maintenanceTimePeriod) []interface{} {
+	list := []interface{}{}
+	for _, p := range periods {
+		kind := MAINTENANCE_PERIOD_REV[p.TimePeriodType]
+		block := map[string]interface{}{
+			"type":        kind,
+			"period":      p.Period,
+			"start_time":  p.StartTime,
+			"every":       p.Every,
+			"day_of_week": flattenMask(p.DayOfWeek, MAINTENANCE_WEEKDAYS),
+			"months":      schema.NewSet(schema.HashString, []interface{}{}),
+		}
+
+		// the api returns defaults for the fields a type does not use
+		switch kind {
+		case "one_time":
+			block["start_date"] = p.StartDate
+			block["start_time"] = 0
+		case "monthly":
+			block["months"] = flattenMask(p.Month, MAINTENANCE_MONTHS)
+			if p.DayOfWeek == 0 {
+				block["day"] = p.Day
+			}
+		}
+		if kind == "one_time" || (kind == "monthly" && p.DayOfWeek == 0) {
+			block["every"] = 1
+		}
+		list = append(list, block)
+	}
+	return list
+}
+
+// buildMaintenanceParams maintenance create/update params from terraform data
+func buildMaintenanceParams(d *schema.ResourceData, api *zabbix.API) zabbix.Params {
+	params := zabbix.Params{
+		"name":             d.Get("name").(string),
+		"description":      d.Get("description").(string),
+		"active_since":     d.Get("active_since").(int),
+		"active_till":      d.Get("active_till").(int),
+		"maintenance_type": 1,
+		"timeperiods":      buildMaintenanceTimePeriods(d),
+	}
+	if d.Get("collect_data").(bool) {
+		params["maintenance_type"] = 0
+
+		tags := []map[string]interface{}{}
+		for _, raw := range d.Get("tag").([]interface{}) {
+			t := raw.(map[string]interface{})
+			tags = append(tags, map[string]interface{}{
+				"tag":      t["tag"].(string),
+				"operator": enumValue(t["operator"].(string), PROBLEM_TAG_OPERATOR),
+				"value":    t["value"].(string),
+			})
+		}
+		params["tags_evaltype"] = enumValue(d.Get("tags_evaltype").(string), MAINTENANCE_TAGS_EVAL_TYPE)
+		params["tags"] = tags
+	}
+
+	hosts := d.Get("hosts").(*schema.Set)
+	groups := d.Get("groups").(*schema.Set)
+	// ids became objects in 6.0
+	if api.Config.Version >= 60000 {
+		params["hosts"] = idObjects("hostid", hosts)
+		params["groups"] = idObjects("groupid", groups)
+	} else {
+		params["hostids"] = hosts.List()
+		params["groupids"] = groups.List()
+	}
+	return params
+}
+
+// resourceMaintenanceCreate terraform resource create handler
+func resourceMaintenanceCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	response, err := api.CallWithError("maintenance.create", buildMaintenanceParams(d, api))
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	d.SetId(result["maintenanceids"].([]interface{})[0].(string))
+	log.Trace("created maintenance: %s", d.Id())
+
+	return readAfterWrite(d, m, resourceMaintenance().Schema, resourceMaintenanceRead)
+}
+
+// resourceMaintenanceRead terraform resource read handler
+func resourceMaintenanceRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of maintenance with id %s", d.Id())
+
+	params := zabbix.Params{
+		"output":            "extend",
+		"maintenanceids":    d.Id(),
+		"selectHosts":       []string{"hostid"},
+		"selectTimeperiods": "extend",
+		"selectTags":        "extend",
+	}
+	// host groups were renamed in 6.2
+	if api.Config.Version >= 60200 {
+		params["selectHostGroups"] = []string{"groupid"}
+	} else {
+		params["selectGroups"] = []string{"groupid"}
+	}
+
+	var maintenances []maintenanceObject
+	if err := api.CallWithErrorParse("maintenance.get", params, &maintenances); err != nil {
+		return err
+	}
+
+	if len(maintenances) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := maintenances[0]
+
+	log.Debug("Got maintenance: %s", t.Maintenance.MaintenanceID)
+
+	hosts := schema.NewSet(schema.HashString, []interface{}{})
+	for _, v := range t.Hosts {
+		hosts.Add(v.HostID)
+	}
+
+	groups := t.Groups
+	if api.Config.Version >= 60200 {
+		groups = t.HostGroups
+	}
+
+	d.Set("name", t.Name)
+	d.Set("description", t.Description)
+	d.Set("active_since", t.ActiveSince)
+	d.Set("active_till", t.ActiveTill)
+	d.Set("collect_data", t.MaintenanceType == 0)
+	d.Set("hosts", hosts)
+	d.Set("groups", flattenHostGroupIds(groups))
+	d.Set("timeperiod", flattenMaintenanceTimePeriods(t.TimePeriods))
+
+	tags := []interface{}{}
+	for _, tag := range t.Tags {
+		tags = append(tags, map[string]interface{}{
+			"tag":      tag.Tag,
+			"operator": PROBLEM_TAG_OPERATOR_REV[tag.Operator],
+			"value":    tag.Value,
+		})
+	}
+	setEnum(d, "tags_evaltype", t.TagsEvalType, MAINTENANCE_TAGS_EVAL_TYPE, MAINTENANCE_TAGS_EVAL_TYPE_REV)
+	d.Set("tag", tags)
+
+	return nil
+}
+
+// resourceMaintenanceUpdate terraform resource update handler
+func resourceMaintenanceUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := buildMaintenanceParams(d, api)
+	params["maintenanceid"] = d.Id()
+
+	if _, err := api.CallWithError("maintenance.update", params); err != nil {
+		return err
+	}
+
+	return readAfterWrite(d, m, resourceMaintenance().Schema, resourceMaintenanceRead)
+}
+
+// resourceMaintenanceDelete terraform resource delete handler
+func resourceMaintenanceDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("maintenance.delete", []string{d.Id()})
+	return err
+}
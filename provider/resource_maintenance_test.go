@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitResourceMaintenanceUnknownValues(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	groupID := server.Seed("hostgroup", map[string]interface{}{"name": "tf-unit-group"})
+
+	// hosts, active_since and start_date are only known once the host exists,
+	// the plan has to pass the checks anyway
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             testUnitResourceMaintenanceUnknownValues(server.APIURL(), groupID),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testUnitResourceMaintenanceUnknownValues(url, groupID string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_host" "test" {
+	host   = "tf-unit-host"
+	groups = [%q]
+	interface {
+		ip = "127.0.0.1"
+	}
+}
+resource "zabbix_maintenance" "test" {
+	name         = "tf-unit-maintenance"
+	hosts        = [zabbix_host.test.id]
+	active_since = zabbix_host.test.id
+	active_till  = 2000000000
+	timeperiod {
+		start_date = zabbix_host.test.id
+	}
+}
+`, testUnitProviderConfig(url), groupID)
+}
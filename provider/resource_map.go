@@ -0,0 +1,535 @@
+package provider
+
+import (
+	"context"
+
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// MAP_ELEMENT map element types
+var MAP_ELEMENT = map[string]int{
+	"host":       0,
+	"map":        1,
+	"trigger":    2,
+	"host_group": 3,
+	"image":      4,
+}
+var MAP_ELEMENT_REV = map[int]string{}
+
+// MAP_ELEMENT_ID_FIELDS id field of the objects an element of each type shows
+var MAP_ELEMENT_ID_FIELDS = map[int]string{
+	0: "hostid",
+	1: "sysmapid",
+	2: "triggerid",
+	3: "groupid",
+}
+
+// MAP_LINK_DRAW line styles of map links
+var MAP_LINK_DRAW = map[string]int{
+	"line":   0,
+	"bold":   2,
+	"dot":    3,
+	"dashed": 4,
+}
+var MAP_LINK_DRAW_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range MAP_ELEMENT {
+		MAP_ELEMENT_REV[v] = k
+	}
+	for k, v := range MAP_LINK_DRAW {
+		MAP_LINK_DRAW_REV[v] = k
+	}
+	return false
+}()
+
+// mapObject network map with its elements and links
+type mapObject struct {
+	SysmapID  string       `json:"sysmapid"`
+	Name      string       `json:"name"`
+	Width     int          `json:"width,string"`
+	Height    int          `json:"height,string"`
+	Selements []mapElement `json:"selements"`
+	Links     []struct {
+		SelementID1  string `json:"selementid1"`
+		SelementID2  string `json:"selementid2"`
+		DrawType     int    `json:"drawtype,string"`
+		Color        string `json:"color"`
+		Label        string `json:"label"`
+		LinkTriggers []struct {
+			TriggerID string `json:"triggerid"`
+			DrawType  int    `json:"drawtype,string"`
+			Color     string `json:"color"`
+		} `json:"linktriggers"`
+	} `json:"links"`
+}
+
+// mapElement element of a network map
+type mapElement struct {
+	SelementID        string              `json:"selementid"`
+	ElementType       int                 `json:"elementtype,string"`
+	Elements          []map[string]string `json:"elements"`
+	IconIDOff         string              `json:"iconid_off"`
+	IconIDOn          string              `json:"iconid_on"`
+	IconIDDisabled    string              `json:"iconid_disabled"`
+	IconIDMaintenance string              `json:"iconid_maintenance"`
+	Label             string              `json:"label"`
+	X                 int                 `json:"x,string"`
+	Y                 int                 `json:"y,string"`
+}
+
+// resourceMap terraform resource handler
+func resourceMap() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceMapCreate),
+		ReadContext:   contextCRUD(resourceMapRead),
+		UpdateContext: contextCRUD(resourceMapUpdate),
+		DeleteContext: contextCRUD(resourceMapDelete),
+		Importer:      importByName(lookupByField("map.get", "sysmapid", "name")),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the map.",
+				Required:     true,
+			},
+			"width": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(1, 65535),
+				Description:  "Width of the map in pixels.",
+				Optional:     true,
+				Default:      800,
+			},
+			"height": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(1, 65535),
+				Description:  "Height of the map in pixels.",
+				Optional:     true,
+				Default:      600,
+			},
+			"element": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Elements of the map, links refer to them by position.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"selementid": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "ID of the map element.",
+							Computed:    true,
+						},
+						"type": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(MAP_ELEMENT),
+							DiffSuppressFunc: suppressEnumDiff(MAP_ELEMENT),
+							Description:      "Element type. Possible values: host (0), map (1), trigger (2), host_group (3), image (4).",
+							Required:         true,
+						},
+						"element_ids": &schema.Schema{
+							Type:        schema.TypeList,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Host, map or host group shown, or the triggers of trigger elements. Images show none.",
+							Optional:    true,
+						},
+						"x": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "X coordinate in pixels.",
+							Optional:     true,
+							Default:      0,
+						},
+						"y": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "Y coordinate in pixels.",
+							Optional:     true,
+							Default:      0,
+						},
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Label of the element, may use macros.",
+							Optional:    true,
+						},
+						"icon_id": &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "Image ID of the icon shown by default.",
+							Required:     true,
+						},
+						"icon_id_on": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Image ID of the icon shown on problems, 0 for icon_id.",
+							Optional:    true,
+							Default:     "0",
+						},
+						"icon_id_disabled": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Image ID of the icon shown when disabled, 0 for icon_id.",
+							Optional:    true,
+							Default:     "0",
+						},
+						"icon_id_maintenance": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Image ID of the icon shown in maintenance, 0 for icon_id.",
+							Optional:    true,
+							Default:     "0",
+						},
+					},
+				},
+			},
+			"link": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Links between elements.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"from": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "Position of the first element in the element list.",
+							Required:     true,
+						},
+						"to": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "Position of the second element in the element list.",
+							Required:     true,
+						},
+						"draw_type": mapLinkDrawSchema(),
+						"color":     mapColorSchema("00CC00"),
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Label of the link, may use macros.",
+							Optional:    true,
+						},
+						"trigger": &schema.Schema{
+							Type:        schema.TypeList,
+							Description: "Triggers changing the style of the link while in problem state.",
+							Optional:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"trigger_id": &schema.Schema{
+										Type:         schema.TypeString,
+										ValidateFunc: validation.StringIsNotWhiteSpace,
+										Description:  "Trigger ID.",
+										Required:     true,
+									},
+									"draw_type": mapLinkDrawSchema(),
+									"color":     mapColorSchema("DD0000"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		CustomizeDiff: mapCustomizeDiff,
+	}
+}
+
+// mapLinkDrawSchema line style of a link
+func mapLinkDrawSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		ValidateFunc:     validateEnum(MAP_LINK_DRAW),
+		DiffSuppressFunc: suppressEnumDiff(MAP_LINK_DRAW),
+		Description:      "Line style. Possible values: line (0, default), bold (2), dot (3), dashed (4).",
+		Optional:         true,
+		Default:          "line",
+	}
+}
+
+// mapColorSchema hex color of a link
+func mapColorSchema(def string) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		ValidateFunc: validation.StringMatch(hexColor, "has to be a hex color like 00CC00"),
+		Description:  "Line color as hex RGB.",
+		Optional:     true,
+		Default:      def,
+	}
+}
+
+// mapCustomizeDiff check elements show the objects of their type and links join existing elements
+func mapCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	elements := d.Get("element").([]interface{})
+	for i, raw := range elements {
+		e := raw.(map[string]interface{})
+		ids := len(e["element_ids"].([]interface{}))
+		switch kind := enumName(e["type"].(string), MAP_ELEMENT); kind {
+		case "image":
+			if ids > 0 {
+				return fmt.Errorf("element %d: image elements show no element_ids", i)
+			}
+		case "trigger":
+			if ids == 0 {
+				return fmt.Errorf("element %d: trigger elements need element_ids", i)
+			}
+		default:
+			if ids != 1 {
+				return fmt.Errorf("element %d: %s elements need exactly one element_ids entry", i, kind)
+			}
+		}
+	}
+
+	for i, raw := range d.Get("link").([]interface{}) {
+		l := raw.(map[string]interface{})
+		from, to := l["from"].(int), l["to"].(int)
+		if from >= len(elements) || to >= len(elements) {
+			return fmt.Errorf("link %d: the map has %d elements", i, len(elements))
+		}
+		if from == to {
+			return fmt.Errorf("link %d: links an element to itself", i)
+		}
+	}
+	return nil
+}
+
+// buildMapElements api elements from the terraform blocks, keeping the ids of
+// the elements at the same positions so they are updated in place
+func buildMapElements(d *schema.ResourceData) []map[string]interface{} {
+	elements := []map[string]interface{}{}
+	for _, raw := range d.Get("element").([]interface{}) {
+		e := raw.(map[string]interface{})
+		elementType := enumValue(e["type"].(string), MAP_ELEMENT)
+
+		objects := []map[string]string{}
+		for _, id := range e["element_ids"].([]interface{}) {
+			objects = append(objects, map[string]string{MAP_ELEMENT_ID_FIELDS[elementType]: id.(string)})
+		}
+
+		element := map[string]interface{}{
+			"elementtype":        elementType,
+			"elements":           objects,
+			"iconid_off":         e["icon_id"].(string),
+			"iconid_on":          e["icon_id_on"].(string),
+			"iconid_disabled":    e["icon_id_disabled"].(string),
+			"iconid_maintenance": e["icon_id_maintenance"].(string),
+			"label":              e["label"].(string),
+			"x":                  e["x"].(int),
+			"y":                  e["y"].(int),
+		}
+		if id := e["selementid"].(string); id != "" {
+			element["selementid"] = id
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// buildMapLinks api links from the terraform blocks, ids are the element ids
+// of the saved map in element order
+func buildMapLinks(d *schema.ResourceData, ids []string) []map[string]interface{} {
+	links := []map[string]interface{}{}
+	for _, raw := range d.Get("link").([]interface{}) {
+		l := raw.(map[string]interface{})
+
+		triggers := []map[string]interface{}{}
+		for _, rawTrigger := range l["trigger"].([]interface{}) {
+			t := rawTrigger.(map[string]interface{})
+			triggers = append(triggers, map[string]interface{}{
+				"triggerid": t["trigger_id"].(string),
+				"drawtype":  enumValue(t["draw_type"].(string), MAP_LINK_DRAW),
+				"color":     t["color"].(string),
+			})
+		}
+
+		links = append(links, map[string]interface{}{
+			"selementid1":  ids[l["from"].(int)],
+			"selementid2":  ids[l["to"].(int)],
+			"drawtype":     enumValue(l["draw_type"].(string), MAP_LINK_DRAW),
+			"color":        l["color"].(string),
+			"label":        l["label"].(string),
+			"linktriggers": triggers,
+		})
+	}
+	return links
+}
+
+// sortedMapElements elements of a map in the order they were created
+func sortedMapElements(elements []mapElement) []mapElement {
+	sort.Slice(elements, func(i, j int) bool {
+		a, _ := strconv.ParseInt(elements[i].SelementID, 10, 64)
+		b, _ := strconv.ParseInt(elements[j].SelementID, 10, 64)
+		return a < b
+	})
+	return elements
+}
+
+// saveMapLinks set the links of a saved map, done apart from the elements as
+// links need the ids of new elements
+func saveMapLinks(d *schema.ResourceData, api *zabbix.API) error {
+	var maps []mapObject
+	err := api.CallWithErrorParse("map.get", zabbix.Params{
+		"output":          []string{"sysmapid"},
+		"sysmapids":       d.Id(),
+		"selectSelements": []string{"selementid"},
+	}, &maps)
+	if err != nil {
+		return err
+	}
+	if len(maps) < 1 {
+		return fmt.Errorf("map %s not found after saving it", d.Id())
+	}
+
+	ids := []string{}
+	for _, e := range sortedMapElements(maps[0].Selements) {
+		ids = append(ids, e.SelementID)
+	}
+	if len(ids) != d.Get("element.#").(int) {
+		return fmt.Errorf("map %s has %d elements after saving %d", d.Id(), len(ids), d.Get("element.#").(int))
+	}
+
+	_, err = api.CallWithError("map.update", zabbix.Params{
+		"sysmapid": d.Id(),
+		"links":    buildMapLinks(d, ids),
+	})
+	return err
+}
+
+// resourceMapCreate terraform resource create handler
+func resourceMapCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	response, err := api.CallWithError("map.create", zabbix.Params{
+		"name":      d.Get("name").(string),
+		"width":     d.Get("width").(int),
+		"height":    d.Get("height").(int),
+		"selements": buildMapElements(d),
+	})
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	d.SetId(result["sysmapids"].([]interface{})[0].(string))
+	log.Trace("created map: %s", d.Id())
+
+	if d.Get("link.#").(int) > 0 {
+		if err := saveMapLinks(d, api); err != nil {
+			return err
+		}
+	}
+
+	return resourceMapRead(d, m)
+}
+
+// resourceMapRead terraform resource read handler
+func resourceMapRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of map with id %s", d.Id())
+
+	var maps []mapObject
+	err := api.CallWithErrorParse("map.get", zabbix.Params{
+		"output":          "extend",
+		"sysmapids":       d.Id(),
+		"selectSelements": "extend",
+		"selectLinks":     "extend",
+	}, &maps)
+	if err != nil {
+		return err
+	}
+
+	if len(maps) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := maps[0]
+
+	log.Debug("Got map: %s %s", t.SysmapID, t.Name)
+
+	positions := map[string]int{}
+	elements := []interface{}{}
+	for i, e := range sortedMapElements(t.Selements) {
+		positions[e.SelementID] = i
+
+		ids := []interface{}{}
+		for _, object := range e.Elements {
+			ids = append(ids, object[MAP_ELEMENT_ID_FIELDS[e.ElementType]])
+		}
+		elements = append(elements, map[string]interface{}{
+			"selementid":          e.SelementID,
+			"type":                MAP_ELEMENT_REV[e.ElementType],
+			"element_ids":         ids,
+			"x":                   e.X,
+			"y":                   e.Y,
+			"label":               e.Label,
+			"icon_id":             e.IconIDOff,
+			"icon_id_on":          e.IconIDOn,
+			"icon_id_disabled":    e.IconIDDisabled,
+			"icon_id_maintenance": e.IconIDMaintenance,
+		})
+	}
+
+	links := []interface{}{}
+	for _, l := range t.Links {
+		triggers := []interface{}{}
+		for _, lt := range l.LinkTriggers {
+			triggers = append(triggers, map[string]interface{}{
+				"trigger_id": lt.TriggerID,
+				"draw_type":  MAP_LINK_DRAW_REV[lt.DrawType],
+				"color":      lt.Color,
+			})
+		}
+		links = append(links, map[string]interface{}{
+			"from":      positions[l.SelementID1],
+			"to":        positions[l.SelementID2],
+			"draw_type": MAP_LINK_DRAW_REV[l.DrawType],
+			"color":     l.Color,
+			"label":     l.Label,
+			"trigger":   triggers,
+		})
+	}
+
+	d.Set("name", t.Name)
+	d.Set("width", t.Width)
+	d.Set("height", t.Height)
+	d.Set("element", elements)
+	d.Set("link", links)
+
+	return nil
+}
+
+// resourceMapUpdate terraform resource update handler
+func resourceMapUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"sysmapid": d.Id(),
+		"name":     d.Get("name").(string),
+		"width":    d.Get("width").(int),
+		"height":   d.Get("height").(int),
+	}
+	// links of removed elements go with them, they are all set again below
+	if d.HasChange("element") {
+		params["selements"] = buildMapElements(d)
+		params["links"] = []interface{}{}
+	}
+	if _, err := api.CallWithError("map.update", params); err != nil {
+		return err
+	}
+
+	if d.HasChanges("element", "link") {
+		if err := saveMapLinks(d, api); err != nil {
+			return err
+		}
+	}
+
+	return resourceMapRead(d, m)
+}
+
+// resourceMapDelete terraform resource delete handler
+func resourceMapDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("map.delete", []string{d.Id()})
+	return err
+}
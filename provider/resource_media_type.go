@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// MEDIA_TYPE media type transports
+var MEDIA_TYPE = map[string]int{
+	"email":   0,
+	"script":  1,
+	"sms":     2,
+	"webhook": 4,
+}
+var MEDIA_TYPE_REV = map[int]string{}
+
+// MEDIA_TYPE_MANAGED transports the resource can configure, the others need
+// settings it does not have yet
+var MEDIA_TYPE_MANAGED = map[string]int{
+	"webhook": 4,
+}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range MEDIA_TYPE {
+		MEDIA_TYPE_REV[v] = k
+	}
+	return false
+}()
+
+// MEDIA_TYPE_OUTPUT fields read by the media type resource
+var MEDIA_TYPE_OUTPUT = []string{
+	"mediatypeid", "name", "type", "status", "description", "script", "timeout",
+	"process_tags", "show_event_menu", "event_menu_url", "event_menu_name",
+	"parameters", "maxsessions", "maxattempts", "attempt_interval",
+}
+
+// mediaTypeObject media type including the webhook settings
+type mediaTypeObject struct {
+	MediaType
+	Description     string               `json:"description"`
+	Script          string               `json:"script"`
+	Timeout         string               `json:"timeout"`
+	ProcessTags     int                  `json:"process_tags,string"`
+	ShowEventMenu   int                  `json:"show_event_menu,string"`
+	EventMenuURL    string               `json:"event_menu_url"`
+	EventMenuName   string               `json:"event_menu_name"`
+	Parameters      []mediaTypeParameter `json:"parameters"`
+	MaxSessions     int                  `json:"maxsessions,string"`
+	MaxAttempts     int                  `json:"maxattempts,string"`
+	AttemptInterval string               `json:"attempt_interval"`
+}
+
+// mediaTypeParameter webhook parameter
+type mediaTypeParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// resourceMediaType terraform resource handler
+func resourceMediaType() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceMediaTypeCreate),
+		ReadContext:   contextCRUD(resourceMediaTypeRead),
+		UpdateContext: contextCRUD(resourceMediaTypeUpdate),
+		DeleteContext: contextCRUD(resourceMediaTypeDelete),
+		Importer:      importByName(lookupByField("mediatype.get", "mediatypeid", "name")),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the media type.",
+				Required:     true,
+			},
+			"type": &schema.Schema{
+				Type:             schema.TypeString,
+				ValidateFunc:     validateEnum(MEDIA_TYPE_MANAGED),
+				DiffSuppressFunc: suppressEnumDiff(MEDIA_TYPE_MANAGED),
+				Description:      "Transport used by the media type. Possible values: webhook (4, default).",
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "webhook",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the media type is enabled.",
+				Optional:    true,
+				Default:     true,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Description of the media type.",
+				Optional:    true,
+			},
+			"script": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "JavaScript body of the webhook.",
+				Required:     true,
+			},
+			"parameters": &schema.Schema{
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Webhook parameters passed to the script, by name. Values may use macros, e.g. {ALERT.MESSAGE}.",
+				Optional:    true,
+			},
+			"timeout": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateTimeSuffix,
+				Description:  "Timeout of the webhook script, 1-60s.",
+				Optional:     true,
+				Default:      "30s",
+			},
+			"process_tags": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Process the returned JSON property tags as event tags.",
+				Optional:    true,
+				Default:     false,
+			},
+			"show_event_menu": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Add an entry to the event menu, linking to event_menu_url.",
+				Optional:    true,
+				Default:     false,
+			},
+			"event_menu_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "URL of the event menu entry, may use {EVENT.TAGS.<name>} macros.",
+				Optional:    true,
+			},
+			"event_menu_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Name of the event menu entry.",
+				Optional:    true,
+			},
+			"max_sessions": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(0, 100),
+				Description:  "Number of alerts sent in parallel, 0 for unlimited.",
+				Optional:     true,
+				Default:      1,
+			},
+			"max_attempts": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(1, 100),
+				Description:  "Number of attempts to send an alert.",
+				Optional:     true,
+				Default:      3,
+			},
+			"attempt_interval": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateTimeSuffix,
+				Description:  "Interval between attempts, 0-1h.",
+				Optional:     true,
+				Default:      "10s",
+			},
+		},
+		CustomizeDiff: mediaTypeCustomizeDiff,
+	}
+}
+
+// mediaTypeCustomizeDiff the event menu needs both its url and name
+func mediaTypeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.Get("show_event_menu").(bool) {
+		return nil
+	}
+	if d.Get("event_menu_url").(string) == "" || d.Get("event_menu_name").(string) == "" {
+		return errors.New("show_event_menu requires event_menu_url and event_menu_name")
+	}
+	return nil
+}
+
+// buildMediaTypeParams media type create/update params from terraform data
+func buildMediaTypeParams(d *schema.ResourceData) zabbix.Params {
+	parameters := []mediaTypeParameter{}
+	for name, value := range d.Get("parameters").(map[string]interface{}) {
+		parameters = append(parameters, mediaTypeParameter{Name: name, Value: value.(string)})
+	}
+
+	params := zabbix.Params{
+		"name":             d.Get("name").(string),
+		"type":             enumValue(d.Get("type").(string), MEDIA_TYPE),
+		"status":           1,
+		"description":      d.Get("description").(string),
+		"script":           d.Get("script").(string),
+		"parameters":       parameters,
+		"timeout":          d.Get("timeout").(string),
+		"process_tags":     0,
+		"show_event_menu":  0,
+		"event_menu_url":   d.Get("event_menu_url").(string),
+		"event_menu_name":  d.Get("event_menu_name").(string),
+		"maxsessions":      d.Get("max_sessions").(int),
+		"maxattempts":      d.Get("max_attempts").(int),
+		"attempt_interval": d.Get("attempt_interval").(string),
+	}
+
+	if d.Get("enabled").(bool) {
+		params["status"] = 0
+	}
+	if d.Get("process_tags").(bool) {
+		params["process_tags"] = 1
+	}
+	if d.Get("show_event_menu").(bool) {
+		params["show_event_menu"] = 1
+	}
+
+	return params
+}
+
+// resourceMediaTypeCreate terraform resource create handler
+func resourceMediaTypeCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	if err := requireVersion(api, 40400, "webhook media types"); err != nil {
+		return err
+	}
+
+	response, err := api.CallWithError("mediatype.create", buildMediaTypeParams(d))
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	d.SetId(result["mediatypeids"].([]interface{})[0].(string))
+	log.Trace("created media type: %s", d.Id())
+
+	return readAfterWrite(d, m, resourceMediaType().Schema, resourceMediaTypeRead)
+}
+
+// resourceMediaTypeRead terraform resource read handler
+func resourceMediaTypeRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of media type with id %s", d.Id())
+
+	var mediaTypes []mediaTypeObject
+	err := api.CallWithErrorParse("mediatype.get", zabbix.Params{
+		"output":       MEDIA_TYPE_OUTPUT,
+		"mediatypeids": d.Id(),
+	}, &mediaTypes)
+	if err != nil {
+		return err
+	}
+
+	if len(mediaTypes) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := mediaTypes[0]
+
+	log.Debug("Got media type: %s", t.MediaType.MediaTypeID)
+
+	parameters := map[string]interface{}{}
+	for _, p := range t.Parameters {
+		parameters[p.Name] = p.Value
+	}
+
+	d.Set("name", t.Name)
+	setEnum(d, "type", t.Type, MEDIA_TYPE, MEDIA_TYPE_REV)
+	d.Set("enabled", t.Status == 0)
+	d.Set("description", t.Description)
+	d.Set("script", t.Script)
+	d.Set("parameters", parameters)
+	d.Set("timeout", t.Timeout)
+	d.Set("process_tags", t.ProcessTags == 1)
+	d.Set("show_event_menu", t.ShowEventMenu == 1)
+	d.Set("event_menu_url", t.EventMenuURL)
+	d.Set("event_menu_name", t.EventMenuName)
+	d.Set("max_sessions", t.MaxSessions)
+	d.Set("max_attempts", t.MaxAttempts)
+	d.Set("attempt_interval", t.AttemptInterval)
+
+	return nil
+}
+
+// resourceMediaTypeUpdate terraform resource update handler
+func resourceMediaTypeUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := buildMediaTypeParams(d)
+	params["mediatypeid"] = d.Id()
+	// the transport can not change
+	delete(params, "type")
+
+	if _, err := api.CallWithError("mediatype.update", params); err != nil {
+		return err
+	}
+
+	return readAfterWrite(d, m, resourceMediaType().Schema, resourceMediaTypeRead)
+}
+
+// resourceMediaTypeDelete terraform resource delete handler
+func resourceMediaTypeDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("mediatype.delete", []string{d.Id()})
+	return err
+}
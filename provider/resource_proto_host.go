@@ -0,0 +1,432 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// protoHost host prototype, the api library has no support for them
+type protoHost struct {
+	HostID           string                    `json:"hostid,omitempty"`
+	RuleID           string                    `json:"ruleid,omitempty"`
+	Host             string                    `json:"host"`
+	Name             string                    `json:"name"`
+	Status           int                       `json:"status,string"`
+	Discover         int                       `json:"discover,string"`
+	InventoryMode    zabbix.InventoryMode      `json:"inventory_mode,string"`
+	CustomInterfaces int                       `json:"custom_interfaces,string"`
+	GroupLinks       zabbix.HostGroupIDs       `json:"groupLinks"`
+	GroupPrototypes  []protoHostGroupPrototype `json:"groupPrototypes"`
+	Templates        zabbix.TemplateIDs        `json:"templates"`
+	Interfaces       zabbix.HostInterfaces     `json:"interfaces"`
+	DiscoveryRule    *protoHostRule            `json:"discoveryRule,omitempty"`
+}
+
+type protoHostGroupPrototype struct {
+	Name string `json:"name"`
+}
+
+type protoHostRule struct {
+	ItemID string `json:"itemid"`
+}
+
+// protoHostFieldVersions api version the optional host prototype fields were introduced in
+var protoHostFieldVersions = []struct {
+	Key   string
+	Since int
+}{
+	{"discover", 40400},
+	{"inventory_mode", 40400},
+	{"custom_interfaces", 50200},
+	{"interfaces", 50200},
+}
+
+var lldMacroName = validation.StringMatch(regexp.MustCompile("\\{#[A-Z0-9_.]+\\}"), "must contain a LLD macro")
+
+// resourceProtoHost terraform host prototype resource entrypoint
+func resourceProtoHost() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceProtoHostCreate),
+		ReadContext:   contextCRUD(resourceProtoHostRead),
+		UpdateContext: contextCRUD(resourceProtoHostUpdate),
+		DeleteContext: contextCRUD(resourceProtoHostDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"ruleid": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "LLD Discovery rule ID to attach the host prototype to",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+			},
+			"host": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Technical name of the discovered hosts",
+				ValidateFunc: lldMacroName,
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Visible name of the discovered hosts, defaults to the value of \"host\"",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Create the discovered hosts enabled",
+			},
+			"discover": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Discover hosts from this prototype",
+			},
+			"groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Required:    true,
+				Description: "Hostgroup IDs the discovered hosts are added to",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be a numeric string"),
+				},
+			},
+			"group_prototypes": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Names of the hostgroups created for the discovered hosts",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: lldMacroName,
+				},
+			},
+			"templates": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Template IDs to attach to the discovered hosts",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be a numeric string"),
+				},
+			},
+			"inventory_mode": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "disabled",
+				Description:  "Inventory Mode, one of: " + strings.Join(HINV_LOOKUP_ARR, ", "),
+				ValidateFunc: validation.StringInSlice(HINV_LOOKUP_ARR, false),
+			},
+			"interface": protoHostInterfaceSchema(),
+		},
+	}
+}
+
+// protoHostInterfaceSchema custom interfaces of the discovered hosts, the host
+// interface schema with LLD macros allowed in the port
+func protoHostInterfaceSchema() *schema.Schema {
+	elem := map[string]*schema.Schema{}
+	for k, v := range hostSchemaBase["interface"].Elem.(*schema.Resource).Schema {
+		if k != "id" {
+			elem[k] = v
+		}
+	}
+	elem["port"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		Description:  "Destination Port, or a macro",
+		ValidateFunc: validation.StringMatch(regexp.MustCompile("^([0-9]+|\\{.+\\})$"), "must be a port number or a macro"),
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Custom interfaces of the discovered hosts, the ones of the discovering host are used if none are set (zabbix >= 5.2)",
+		Elem: &schema.Resource{
+			Schema: elem,
+		},
+	}
+}
+
+// buildProtoHostObject create host prototype struct
+func buildProtoHostObject(d *schema.ResourceData, api *zabbix.API) (*protoHost, error) {
+	item := protoHost{
+		Host:            d.Get("host").(string),
+		Name:            d.Get("name").(string),
+		InventoryMode:   HINV_LOOKUP[d.Get("inventory_mode").(string)],
+		GroupLinks:      buildHostGroupIds(d.Get("groups").(*schema.Set)),
+		GroupPrototypes: []protoHostGroupPrototype{},
+		Templates:       buildTemplateIds(d.Get("templates").(*schema.Set)),
+	}
+
+	if !d.Get("enabled").(bool) {
+		item.Status = 1
+	}
+	if !d.Get("discover").(bool) {
+		item.Discover = 1
+	}
+
+	for _, name := range d.Get("group_prototypes").(*schema.Set).List() {
+		item.GroupPrototypes = append(item.GroupPrototypes, protoHostGroupPrototype{Name: name.(string)})
+	}
+
+	interfaces, err := protoHostGenerateInterfaces(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+	item.Interfaces = interfaces
+	if len(interfaces) > 0 {
+		item.CustomInterfaces = 1
+	}
+
+	return &item, nil
+}
+
+// protoHostGenerateInterfaces generate interface prototype object array
+func protoHostGenerateInterfaces(d *schema.ResourceData, api *zabbix.API) (interfaces zabbix.HostInterfaces, err error) {
+	interfaceCount := d.Get("interface.#").(int)
+	interfaces = make(zabbix.HostInterfaces, interfaceCount)
+
+	for i := 0; i < interfaceCount; i++ {
+		prefix := fmt.Sprintf("interface.%d.", i)
+
+		interfaces[i] = zabbix.HostInterface{
+			IP:    d.Get(prefix + "ip").(string),
+			DNS:   d.Get(prefix + "dns").(string),
+			Port:  d.Get(prefix + "port").(string),
+			Main:  "0",
+			Type:  HOST_IFACE_TYPES[d.Get(prefix+"type").(string)],
+			UseIP: "0",
+		}
+		if interfaces[i].IP == "" && interfaces[i].DNS == "" {
+			err = errors.New("interface requires either an IP or DNS entry")
+			return
+		}
+		if interfaces[i].IP != "" {
+			interfaces[i].UseIP = "1"
+		}
+		if d.Get(prefix + "main").(bool) {
+			interfaces[i].Main = "1"
+		}
+
+		// if no port set, use the default for the type
+		if interfaces[i].Port == "" {
+			interfaces[i].Port = strconv.Itoa(HOST_IFACE_PORTS[d.Get(prefix+"type").(string)])
+		}
+
+		var details *zabbix.HostInterfaceDetail
+		if details, err = hostInterfaceDetails(d, api, prefix); err != nil {
+			return
+		}
+		if details != nil {
+			raw, _ := json.Marshal(details)
+			interfaces[i].RawDetails = json.RawMessage(raw)
+		}
+	}
+
+	return
+}
+
+// protoHostParams request params of a host prototype, leaving out the fields the
+// connected server does not know yet
+func protoHostParams(api *zabbix.API, item *protoHost) (map[string]interface{}, error) {
+	params, err := versionedParams(api, item, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range protoHostFieldVersions {
+		if api.Config.Version >= f.Since {
+			continue
+		}
+		switch f.Key {
+		case "discover":
+			if item.Discover != 0 {
+				return nil, requireVersion(api, f.Since, "discover = false")
+			}
+		case "inventory_mode":
+			// older servers take it inside the inventory object
+			params["inventory"] = map[string]interface{}{"inventory_mode": params[f.Key]}
+		case "interfaces":
+			if len(item.Interfaces) > 0 {
+				return nil, requireVersion(api, f.Since, "interface")
+			}
+		}
+		delete(params, f.Key)
+	}
+
+	return params, nil
+}
+
+// resourceProtoHostCreate terraform create resource handler
+func resourceProtoHostCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	item, err := buildProtoHostObject(d, api)
+
+	if err != nil {
+		return err
+	}
+	item.RuleID = d.Get("ruleid").(string)
+
+	params, err := protoHostParams(api, item)
+
+	if err != nil {
+		return err
+	}
+
+	response, err := api.CallWithError("hostprototype.create", params)
+
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	item.HostID = result["hostids"].([]interface{})[0].(string)
+
+	log.Trace("created host prototype: %s", item.HostID)
+
+	d.SetId(item.HostID)
+
+	return resourceProtoHostRead(d, m)
+}
+
+// resourceProtoHostRead terraform read resource handler
+func resourceProtoHostRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of host prototype with id %s", d.Id())
+
+	params := zabbix.Params{
+		"output":                "extend",
+		"hostids":               d.Id(),
+		"selectGroupLinks":      []string{"groupid"},
+		"selectGroupPrototypes": []string{"name"},
+		"selectTemplates":       []string{"templateid"},
+		"selectDiscoveryRule":   []string{"itemid"},
+	}
+	if api.Config.Version >= 50200 {
+		params["selectInterfaces"] = "extend"
+	}
+
+	var items []protoHost
+	err := api.CallWithErrorParse("hostprototype.get", params, &items)
+
+	if err != nil {
+		return err
+	}
+
+	if len(items) < 1 {
+		d.SetId("")
+		return nil
+	}
+	if len(items) > 1 {
+		return errors.New("multiple host prototypes found")
+	}
+	item := items[0]
+
+	log.Debug("Got host prototype: %s", item.HostID)
+
+	d.SetId(item.HostID)
+	if item.DiscoveryRule != nil {
+		d.Set("ruleid", item.DiscoveryRule.ItemID)
+	}
+	d.Set("host", item.Host)
+	d.Set("name", item.Name)
+	d.Set("enabled", item.Status == 0)
+	if api.Config.Version >= 40400 {
+		d.Set("discover", item.Discover == 0)
+		d.Set("inventory_mode", HINV_LOOKUP_REV[item.InventoryMode])
+	}
+	d.Set("groups", flattenHostGroupIds(item.GroupLinks))
+	d.Set("templates", flattenTemplateIds(item.Templates))
+
+	names := make([]interface{}, len(item.GroupPrototypes))
+	for i, group := range item.GroupPrototypes {
+		names[i] = group.Name
+	}
+	d.Set("group_prototypes", names)
+
+	if api.Config.Version >= 50200 {
+		d.Set("interface", flattenProtoHostInterfaces(item, d, api))
+	}
+
+	return nil
+}
+
+// flattenProtoHostInterfaces convert the custom interfaces to terraform input
+func flattenProtoHostInterfaces(item protoHost, d *schema.ResourceData, api *zabbix.API) []interface{} {
+	if item.CustomInterfaces == 0 {
+		return []interface{}{}
+	}
+
+	val := make([]interface{}, len(item.Interfaces))
+	for i, in := range item.Interfaces {
+		params := map[string]interface{}{
+			"ip":   in.IP,
+			"dns":  in.DNS,
+			"main": in.Main == "1",
+			"port": in.Port,
+			"type": HOST_IFACE_TYPES_REV[in.Type],
+		}
+
+		// details come back as an empty list for interfaces without them
+		var details *zabbix.HostInterfaceDetail
+		if len(in.RawDetails) > 0 && in.RawDetails[0] == '{' {
+			details = &zabbix.HostInterfaceDetail{}
+			if err := json.Unmarshal(in.RawDetails, details); err != nil {
+				log.Debug("unreadable interface details %s: %s", in.RawDetails, err)
+				details = nil
+			}
+		}
+		flattenInterfaceDetails(params, details, d, fmt.Sprintf("interface.%d.", i), api)
+
+		val[i] = params
+	}
+	return val
+}
+
+// resourceProtoHostUpdate terraform update resource handler
+func resourceProtoHostUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	item, err := buildProtoHostObject(d, api)
+
+	if err != nil {
+		return err
+	}
+	item.HostID = d.Id()
+
+	params, err := protoHostParams(api, item)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.CallWithError("hostprototype.update", params); err != nil {
+		return err
+	}
+
+	return resourceProtoHostRead(d, m)
+}
+
+// resourceProtoHostDelete terraform delete resource handler
+func resourceProtoHostDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("hostprototype.delete", []string{d.Id()})
+	return err
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestUnitResourceProtoHost(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	groupID := server.Seed("hostgroup", map[string]interface{}{"name": "tf-unit-group"})
+	ruleID := server.Seed("discoveryrule", map[string]interface{}{"name": "tf-unit-rule"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceProtoHost(server.APIURL(), ruleID, groupID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "ruleid", ruleID),
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "inventory_mode", "automatic"),
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "group_prototypes.#", "1"),
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "interface.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "interface.0.port", "{#PORT}"),
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "interface.1.port", "161"),
+					resource.TestCheckResourceAttr("zabbix_proto_host.test", "interface.1.snmp_community", "public"),
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceProtoHost(url, ruleID, groupID string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_proto_host" "test" {
+	ruleid           = %q
+	host             = "{#VM.NAME}"
+	groups           = [%q]
+	group_prototypes = ["VMs/{#CLUSTER.NAME}"]
+	inventory_mode   = "automatic"
+	interface {
+		dns  = "{#VM.DNS}"
+		port = "{#PORT}"
+	}
+	interface {
+		type           = "snmp"
+		ip             = "{#VM.IP}"
+		snmp_community = "public"
+	}
+}
+`, testUnitProviderConfig(url), ruleID, groupID)
+}
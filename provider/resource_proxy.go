@@ -1,26 +1,70 @@
 package provider
 
 import (
+	"context"
+
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/hoonii2/go-zabbix-api"
 )
 
+var PROXY_OPERATING_MODE = map[string]int{
+	"active":  0,
+	"passive": 1,
+}
+var PROXY_OPERATING_MODE_REV = map[int]string{}
+
+var PROXY_TLS = map[string]int{
+	"unencrypted": 1,
+	"psk":         2,
+	"cert":        4,
+}
+var PROXY_TLS_REV = map[int]string{}
+var PROXY_TLS_ARR = []string{}
+
+// PROXY_COMPATIBILITY version compatibility of a proxy with the server, 6.4+
+var PROXY_COMPATIBILITY = map[string]int{
+	"undefined":   0,
+	"current":     1,
+	"outdated":    2,
+	"unsupported": 3,
+}
+var PROXY_COMPATIBILITY_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range PROXY_OPERATING_MODE {
+		PROXY_OPERATING_MODE_REV[v] = k
+	}
+	for k, v := range PROXY_TLS {
+		PROXY_TLS_REV[v] = k
+		PROXY_TLS_ARR = append(PROXY_TLS_ARR, k)
+	}
+	for k, v := range PROXY_COMPATIBILITY {
+		PROXY_COMPATIBILITY_REV[v] = k
+	}
+	return false
+}()
+
 // resourceProxy terraform resource handler
 func resourceProxy() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceProxyCreate,
-		Read:   resourceProxyRead,
-		Update: resourceProxyUpdate,
-		Delete: resourceProxyDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-
-		Schema: map[string]*schema.Schema{
+		CreateContext: contextCRUD(resourceProxyCreate),
+		ReadContext:   contextCRUD(resourceProxyRead),
+		UpdateContext: contextCRUD(resourceProxyUpdate),
+		DeleteContext: contextCRUD(resourceProxyDelete),
+		Importer:      importByName(lookupProxyByName),
+		CustomizeDiff: customdiff.All(tlsCustomizeDiff, tlsPSKCustomizeDiff, proxyTimeoutsCustomizeDiff, proxyModeCustomizeDiff),
+
+		Schema: mergeSchemas(proxyTimeoutsSchema(), tlsSchema(), map[string]*schema.Schema{
+			"deletion_protection": deletionProtectionSchema(),
 			"name": &schema.Schema{
 				Type:         schema.TypeString,
 				Description:  "Name of the proxy.",
@@ -28,10 +72,11 @@ func resourceProxy() *schema.Resource {
 				Required:     true,
 			},
 			"operating_mode": &schema.Schema{
-				Type:         schema.TypeInt,
-				Description:  "Type of proxy. Possible values: 0 - active proxy; 1 - passive proxy.",
-				ValidateFunc: validation.IntBetween(0, 1),
-				Required:     true,
+				Type:             schema.TypeString,
+				Description:      "Type of proxy. Possible values: active (0); passive (1).",
+				ValidateFunc:     validateEnum(PROXY_OPERATING_MODE),
+				DiffSuppressFunc: suppressEnumDiff(PROXY_OPERATING_MODE),
+				Required:         true,
 			},
 			"description": &schema.Schema{
 				Type:        schema.TypeString,
@@ -39,68 +84,472 @@ func resourceProxy() *schema.Resource {
 				//ValidateFunc: validation.StringIsNotWhiteSpace,
 				Optional: true,
 			},
-			"tls_connect": &schema.Schema{
-				Type:         schema.TypeInt,
-				Description:  "Connections to host.	Possible values: 1 - (default) No encryption; 2 - PSK; 4 - certificate.",
-				ValidateFunc: validation.IntBetween(1, 4),
-				Optional:     true,
-				Default:      1,
+			"proxy_address": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "Comma-delimited IP addresses or DNS names of active Zabbix proxy.",
+				Deprecated:    "proxy_address was renamed to allowed_addresses in zabbix 7.0",
+				ConflictsWith: []string{"allowed_addresses"},
+				Optional:      true,
 			},
-			"tls_accept": &schema.Schema{
-				Type:         schema.TypeInt,
-				Description:  "Connections from host. This is a bitmask field, any combination of possible bitmap values is acceptable. Possible bitmap values: 1 - (default) No encryption; 2 - PSK; 4 - certificate.",
-				ValidateFunc: validation.IntBetween(1, 7),
-				Optional:     true,
-				Default:      1,
+			"allowed_addresses": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "Comma-delimited IP addresses or DNS names of active Zabbix proxy.",
+				ConflictsWith: []string{"proxy_address"},
+				Optional:      true,
 			},
-			"tls_issuer": &schema.Schema{
-				Type:        schema.TypeString,
-				Description: "Certificate issuer.",
-				//ValidateFunc: validation.StringIsNotWhiteSpace,
-				Optional: true,
+			"address": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "IP address or DNS name to connect to, for passive proxies.",
+				ConflictsWith: []string{"interface"},
+				Optional:      true,
+				Computed:      true,
 			},
-			"tls_subject": &schema.Schema{
-				Type:        schema.TypeString,
-				Description: "Certificate subject.",
-				//ValidateFunc: validation.StringIsNotWhiteSpace,
-				Optional: true,
+			"port": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "Port number to connect to, for passive proxies.",
+				ConflictsWith: []string{"interface"},
+				Optional:      true,
+				Computed:      true,
 			},
-			"tls_psk_identity": &schema.Schema{
-				Type:        schema.TypeString,
-				Description: "PSK identity. Do not put sensitive information in the PSK identity, it is transmitted unencrypted over the network to inform a receiver which PSK to use. Required if tls_connect is set to \"PSK\", or tls_accept contains the \"PSK\" bit.",
-				//ValidateFunc: validation.StringIsNotWhiteSpace,
-				Optional: true,
+			"interface": &schema.Schema{
+				Type:          schema.TypeList,
+				Description:   "Interface to connect to, for passive proxies. Alternative to address and port.",
+				ConflictsWith: []string{"address", "port"},
+				MaxItems:      1,
+				Optional:      true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dns": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "DNS name to connect to.",
+							Optional:    true,
+						},
+						"ip": &schema.Schema{
+							Type:         schema.TypeString,
+							Description:  "IP address to connect to.",
+							ValidateFunc: validation.IsIPAddress,
+							Optional:     true,
+						},
+						"useip": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Connect using the IP address instead of the DNS name.",
+							Optional:    true,
+							Default:     true,
+						},
+						"port": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Port number to connect to.",
+							Optional:    true,
+							Default:     "10051",
+						},
+					},
+				},
 			},
-			"tls_psk": &schema.Schema{
+			"local_address": &schema.Schema{
 				Type:        schema.TypeString,
-				Description: "The preshared key, at least 32 hex digits. Required if tls_connect is set to \"PSK\", or tls_accept contains the \"PSK\" bit.",
-				//ValidateFunc: validation.StringIsNotWhiteSpace,
-				Optional: true,
+				Description: "Address for active agents, used when the proxy belongs to a proxy group.",
+				Optional:    true,
+				Computed:    true,
 			},
-			"proxy_address": &schema.Schema{
+			"local_port": &schema.Schema{
 				Type:        schema.TypeString,
-				Description: "Comma-delimited IP addresses or DNS names of active Zabbix proxy.",
-				//ValidateFunc: validation.StringIsNotWhiteSpace,
-				Optional: true,
+				Description: "Local proxy port number to connect to for active agents.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"lastaccess":    proxyLastAccessSchema(),
+			"version":       proxyVersionSchema(),
+			"compatibility": proxyCompatibilitySchema(),
+		}),
+	}
+}
+
+// PROXY_TIMEOUTS per item type timeouts a proxy can override, with the version they appeared in
+var PROXY_TIMEOUTS = map[string]int{
+	"timeout_zabbix_agent":   70000,
+	"timeout_simple_check":   70000,
+	"timeout_snmp_agent":     70000,
+	"timeout_external_check": 70000,
+	"timeout_db_monitor":     70000,
+	"timeout_http_agent":     70000,
+	"timeout_ssh_agent":      70000,
+	"timeout_telnet_agent":   70000,
+	"timeout_script":         70000,
+	"timeout_browser":        70000,
+}
+
+// PROXY_TIMEOUT_DEFAULTS timeouts not defaulting to 3s on the server
+var PROXY_TIMEOUT_DEFAULTS = map[string]string{
+	"timeout_browser": "60s",
+}
+
+// proxyTimeoutsSchema custom_timeouts and the timeouts it enables, zabbix >= 7.0
+func proxyTimeoutsSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"custom_timeouts": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "Override the global item timeouts with the timeout_* attributes. Zabbix >= 7.0.",
+			Optional:    true,
+			Default:     false,
+		},
+	}
+	for k, since := range PROXY_TIMEOUTS {
+		s[k] = &schema.Schema{
+			Type:         schema.TypeString,
+			Description:  fmt.Sprintf("Timeout override, used when custom_timeouts is set. Zabbix >= %s.", versionString(since)),
+			ValidateFunc: validateTimeSuffix,
+			Optional:     true,
+			Default:      "3s",
+		}
+		if def, ok := PROXY_TIMEOUT_DEFAULTS[k]; ok {
+			s[k].Default = def
+		}
+	}
+	return s
+}
+
+// proxyTimeoutsCustomizeDiff check custom timeouts are supported by the server
+func proxyTimeoutsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.Get("custom_timeouts").(bool) {
+		return nil
+	}
+	return requireVersion(m.(*zabbix.API), 70000, "custom_timeouts on proxies")
+}
+
+// proxyModeCustomizeDiff check the connection attributes match operating_mode, passive
+// proxies are connected to and active ones only need their allowed addresses
+func proxyModeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("operating_mode") || !d.NewValueKnown("interface") {
+		return nil
+	}
+
+	iface := d.Get("interface").([]interface{})
+	if enumValue(d.Get("operating_mode").(string), PROXY_OPERATING_MODE) != PROXY_OPERATING_MODE["passive"] {
+		if len(iface) > 0 {
+			return errors.New("interface is only used by passive proxies")
+		}
+		return nil
+	}
+
+	for _, k := range []string{"allowed_addresses", "proxy_address"} {
+		if v, ok := d.GetOk(k); ok && v.(string) != "" {
+			return fmt.Errorf("%s is only used by active proxies", k)
+		}
+	}
+
+	if len(iface) < 1 {
+		if d.NewValueKnown("address") && d.Get("address").(string) == "" {
+			return errors.New("passive proxies need an address or interface to connect to")
+		}
+		return nil
+	}
+	if current, ok := iface[0].(map[string]interface{}); ok {
+		if current["useip"].(bool) && current["ip"].(string) == "" {
+			return errors.New("interface.0.ip is required when useip is set")
+		}
+		if !current["useip"].(bool) && current["dns"].(string) == "" {
+			return errors.New("interface.0.dns is required when useip is not set")
+		}
+	}
+	return nil
+}
+
+// proxyLastAccessSchema computed time the proxy last contacted the server
+func proxyLastAccessSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeInt,
+		Description: "Time when the proxy last contacted the server (unix timestamp).",
+		Computed:    true,
+	}
+}
+
+// proxyVersionSchema computed proxy version, zabbix >= 6.4
+func proxyVersionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeInt,
+		Description: "Version of the proxy as a number, e.g. 60400, 0 if unknown. Zabbix >= 6.4.",
+		Computed:    true,
+	}
+}
+
+// proxyCompatibilitySchema computed proxy version compatibility, zabbix >= 6.4
+func proxyCompatibilitySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Version compatibility with the server, one of undefined, current, outdated, unsupported. Zabbix >= 6.4.",
+		Computed:    true,
+	}
+}
+
+// tlsSchema encryption settings of connections with proxies and hosts
+func tlsSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"tls_connect": &schema.Schema{
+			Type:             schema.TypeString,
+			Description:      "Connections to host.	Possible values: unencrypted (1, default); psk (2); cert (4).",
+			ValidateFunc:     validateEnum(PROXY_TLS),
+			DiffSuppressFunc: suppressEnumDiff(PROXY_TLS),
+			Optional:         true,
+			Default:          "unencrypted",
+		},
+		"tls_accept": &schema.Schema{
+			Type:          schema.TypeInt,
+			Description:   "Connections from host. This is a bitmask field, any combination of possible bitmap values is acceptable. Possible bitmap values: 1 - (default) No encryption; 2 - PSK; 4 - certificate.",
+			ValidateFunc:  validation.IntBetween(1, 7),
+			ConflictsWith: []string{"tls_accept_modes"},
+			Optional:      true,
+			Computed:      true,
+		},
+		"tls_accept_modes": &schema.Schema{
+			Type: schema.TypeSet,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(PROXY_TLS_ARR, false),
 			},
+			Description:   "Connections from host, as a list of unencrypted, psk and cert. Alternative to the tls_accept bitmask.",
+			ConflictsWith: []string{"tls_accept"},
+			Optional:      true,
+		},
+		"tls_issuer": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Certificate issuer.",
+			//ValidateFunc: validation.StringIsNotWhiteSpace,
+			Optional: true,
+		},
+		"tls_subject": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "Certificate subject.",
+			//ValidateFunc: validation.StringIsNotWhiteSpace,
+			Optional: true,
+		},
+		"tls_psk_identity": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "PSK identity. Do not put sensitive information in the PSK identity, it is transmitted unencrypted over the network to inform a receiver which PSK to use. Required if tls_connect is set to \"PSK\", or tls_accept contains the \"PSK\" bit.",
+			//ValidateFunc: validation.StringIsNotWhiteSpace,
+			Optional: true,
 		},
+		"tls_psk": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The preshared key, at least 32 hex digits. Required if tls_connect is set to \"PSK\", or tls_accept contains the \"PSK\" bit. Write-only, the state only keeps a hash of it.",
+			//ValidateFunc: validation.StringIsNotWhiteSpace,
+			Optional:  true,
+			Sensitive: true,
+			StateFunc: hashSecret,
+		},
+		"tls_psk_version": &schema.Schema{
+			Type:        schema.TypeInt,
+			Description: "Change this along with tls_psk to mark a new key, changing it alone is rejected as tls_psk is only known by its hash.",
+			Optional:    true,
+		},
+	}
+}
+
+// buildTLSAccept tls_accept bitmask, from tls_accept_modes when that is used
+func buildTLSAccept(d *schema.ResourceData) int {
+	accept := d.Get("tls_accept").(int)
+	if v := d.Get("tls_accept_modes").(*schema.Set); v.Len() > 0 {
+		accept = 0
+		for _, mode := range v.List() {
+			accept |= PROXY_TLS[mode.(string)]
+		}
+	}
+	if accept == 0 {
+		accept = PROXY_TLS["unencrypted"]
+	}
+	return accept
+}
+
+// setTLSAccept store the tls_accept bitmask, and the modes when those are used
+func setTLSAccept(d *schema.ResourceData, accept int) {
+	d.Set("tls_accept", accept)
+	if d.Get("tls_accept_modes").(*schema.Set).Len() > 0 {
+		modes := []string{}
+		for bit, mode := range PROXY_TLS_REV {
+			if accept&bit != 0 {
+				modes = append(modes, mode)
+			}
+		}
+		d.Set("tls_accept_modes", modes)
+	}
+}
+
+// tlsPSKCustomizeDiff reject identity and version changes without a new tls_psk,
+// zabbix wants the key along with them and the state only has its hash
+var tlsPSKCustomizeDiff = hashedSecretCustomizeDiff("tls_psk", "tls_psk_identity", "tls_psk_version")
+
+// tlsCustomizeDiff check at plan time that the psk and certificate fields match
+// the encryption chosen in tls_connect and tls_accept
+func tlsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	for _, k := range []string{"tls_connect", "tls_accept", "tls_accept_modes", "tls_psk", "tls_psk_identity", "tls_issuer", "tls_subject"} {
+		if !d.NewValueKnown(k) {
+			return nil
+		}
+	}
+
+	connect := enumValue(d.Get("tls_connect").(string), PROXY_TLS)
+	accept := d.Get("tls_accept").(int)
+	if modes := d.Get("tls_accept_modes").(*schema.Set); modes.Len() > 0 {
+		accept = 0
+		for _, mode := range modes.List() {
+			accept |= PROXY_TLS[mode.(string)]
+		}
+	}
+
+	psk := connect == PROXY_TLS["psk"] || accept&PROXY_TLS["psk"] != 0
+	if psk {
+		if d.Get("tls_psk_identity").(string) == "" {
+			return errors.New("tls_psk_identity is required when tls_connect is psk or tls_accept includes psk")
+		}
+		if d.Get("tls_psk").(string) == "" {
+			return errors.New("tls_psk is required when tls_connect is psk or tls_accept includes psk")
+		}
+	}
+
+	cert := connect == PROXY_TLS["cert"] || accept&PROXY_TLS["cert"] != 0
+	if !cert {
+		for _, k := range []string{"tls_issuer", "tls_subject"} {
+			if d.Get(k).(string) != "" {
+				return fmt.Errorf("%s is only used when tls_connect is cert or tls_accept includes cert", k)
+			}
+		}
 	}
+
+	return nil
 }
 
 // dataProxy terraform data handler
 func dataProxy() *schema.Resource {
 	return &schema.Resource{
-		Read: dataProxyRead,
+		ReadContext: contextCRUD(dataProxyRead),
 
-		Schema: map[string]*schema.Schema{
+		Schema: mergeSchemas(searchSchema(false), map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:         schema.TypeString,
 				Description:  "Name of the proxy.",
 				ValidateFunc: validation.StringIsNotWhiteSpace,
 				Required:     true,
 			},
-		},
+			"lastaccess":    proxyLastAccessSchema(),
+			"version":       proxyVersionSchema(),
+			"compatibility": proxyCompatibilitySchema(),
+		}),
+	}
+}
+
+// PROXY_SORT_FIELDS sort_field values of zabbix_proxies and the proxy.get
+// sortfield of each, empty for those it can not sort by
+var PROXY_SORT_FIELDS = map[string]string{
+	"id":             "proxyid",
+	"name":           "name",
+	"operating_mode": "operating_mode",
+	"lastaccess":     "",
+	"version":        "",
+}
+
+// dataProxies terraform plural proxy data handler
+func dataProxies() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataProxiesRead),
+
+		Schema: mergeSchemas(pagingSchema(), searchSchema(true), sortSchema(PROXY_SORT_FIELDS), map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Name of the proxy to filter on, * may be used as a wildcard.",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Optional:     true,
+			},
+			"operating_mode": &schema.Schema{
+				Type:         schema.TypeInt,
+				Description:  "Type of proxy to filter on. Possible values: 0 - active proxy; 1 - passive proxy.",
+				ValidateFunc: validation.IntBetween(0, 1),
+				Optional:     true,
+			},
+			"ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Matching proxy IDs.",
+				Computed:    true,
+			},
+			"proxies": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Matching proxies.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "ID of the proxy.",
+							Computed:    true,
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Name of the proxy.",
+							Computed:    true,
+						},
+						"operating_mode": &schema.Schema{
+							Type:        schema.TypeInt,
+							Description: "Type of proxy.",
+							Computed:    true,
+						},
+						"lastaccess":    proxyLastAccessSchema(),
+						"version":       proxyVersionSchema(),
+						"compatibility": proxyCompatibilitySchema(),
+					},
+				},
+			},
+		}),
+	}
+}
+
+// dataProxiesRead read handler for plural data resource
+func dataProxiesRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"output": []string{"proxyid", "name", "operating_mode", "lastaccess"},
+		"filter": map[string]interface{}{},
+	}
+	if api.Config.Version >= 60400 {
+		params["output"] = append(params["output"].([]string), "version", "compatibility")
+	}
+
+	if v, ok := d.GetOkExists("operating_mode"); ok {
+		params["filter"].(map[string]interface{})["operating_mode"] = v
+	}
+
+	if v, ok := d.GetOk("name"); ok {
+		params["search"] = map[string]interface{}{
+			"name": v,
+		}
+	}
+	applySearch(d, params)
+	log.Debug("performing data lookup with params: %#v", params)
+
+	ids, list, err := pagedGet(d, "proxyid", PROXY_SORT_FIELDS, params, func(params zabbix.Params) ([]map[string]interface{}, error) {
+		proxies, err := proxiesGet(api, params)
+		entries := make([]map[string]interface{}, len(proxies))
+		for i, p := range proxies {
+			entries[i] = map[string]interface{}{
+				"id":             p.ProxyID,
+				"name":           p.Name,
+				"operating_mode": p.OperatingMode,
+				"lastaccess":     p.LastAccess,
+				"version":        p.Version,
+				"compatibility":  PROXY_COMPATIBILITY_REV[p.Compatibility],
+			}
+		}
+		return entries, err
+	})
+
+	if err != nil {
+		return err
 	}
+
+	d.SetId(dataListID(params))
+	d.Set("ids", ids)
+	d.Set("proxies", list)
+
+	return nil
 }
 
 // dataProxyRead read handler for data resource
@@ -117,7 +566,9 @@ func dataProxyRead(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
-	if len(params["filter"].(map[string]interface{})) < 1 {
+	applySearch(d, params, lookups...)
+
+	if len(params["filter"].(map[string]interface{})) < 1 && params["search"] == nil {
 		return errors.New("no proxy lookup attribute")
 	}
 	log.Debug("performing data lookup with params: %#v", params)
@@ -125,37 +576,327 @@ func dataProxyRead(d *schema.ResourceData, m interface{}) error {
 	return proxyRead(d, m, params)
 }
 
+// Proxy represent Zabbix proxy object, the api library only knows the pre 7.0 fields
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/proxy/object
+type Proxy struct {
+	ProxyID          string `json:"proxyid,omitempty"`
+	Name             string `json:"name"`
+	OperatingMode    int    `json:"operating_mode,string"`
+	Description      string `json:"description"`
+	TLSConnect       int    `json:"tls_connect,string"`
+	TLSAccept        int    `json:"tls_accept,string"`
+	TLSIssuer        string `json:"tls_issuer"`
+	TLSSubject       string `json:"tls_subject"`
+	TLSPSKIdentity   string `json:"tls_psk_identity,omitempty"`
+	TLSPSK           string `json:"tls_psk,omitempty"`
+	AllowedAddresses string `json:"allowed_addresses"`
+	Address          string `json:"address,omitempty"`
+	Port             string `json:"port,omitempty"`
+	LocalAddress     string `json:"local_address,omitempty"`
+	LocalPort        string `json:"local_port,omitempty"`
+	LastAccess       int64  `json:"lastaccess,omitempty,string"`
+	Version          int    `json:"version,omitempty,string"`
+	Compatibility    int    `json:"compatibility,omitempty,string"`
+
+	// passive proxy interface before 7.0, only address and port are kept after
+	Interface *legacyProxyInterface `json:"-"`
+
+	CustomTimeouts       int    `json:"custom_timeouts,string"`
+	TimeoutZabbixAgent   string `json:"timeout_zabbix_agent,omitempty"`
+	TimeoutSimpleCheck   string `json:"timeout_simple_check,omitempty"`
+	TimeoutSNMPAgent     string `json:"timeout_snmp_agent,omitempty"`
+	TimeoutExternalCheck string `json:"timeout_external_check,omitempty"`
+	TimeoutDBMonitor     string `json:"timeout_db_monitor,omitempty"`
+	TimeoutHTTPAgent     string `json:"timeout_http_agent,omitempty"`
+	TimeoutSSHAgent      string `json:"timeout_ssh_agent,omitempty"`
+	TimeoutTelnetAgent   string `json:"timeout_telnet_agent,omitempty"`
+	TimeoutScript        string `json:"timeout_script,omitempty"`
+	TimeoutBrowser       string `json:"timeout_browser,omitempty"`
+}
+
+// timeouts timeout fields of the proxy by attribute name
+func (p *Proxy) timeouts() map[string]*string {
+	return map[string]*string{
+		"timeout_zabbix_agent":   &p.TimeoutZabbixAgent,
+		"timeout_simple_check":   &p.TimeoutSimpleCheck,
+		"timeout_snmp_agent":     &p.TimeoutSNMPAgent,
+		"timeout_external_check": &p.TimeoutExternalCheck,
+		"timeout_db_monitor":     &p.TimeoutDBMonitor,
+		"timeout_http_agent":     &p.TimeoutHTTPAgent,
+		"timeout_ssh_agent":      &p.TimeoutSSHAgent,
+		"timeout_telnet_agent":   &p.TimeoutTelnetAgent,
+		"timeout_script":         &p.TimeoutScript,
+		"timeout_browser":        &p.TimeoutBrowser,
+	}
+}
+
+// Proxies is an array of Proxy
+type Proxies []Proxy
+
+// legacyProxy proxy object of zabbix < 7.0, named by host with an active/passive status
+type legacyProxy struct {
+	ProxyID        string          `json:"proxyid,omitempty"`
+	Host           string          `json:"host"`
+	Status         int             `json:"status,string"`
+	Description    string          `json:"description"`
+	TLSConnect     int             `json:"tls_connect,string"`
+	TLSAccept      int             `json:"tls_accept,string"`
+	TLSIssuer      string          `json:"tls_issuer"`
+	TLSSubject     string          `json:"tls_subject"`
+	TLSPSKIdentity string          `json:"tls_psk_identity,omitempty"`
+	TLSPSK         string          `json:"tls_psk,omitempty"`
+	ProxyAddress   string          `json:"proxy_address"`
+	LastAccess     int64           `json:"lastaccess,omitempty,string"`
+	Version        int             `json:"version,omitempty,string"`
+	Compatibility  int             `json:"compatibility,omitempty,string"`
+	Interface      json.RawMessage `json:"interface,omitempty"`
+}
+
+// legacyProxyInterface passive proxy interface of zabbix < 7.0
+type legacyProxyInterface struct {
+	DNS   string `json:"dns"`
+	IP    string `json:"ip"`
+	UseIP int    `json:"useip,string"`
+	Port  string `json:"port"`
+}
+
+// proxy status values before 7.0, active and passive
+const (
+	legacyProxyActive  = 5
+	legacyProxyPassive = 6
+)
+
+// legacy field names of proxy.get filters and output
+var PROXY_LEGACY_FIELDS = map[string]string{
+	"name":              "host",
+	"operating_mode":    "status",
+	"allowed_addresses": "proxy_address",
+}
+
+// proxiesGet wrapper for proxy.get, translating the pre 7.0 proxy object
+func proxiesGet(api *zabbix.API, params zabbix.Params) (res Proxies, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	if api.Config.Version >= 70000 {
+		err = api.CallWithErrorParse("proxy.get", params, &res)
+		return
+	}
+
+	legacyParams := zabbix.Params{}
+	for k, v := range params {
+		legacyParams[k] = v
+	}
+	legacyParams["selectInterface"] = "extend"
+	if output, ok := params["output"].([]string); ok {
+		fields := []string{}
+		for _, f := range output {
+			if l, ok := PROXY_LEGACY_FIELDS[f]; ok {
+				f = l
+			}
+			fields = append(fields, f)
+		}
+		legacyParams["output"] = fields
+	}
+	if sortBy, ok := params["sortfield"].([]string); ok {
+		fields := []string{}
+		for _, f := range sortBy {
+			if l, ok := PROXY_LEGACY_FIELDS[f]; ok {
+				f = l
+			} else if f == "proxyid" {
+				f = "hostid"
+			}
+			fields = append(fields, f)
+		}
+		legacyParams["sortfield"] = fields
+	}
+	for _, k := range []string{"filter", "search"} {
+		if m, ok := params[k].(map[string]interface{}); ok {
+			legacy := map[string]interface{}{}
+			for f, v := range m {
+				if f == "operating_mode" {
+					v = v.(int) + legacyProxyActive
+				}
+				if l, ok := PROXY_LEGACY_FIELDS[f]; ok {
+					f = l
+				}
+				legacy[f] = v
+			}
+			legacyParams[k] = legacy
+		}
+	}
+
+	var legacy []legacyProxy
+	if err = api.CallWithErrorParse("proxy.get", legacyParams, &legacy); err != nil {
+		return
+	}
+
+	res = make(Proxies, len(legacy))
+	for i, l := range legacy {
+		res[i] = Proxy{
+			ProxyID:          l.ProxyID,
+			Name:             l.Host,
+			OperatingMode:    l.Status - legacyProxyActive,
+			Description:      l.Description,
+			TLSConnect:       l.TLSConnect,
+			TLSAccept:        l.TLSAccept,
+			TLSIssuer:        l.TLSIssuer,
+			TLSSubject:       l.TLSSubject,
+			TLSPSKIdentity:   l.TLSPSKIdentity,
+			TLSPSK:           l.TLSPSK,
+			AllowedAddresses: l.ProxyAddress,
+			LastAccess:       l.LastAccess,
+			Version:          l.Version,
+			Compatibility:    l.Compatibility,
+		}
+
+		// active proxies return an empty list instead of an interface object
+		var iface legacyProxyInterface
+		if len(l.Interface) > 0 && l.Interface[0] == '{' {
+			if err = json.Unmarshal(l.Interface, &iface); err != nil {
+				return
+			}
+			res[i].Address = iface.DNS
+			if iface.UseIP == 1 {
+				res[i].Address = iface.IP
+			}
+			res[i].Port = iface.Port
+			res[i].Interface = &iface
+		}
+	}
+	return
+}
+
+// proxyParams build proxy.create/update params for the connected server version
+func proxyParams(api *zabbix.API, proxy Proxy) interface{} {
+	if api.Config.Version >= 70000 {
+		// timeouts the server doesn't know yet
+		for k, v := range proxy.timeouts() {
+			if api.Config.Version < PROXY_TIMEOUTS[k] {
+				*v = ""
+			}
+		}
+		return Proxies{proxy}
+	}
+
+	legacy := legacyProxy{
+		ProxyID:        proxy.ProxyID,
+		Host:           proxy.Name,
+		Status:         proxy.OperatingMode + legacyProxyActive,
+		Description:    proxy.Description,
+		TLSConnect:     proxy.TLSConnect,
+		TLSAccept:      proxy.TLSAccept,
+		TLSIssuer:      proxy.TLSIssuer,
+		TLSSubject:     proxy.TLSSubject,
+		TLSPSKIdentity: proxy.TLSPSKIdentity,
+		TLSPSK:         proxy.TLSPSK,
+		ProxyAddress:   proxy.AllowedAddresses,
+	}
+
+	// passive proxies are reached through an interface object
+	if legacy.Status == legacyProxyPassive {
+		iface := legacyProxyInterface{
+			DNS:   proxy.Address,
+			UseIP: 0,
+			Port:  proxy.Port,
+		}
+		if net.ParseIP(proxy.Address) != nil {
+			iface.DNS = ""
+			iface.IP = proxy.Address
+			iface.UseIP = 1
+		}
+		if proxy.Interface != nil {
+			iface = *proxy.Interface
+		}
+		if iface.Port == "" {
+			iface.Port = "10051"
+		}
+		legacy.Interface, _ = json.Marshal(iface)
+		legacy.ProxyAddress = ""
+	}
+
+	return []legacyProxy{legacy}
+}
+
+// buildProxyObject create a proxy object from terraform data
+func buildProxyObject(d *schema.ResourceData) Proxy {
+	proxy := Proxy{
+		ProxyID:          d.Id(),
+		Name:             d.Get("name").(string),
+		OperatingMode:    enumValue(d.Get("operating_mode").(string), PROXY_OPERATING_MODE),
+		Description:      d.Get("description").(string),
+		TLSConnect:       enumValue(d.Get("tls_connect").(string), PROXY_TLS),
+		TLSAccept:        buildTLSAccept(d),
+		TLSIssuer:        d.Get("tls_issuer").(string),
+		TLSSubject:       d.Get("tls_subject").(string),
+		TLSPSKIdentity:   d.Get("tls_psk_identity").(string),
+		AllowedAddresses: d.Get("allowed_addresses").(string),
+		Address:          d.Get("address").(string),
+		Port:             d.Get("port").(string),
+		LocalAddress:     d.Get("local_address").(string),
+		LocalPort:        d.Get("local_port").(string),
+	}
+
+	// the psk is write-only, only send it when it is new, any other time the
+	// state only holds its hash and the server keeps the current one
+	if d.IsNewResource() || d.HasChange("tls_psk") {
+		proxy.TLSPSK = d.Get("tls_psk").(string)
+	}
+
+	// an interface block stands in for address and port, 7.0 only takes the one
+	// address it connects to
+	if v := d.Get("interface").([]interface{}); len(v) > 0 && v[0] != nil {
+		current := v[0].(map[string]interface{})
+		proxy.Interface = &legacyProxyInterface{
+			DNS:   current["dns"].(string),
+			IP:    current["ip"].(string),
+			Port:  current["port"].(string),
+			UseIP: 0,
+		}
+		proxy.Address = proxy.Interface.DNS
+		if current["useip"].(bool) {
+			proxy.Interface.UseIP = 1
+			proxy.Address = proxy.Interface.IP
+		}
+		proxy.Port = proxy.Interface.Port
+	}
+
+	// the timeouts are only accepted when overriding the global ones
+	if d.Get("custom_timeouts").(bool) {
+		proxy.CustomTimeouts = 1
+		for k, v := range proxy.timeouts() {
+			*v = d.Get(k).(string)
+		}
+	}
+
+	// proxy_address was renamed to allowed_addresses in 7.0
+	if v, ok := d.GetOk("proxy_address"); ok {
+		proxy.AllowedAddresses = v.(string)
+	}
+
+	return proxy
+}
+
 // terraform proxy create function
 func resourceProxyCreate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
-	proxy := zabbix.Proxy{
-		ProxyID:        d.Id(),
-		Name:           d.Get("name").(string),
-		OperatingMode:  d.Get("operating_mode").(int),
-		Description:    d.Get("description").(string),
-		TLSConnect:     d.Get("tls_connect").(int),
-		TLSAccept:      d.Get("tls_accept").(int),
-		TLSIssuer:      d.Get("tls_issuer").(string),
-		TLSSubject:     d.Get("tls_subject").(string),
-		TLSPSKIdentity: d.Get("tls_psk_identity").(string),
-		TLSPSK:         d.Get("tls_psk").(string),
-		ProxyAddress:   d.Get("proxy_address").(string),
-	}
-
-	proxies := []zabbix.Proxy{proxy}
+	proxy := buildProxyObject(d)
 
-	err := api.ProxiesCreate(proxies)
+	response, err := api.CallWithError("proxy.create", proxyParams(api, proxy))
 
 	if err != nil {
 		return err
 	}
 
-	log.Trace("created Proxy: %+v", proxies[0])
+	result := response.Result.(map[string]interface{})
+	proxy.ProxyID = result["proxyids"].([]interface{})[0].(string)
 
-	d.SetId(proxies[0].ProxyID)
+	log.Trace("created Proxy: %s", proxy.ProxyID)
 
-	return resourceProxyRead(d, m)
+	d.SetId(proxy.ProxyID)
+
+	return readAfterWrite(d, m, resourceProxy().Schema, resourceProxyRead)
 }
 
 // proxyRead common proxy read function
@@ -164,7 +905,7 @@ func proxyRead(d *schema.ResourceData, m interface{}, params zabbix.Params) erro
 
 	log.Debug("Lookup of proxy with params %#v", params)
 
-	proxys, err := api.ProxiesGet(params)
+	proxys, err := proxiesGet(api, params)
 
 	if err != nil {
 		return err
@@ -179,23 +920,69 @@ func proxyRead(d *schema.ResourceData, m interface{}, params zabbix.Params) erro
 	}
 	proxy := proxys[0]
 
-	log.Debug("Got proxy: %+v", proxy)
+	log.Debug("Got proxy: %s", proxy.ProxyID)
 
 	d.SetId(proxy.ProxyID)
 	d.Set("name", proxy.Name)
-	d.Set("operating_mode", proxy.OperatingMode)
+	setEnum(d, "operating_mode", proxy.OperatingMode, PROXY_OPERATING_MODE, PROXY_OPERATING_MODE_REV)
 	d.Set("description", proxy.Description)
-	d.Set("tls_connect", proxy.TLSConnect)
-	d.Set("tls_accept", proxy.TLSAccept)
+	setEnum(d, "tls_connect", proxy.TLSConnect, PROXY_TLS, PROXY_TLS_REV)
+	setTLSAccept(d, proxy.TLSAccept)
 	d.Set("tls_issuer", proxy.TLSIssuer)
 	d.Set("tls_subject", proxy.TLSSubject)
 	d.Set("tls_psk_identity", proxy.TLSPSKIdentity)
-	d.Set("tls_psk", proxy.TLSPSK)
-	d.Set("proxy_address", proxy.ProxyAddress)
+	d.Set("address", proxy.Address)
+	d.Set("port", proxy.Port)
+	if v := d.Get("interface").([]interface{}); len(v) > 0 && v[0] != nil {
+		d.Set("interface", flattenProxyInterface(proxy, v[0].(map[string]interface{})))
+	}
+	d.Set("local_address", proxy.LocalAddress)
+	d.Set("local_port", proxy.LocalPort)
+	d.Set("custom_timeouts", proxy.CustomTimeouts == 1)
+	if proxy.CustomTimeouts == 1 {
+		for k, v := range proxy.timeouts() {
+			if *v != "" {
+				d.Set(k, *v)
+			}
+		}
+	}
+	d.Set("lastaccess", proxy.LastAccess)
+	d.Set("version", proxy.Version)
+	d.Set("compatibility", PROXY_COMPATIBILITY_REV[proxy.Compatibility])
+
+	// keep the addresses under whichever name the configuration uses
+	if _, ok := d.GetOk("proxy_address"); ok {
+		d.Set("proxy_address", proxy.AllowedAddresses)
+	} else {
+		d.Set("allowed_addresses", proxy.AllowedAddresses)
+	}
 
 	return nil
 }
 
+// flattenProxyInterface interface block of a proxy, 7.0 only returns the address in
+// use so the other one is kept from the configuration
+func flattenProxyInterface(proxy Proxy, current map[string]interface{}) []interface{} {
+	iface := map[string]interface{}{
+		"dns":   current["dns"],
+		"ip":    current["ip"],
+		"useip": current["useip"],
+		"port":  proxy.Port,
+	}
+
+	if proxy.Interface != nil {
+		iface["dns"] = proxy.Interface.DNS
+		iface["ip"] = proxy.Interface.IP
+		iface["useip"] = proxy.Interface.UseIP == 1
+	} else if current["useip"].(bool) {
+		iface["ip"] = proxy.Address
+	} else {
+		iface["dns"] = proxy.Address
+	}
+
+	return []interface{}{iface}
+}
+
 // resourceProxyRead terraform resource read handler
 func resourceProxyRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of Proxy with id %s", d.Id())
@@ -209,33 +996,21 @@ func resourceProxyRead(d *schema.ResourceData, m interface{}) error {
 func resourceProxyUpdate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
-	proxy := zabbix.Proxy{
-		ProxyID:        d.Id(),
-		Name:           d.Get("name").(string),
-		OperatingMode:  d.Get("operating_mode").(int),
-		Description:    d.Get("description").(string),
-		TLSConnect:     d.Get("tls_connect").(int),
-		TLSAccept:      d.Get("tls_accept").(int),
-		TLSIssuer:      d.Get("tls_issuer").(string),
-		TLSSubject:     d.Get("tls_subject").(string),
-		TLSPSKIdentity: d.Get("tls_psk_identity").(string),
-		TLSPSK:         d.Get("tls_psk").(string),
-		ProxyAddress:   d.Get("proxy_address").(string),
-	}
-
-	proxies := []zabbix.Proxy{proxy}
-
-	err := api.ProxiesUpdate(proxies)
+	_, err := api.CallWithError("proxy.update", proxyParams(api, buildProxyObject(d)))
 
 	if err != nil {
 		return err
 	}
 
-	return resourceProxyRead(d, m)
+	return readAfterWrite(d, m, resourceProxy().Schema, resourceProxyRead)
 }
 
 // resourceProxyDelete terraform resource delete handler
 func resourceProxyDelete(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
+
+	if err := checkDeletionProtection(d, "proxy"); err != nil {
+		return err
+	}
 	return api.ProxiesDeleteByIds([]string{d.Id()})
 }
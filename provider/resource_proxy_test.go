@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const (
+	testUnitPSK1 = "0123456789abcdef0123456789abcdef"
+	testUnitPSK2 = "fedcba9876543210fedcba9876543210"
+)
+
+func TestUnitResourceProxyPSK(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceProxyPSK(server.APIURL(), "tf-unit-identity", testUnitPSK1, 1),
+				Check:  testUnitCheckCallParam(server, "proxy.create", "tls_psk", testUnitPSK1),
+			},
+			{
+				Config:      testUnitResourceProxyPSK(server.APIURL(), "tf-unit-identity", testUnitPSK1, 2),
+				ExpectError: regexp.MustCompile("tls_psk_version changed but tls_psk did not"),
+			},
+			{
+				Config:      testUnitResourceProxyPSK(server.APIURL(), "tf-unit-renamed", testUnitPSK1, 1),
+				ExpectError: regexp.MustCompile("tls_psk_identity changed but tls_psk did not"),
+			},
+			{
+				Config: testUnitResourceProxyPSK(server.APIURL(), "tf-unit-renamed", testUnitPSK2, 2),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCallParam(server, "proxy.update", "tls_psk", testUnitPSK2),
+					testUnitCheckCallParam(server, "proxy.update", "tls_psk_identity", "tf-unit-renamed"),
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceProxyPSK(url, identity, psk string, version int) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_proxy" "test" {
+	name             = "tf-unit-proxy"
+	operating_mode   = "active"
+	tls_accept_modes = ["psk"]
+	tls_psk_identity = %q
+	tls_psk          = %q
+	tls_psk_version  = %d
+}
+`, testUnitProviderConfig(url), identity, psk, version)
+}
@@ -0,0 +1,449 @@
+package provider
+
+import (
+	"context"
+
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// SERVICE_ALGORITHM how a service derives its status from its children
+var SERVICE_ALGORITHM = map[string]int{
+	"status_ok":         0,
+	"most_critical_all": 1,
+	"most_critical_one": 2,
+}
+var SERVICE_ALGORITHM_REV = map[int]string{}
+
+// SERVICE_PROPAGATION how the status of a service propagates to its parents
+var SERVICE_PROPAGATION = map[string]int{
+	"as_is":    0,
+	"increase": 1,
+	"decrease": 2,
+	"ignore":   3,
+	"fixed":    4,
+}
+var SERVICE_PROPAGATION_REV = map[int]string{}
+
+// SERVICE_STATUS service statuses, the trigger severities and ok
+var SERVICE_STATUS = map[string]int{
+	"ok":             -1,
+	"not_classified": 0,
+	"info":           1,
+	"warn":           2,
+	"average":        3,
+	"high":           4,
+	"disaster":       5,
+}
+var SERVICE_STATUS_REV = map[int]string{}
+
+// SERVICE_STATUS_RULE status rule conditions on the children of a service
+var SERVICE_STATUS_RULE = map[string]int{
+	"count_at_least":           0,
+	"percent_at_least":         1,
+	"count_less_than":          2,
+	"percent_less_than":        3,
+	"weight_at_least":          4,
+	"weight_percent_at_least":  5,
+	"weight_less_than":         6,
+	"weight_percent_less_than": 7,
+}
+var SERVICE_STATUS_RULE_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range SERVICE_ALGORITHM {
+		SERVICE_ALGORITHM_REV[v] = k
+	}
+	for k, v := range SERVICE_PROPAGATION {
+		SERVICE_PROPAGATION_REV[v] = k
+	}
+	for k, v := range SERVICE_STATUS {
+		SERVICE_STATUS_REV[v] = k
+	}
+	for k, v := range SERVICE_STATUS_RULE {
+		SERVICE_STATUS_RULE_REV[v] = k
+	}
+	return false
+}()
+
+// serviceObject service with its rules and relations
+type serviceObject struct {
+	ServiceID        string `json:"serviceid"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Algorithm        int    `json:"algorithm,string"`
+	SortOrder        int    `json:"sortorder,string"`
+	Weight           int    `json:"weight,string"`
+	PropagationRule  int    `json:"propagation_rule,string"`
+	PropagationValue int    `json:"propagation_value,string"`
+	ProblemTags      []struct {
+		Tag      string `json:"tag"`
+		Operator int    `json:"operator,string"`
+		Value    string `json:"value"`
+	} `json:"problem_tags"`
+	StatusRules []struct {
+		Type        int `json:"type,string"`
+		LimitValue  int `json:"limit_value,string"`
+		LimitStatus int `json:"limit_status,string"`
+		NewStatus   int `json:"new_status,string"`
+	} `json:"status_rules"`
+	Parents []struct {
+		ServiceID string `json:"serviceid"`
+	} `json:"parents"`
+	Children []struct {
+		ServiceID string `json:"serviceid"`
+	} `json:"children"`
+}
+
+// resourceService terraform resource handler
+func resourceService() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceServiceCreate),
+		ReadContext:   contextCRUD(resourceServiceRead),
+		UpdateContext: contextCRUD(resourceServiceUpdate),
+		DeleteContext: contextCRUD(resourceServiceDelete),
+		Importer:      importByName(lookupByField("service.get", "serviceid", "name")),
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the service.",
+				Required:     true,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Description of the service.",
+				Optional:    true,
+			},
+			"algorithm": &schema.Schema{
+				Type:             schema.TypeString,
+				ValidateFunc:     validateEnum(SERVICE_ALGORITHM),
+				DiffSuppressFunc: suppressEnumDiff(SERVICE_ALGORITHM),
+				Description:      "Status calculation rule. Possible values: status_ok (0), most_critical_all (1), most_critical_one (2, default).",
+				Optional:         true,
+				Default:          "most_critical_one",
+			},
+			"sort_order": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(0, 999),
+				Description:  "Position of the service among its siblings.",
+				Optional:     true,
+				Default:      0,
+			},
+			"weight": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(0, 1000000),
+				Description:  "Weight of the service in the weight based status rules of its parents.",
+				Optional:     true,
+				Default:      0,
+			},
+			"propagation_rule": &schema.Schema{
+				Type:             schema.TypeString,
+				ValidateFunc:     validateEnum(SERVICE_PROPAGATION),
+				DiffSuppressFunc: suppressEnumDiff(SERVICE_PROPAGATION),
+				Description:      "Status propagated to the parents. Possible values: as_is (0, default), increase (1), decrease (2), ignore (3), fixed (4).",
+				Optional:         true,
+				Default:          "as_is",
+			},
+			"propagation_value": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Severity levels the status is increased or decreased by, 1-5, or the fixed status, e.g. ok or high.",
+				Optional:    true,
+			},
+			"problem_tag": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Tags of the problems the service status is calculated from, services with problem tags have no children.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag": &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "Tag name.",
+							Required:     true,
+						},
+						"operator": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(PROBLEM_TAG_OPERATOR),
+							DiffSuppressFunc: suppressEnumDiff(PROBLEM_TAG_OPERATOR),
+							Description:      "How the value is matched. Possible values: equals (0, default), contains (2).",
+							Optional:         true,
+							Default:          "equals",
+						},
+						"value": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Tag value.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"status_rule": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Rules on the children setting the status of the service, the most critical matching one wins over algorithm.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(SERVICE_STATUS_RULE),
+							DiffSuppressFunc: suppressEnumDiff(SERVICE_STATUS_RULE),
+							Description:      "Condition on the children, e.g. count_at_least or weight_percent_less_than.",
+							Required:         true,
+						},
+						"limit_value": &schema.Schema{
+							Type:         schema.TypeInt,
+							ValidateFunc: validation.IntBetween(1, 1000000),
+							Description:  "Number, percentage or weight of the condition.",
+							Required:     true,
+						},
+						"limit_status": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(SERVICE_STATUS),
+							DiffSuppressFunc: suppressEnumDiff(SERVICE_STATUS),
+							Description:      "Status of the children the condition counts.",
+							Required:         true,
+						},
+						"new_status": &schema.Schema{
+							Type:             schema.TypeString,
+							ValidateFunc:     validateEnum(SERVICE_STATUS),
+							DiffSuppressFunc: suppressEnumDiff(SERVICE_STATUS),
+							Description:      "Status of the service when the condition matches.",
+							Required:         true,
+						},
+					},
+				},
+			},
+			"parent_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Parent services. Left unset, parents linked elsewhere are kept.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"child_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Child services. Left unset, children linked elsewhere are kept.",
+				Optional:    true,
+				Computed:    true,
+			},
+		},
+		CustomizeDiff: serviceCustomizeDiff,
+	}
+}
+
+// serviceCustomizeDiff check the combinations of settings the api accepts
+func serviceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	value := d.Get("propagation_value").(string)
+	switch enumName(d.Get("propagation_rule").(string), SERVICE_PROPAGATION) {
+	case "increase", "decrease":
+		if n := enumValue(value, SERVICE_STATUS); n < 1 {
+			return fmt.Errorf("propagation_value has to be 1-5 for rule %s", d.Get("propagation_rule"))
+		}
+	case "fixed":
+		if enumName(value, SERVICE_STATUS) == "" {
+			return fmt.Errorf("propagation_value has to be a status for rule fixed")
+		}
+	default:
+		if value != "" {
+			return fmt.Errorf("propagation_value is only used by rules increase, decrease and fixed")
+		}
+	}
+
+	for i, raw := range d.Get("status_rule").([]interface{}) {
+		r := raw.(map[string]interface{})
+		switch enumName(r["type"].(string), SERVICE_STATUS_RULE) {
+		case "percent_at_least", "percent_less_than", "weight_percent_at_least", "weight_percent_less_than":
+			if r["limit_value"].(int) > 100 {
+				return fmt.Errorf("status_rule %d: limit_value is a percentage, 1-100", i)
+			}
+		}
+		if enumName(r["new_status"].(string), SERVICE_STATUS) == "ok" {
+			return fmt.Errorf("status_rule %d: new_status has to be a severity", i)
+		}
+	}
+
+	if len(d.Get("problem_tag").([]interface{})) > 0 && d.Get("child_ids").(*schema.Set).Len() > 0 {
+		return fmt.Errorf("services with problem_tag have no children")
+	}
+	return nil
+}
+
+// buildServiceParams service create/update params from terraform data, parents
+// and children are only sent when configured so links made by other services stay
+func buildServiceParams(d *schema.ResourceData) zabbix.Params {
+	propagationValue := 0
+	if v := d.Get("propagation_value").(string); v != "" {
+		propagationValue = enumValue(v, SERVICE_STATUS)
+	}
+
+	problemTags := []map[string]interface{}{}
+	for _, raw := range d.Get("problem_tag").([]interface{}) {
+		t := raw.(map[string]interface{})
+		problemTags = append(problemTags, map[string]interface{}{
+			"tag":      t["tag"].(string),
+			"operator": enumValue(t["operator"].(string), PROBLEM_TAG_OPERATOR),
+			"value":    t["value"].(string),
+		})
+	}
+
+	statusRules := []map[string]interface{}{}
+	for _, raw := range d.Get("status_rule").([]interface{}) {
+		r := raw.(map[string]interface{})
+		statusRules = append(statusRules, map[string]interface{}{
+			"type":         enumValue(r["type"].(string), SERVICE_STATUS_RULE),
+			"limit_value":  r["limit_value"].(int),
+			"limit_status": enumValue(r["limit_status"].(string), SERVICE_STATUS),
+			"new_status":   enumValue(r["new_status"].(string), SERVICE_STATUS),
+		})
+	}
+
+	params := zabbix.Params{
+		"name":              d.Get("name").(string),
+		"description":       d.Get("description").(string),
+		"algorithm":         enumValue(d.Get("algorithm").(string), SERVICE_ALGORITHM),
+		"sortorder":         d.Get("sort_order").(int),
+		"weight":            d.Get("weight").(int),
+		"propagation_rule":  enumValue(d.Get("propagation_rule").(string), SERVICE_PROPAGATION),
+		"propagation_value": propagationValue,
+		"problem_tags":      problemTags,
+		"status_rules":      statusRules,
+	}
+
+	for field, key := range map[string]string{"parents": "parent_ids", "children": "child_ids"} {
+		if _, ok := d.GetOk(key); (d.IsNewResource() && ok) || (!d.IsNewResource() && d.HasChange(key)) {
+			params[field] = idObjects("serviceid", d.Get(key).(*schema.Set))
+		}
+	}
+	return params
+}
+
+// resourceServiceCreate terraform resource create handler
+func resourceServiceCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	// services were reworked around problem tags in 6.0
+	if err := requireVersion(api, 60000, "zabbix_service"); err != nil {
+		return err
+	}
+
+	response, err := api.CallWithError("service.create", buildServiceParams(d))
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	d.SetId(result["serviceids"].([]interface{})[0].(string))
+	log.Trace("created service: %s", d.Id())
+
+	return readAfterWrite(d, m, resourceService().Schema, resourceServiceRead)
+}
+
+// resourceServiceRead terraform resource read handler
+func resourceServiceRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of service with id %s", d.Id())
+
+	var services []serviceObject
+	err := api.CallWithErrorParse("service.get", zabbix.Params{
+		"output":            "extend",
+		"serviceids":        d.Id(),
+		"selectParents":     []string{"serviceid"},
+		"selectChildren":    []string{"serviceid"},
+		"selectProblemTags": "extend",
+		"selectStatusRules": "extend",
+	}, &services)
+	if err != nil {
+		return err
+	}
+
+	if len(services) < 1 {
+		d.SetId("")
+		return nil
+	}
+	t := services[0]
+
+	log.Debug("Got service: %s %s", t.ServiceID, t.Name)
+
+	problemTags := []interface{}{}
+	for _, tag := range t.ProblemTags {
+		problemTags = append(problemTags, map[string]interface{}{
+			"tag":      tag.Tag,
+			"operator": PROBLEM_TAG_OPERATOR_REV[tag.Operator],
+			"value":    tag.Value,
+		})
+	}
+
+	statusRules := []interface{}{}
+	for _, r := range t.StatusRules {
+		statusRules = append(statusRules, map[string]interface{}{
+			"type":         SERVICE_STATUS_RULE_REV[r.Type],
+			"limit_value":  r.LimitValue,
+			"limit_status": SERVICE_STATUS_REV[r.LimitStatus],
+			"new_status":   SERVICE_STATUS_REV[r.NewStatus],
+		})
+	}
+
+	parents := []interface{}{}
+	for _, p := range t.Parents {
+		parents = append(parents, p.ServiceID)
+	}
+	children := []interface{}{}
+	for _, c := range t.Children {
+		children = append(children, c.ServiceID)
+	}
+
+	// the value means nothing to the other rules
+	propagationValue := ""
+	switch SERVICE_PROPAGATION_REV[t.PropagationRule] {
+	case "increase", "decrease":
+		propagationValue = fmt.Sprintf("%d", t.PropagationValue)
+	case "fixed":
+		propagationValue = SERVICE_STATUS_REV[t.PropagationValue]
+		if enumValue(d.Get("propagation_value").(string), SERVICE_STATUS) == t.PropagationValue {
+			propagationValue = d.Get("propagation_value").(string)
+		}
+	}
+
+	d.Set("name", t.Name)
+	d.Set("description", t.Description)
+	setEnum(d, "algorithm", t.Algorithm, SERVICE_ALGORITHM, SERVICE_ALGORITHM_REV)
+	d.Set("sort_order", t.SortOrder)
+	d.Set("weight", t.Weight)
+	setEnum(d, "propagation_rule", t.PropagationRule, SERVICE_PROPAGATION, SERVICE_PROPAGATION_REV)
+	d.Set("propagation_value", propagationValue)
+	d.Set("problem_tag", problemTags)
+	d.Set("status_rule", statusRules)
+	d.Set("parent_ids", schema.NewSet(schema.HashString, parents))
+	d.Set("child_ids", schema.NewSet(schema.HashString, children))
+
+	return nil
+}
+
+// resourceServiceUpdate terraform resource update handler
+func resourceServiceUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := buildServiceParams(d)
+	params["serviceid"] = d.Id()
+
+	if _, err := api.CallWithError("service.update", params); err != nil {
+		return err
+	}
+
+	return readAfterWrite(d, m, resourceService().Schema, resourceServiceRead)
+}
+
+// resourceServiceDelete terraform resource delete handler
+func resourceServiceDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("service.delete", []string{d.Id()})
+	return err
+}
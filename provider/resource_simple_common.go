@@ -1,19 +1,19 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // terraform resource handler for item type
 func resourceItemSimple() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemSimpleModFunc, itemSimpleReadFunc),
-		Read:   itemGetReadWrapper(itemSimpleReadFunc),
-		Update: itemGetUpdateWrapper(itemSimpleModFunc, itemSimpleReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemSimpleModFunc, itemSimpleReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemSimpleReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemSimpleModFunc, itemSimpleReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema),
@@ -21,12 +21,12 @@ func resourceItemSimple() *schema.Resource {
 }
 func resourceProtoItemSimple() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemSimpleModFunc, itemSimpleReadFunc),
-		Read:   protoItemGetReadWrapper(itemSimpleReadFunc),
-		Update: protoItemGetUpdateWrapper(itemSimpleModFunc, itemSimpleReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemSimpleModFunc, itemSimpleReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemSimpleReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemSimpleModFunc, itemSimpleReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema),
@@ -34,12 +34,13 @@ func resourceProtoItemSimple() *schema.Resource {
 }
 func resourceLLDSimple() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldSimpleModFunc, lldSimpleReadFunc),
-		Read:   lldGetReadWrapper(lldSimpleReadFunc),
-		Update: lldGetUpdateWrapper(lldSimpleModFunc, lldSimpleReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldSimpleModFunc, lldSimpleReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldSimpleReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldSimpleModFunc, lldSimpleReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema),
@@ -3,8 +3,8 @@ package provider
 import (
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -134,24 +134,24 @@ var schemaSnmp = map[string]*schema.Schema{
 // terraform resource handler for item type
 func resourceItemSnmp() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemSnmpModFunc, itemSnmpReadFunc),
-		Read:   itemGetReadWrapper(itemSnmpReadFunc),
-		Update: itemGetUpdateWrapper(itemSnmpModFunc, itemSnmpReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemSnmpModFunc, itemSnmpReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemSnmpReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemSnmpModFunc, itemSnmpReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaSnmp),
 	}
 }
 func resourceProtoItemSnmp() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemSnmpModFunc, itemSnmpReadFunc),
-		Read:   protoItemGetReadWrapper(itemSnmpReadFunc),
-		Update: protoItemGetUpdateWrapper(itemSnmpModFunc, itemSnmpReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemSnmpModFunc, itemSnmpReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemSnmpReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemSnmpModFunc, itemSnmpReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaSnmp),
 	}
@@ -159,12 +159,13 @@ func resourceProtoItemSnmp() *schema.Resource {
 
 func resourceLLDSnmp() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldSnmpModFunc, lldSnmpReadFunc),
-		Read:   lldGetReadWrapper(lldSnmpReadFunc),
-		Update: lldGetUpdateWrapper(lldSnmpModFunc, lldSnmpReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldSnmpModFunc, lldSnmpReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldSnmpReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldSnmpModFunc, lldSnmpReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaSnmp),
 	}
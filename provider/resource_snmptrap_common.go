@@ -1,19 +1,19 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // terraform resource handler for item type
 func resourceItemSnmpTrap() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc),
-		Read:   itemGetReadWrapper(itemSnmpTrapReadFunc),
-		Update: itemGetUpdateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemSnmpTrapReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: itemCommonSchema,
@@ -21,12 +21,12 @@ func resourceItemSnmpTrap() *schema.Resource {
 }
 func resourceProtoItemSnmpTrap() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc),
-		Read:   protoItemGetReadWrapper(itemSnmpTrapReadFunc),
-		Update: protoItemGetUpdateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemSnmpTrapReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema),
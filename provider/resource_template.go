@@ -3,24 +3,29 @@ package provider
 import (
 	"errors"
 	"regexp"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // template resource function
 func resourceTemplate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceTemplateCreate,
-		Read:   resourceTemplateRead,
-		Update: resourceTemplateUpdate,
-		Delete: resourceTemplateDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+		CreateContext: contextCRUD(resourceTemplateCreate),
+		ReadContext:   contextCRUD(resourceTemplateRead),
+		UpdateContext: contextCRUD(resourceTemplateUpdate),
+		DeleteContext: contextCRUD(resourceTemplateDelete),
+		Importer:      importByName(lookupByField("template.get", "templateid", "host")),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 
-		Schema: map[string]*schema.Schema{
+		Schema: mergeSchemas(safeDeleteSchema(), map[string]*schema.Schema{
+			"deletion_protection": deletionProtectionSchema(),
 			"groups": &schema.Schema{
 				Type: schema.TypeSet,
 				Elem: &schema.Schema{
@@ -55,14 +60,15 @@ func resourceTemplate() *schema.Resource {
 				},
 				Description: "linked templates",
 			},
-			"macro": macroListSchema,
-		},
+			"templates_clear": templatesClearSchema(),
+			"macro":           macroListSchema,
+		}),
 	}
 }
 
 func dataTemplate() *schema.Resource {
 	return &schema.Resource{
-		Read: dataTemplateRead,
+		ReadContext: contextCRUD(dataTemplateRead),
 
 		Schema: map[string]*schema.Schema{
 			"groups": &schema.Schema{
@@ -95,34 +101,112 @@ func dataTemplate() *schema.Resource {
 	}
 }
 
+// TEMPLATE_SORT_FIELDS sort_field values of zabbix_templates and the template.get
+// sortfield of each, empty for those it can not sort by
+var TEMPLATE_SORT_FIELDS = map[string]string{
+	"id":   "hostid",
+	"host": "host",
+	"name": "name",
+}
+
+// dataTemplates terraform plural template data handler
+func dataTemplates() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataTemplatesRead),
+
+		Schema: mergeSchemas(pagingSchema(), searchSchema(true), sortSchema(TEMPLATE_SORT_FIELDS), map[string]*schema.Schema{
+			"groups": &schema.Schema{
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be a numeric string"),
+				},
+				Optional:    true,
+				Description: "Template Group IDs to filter on",
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Template Display Name to filter on, * may be used as a wildcard",
+			},
+			"ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "Matching Template IDs",
+			},
+			"templates": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching Templates",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Template ID",
+						},
+						"host": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Template hostname (internal name)",
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Template Display Name",
+						},
+					},
+				},
+			},
+		}),
+	}
+}
+
 // terraform resource create handler
 func resourceTemplateCreate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
 	item := buildTemplateObject(d)
-	items := []zabbix.Template{*item}
+	params, err := templateParams(d, api, item)
+
+	if err != nil {
+		return err
+	}
 
-	err := api.TemplatesCreate(items)
+	var response zabbix.Response
+	err = retryTransientCreate(api, d.Timeout(schema.TimeoutCreate), func(api *zabbix.API) (err error) {
+		response, err = api.CallWithError("template.create", params)
+		return
+	})
 
 	if err != nil {
 		return err
 	}
 
-	log.Trace("crated template: %+v", items[0])
+	result := response.Result.(map[string]interface{})
+	item.TemplateID = result["templateids"].([]interface{})[0].(string)
+
+	log.Trace("created template: %s", item.TemplateID)
 
-	d.SetId(items[0].TemplateID)
+	d.SetId(item.TemplateID)
 
-	return resourceTemplateRead(d, m)
+	return readAfterWrite(d, m, resourceTemplate().Schema, resourceTemplateRead)
 }
 
+// TEMPLATE_OUTPUT template fields read back
+var TEMPLATE_OUTPUT = []string{"templateid", "host", "name", "description"}
+
 // terraform template read handler (data source)
 func dataTemplateRead(d *schema.ResourceData, m interface{}) error {
 
 	params := zabbix.Params{
+		"output":                TEMPLATE_OUTPUT,
 		"filter":                map[string]interface{}{},
-		"selectMacros":          "extend",
-		"selectParentTemplates": "extend",
-		"selectGroups":          "extend",
+		"selectParentTemplates": []string{"templateid"},
+		"selectGroups":          []string{"groupid"},
+		"selectMacros":          macroOutput(m.(*zabbix.API)),
 	}
 
 	if v := d.Get("host").(string); v != "" {
@@ -141,23 +225,84 @@ func dataTemplateRead(d *schema.ResourceData, m interface{}) error {
 	return templateRead(d, m, params)
 }
 
+// terraform plural template read handler (data source)
+func dataTemplatesRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"output": []string{"templateid", "host", "name"},
+	}
+
+	if v := d.Get("groups").(*schema.Set); v.Len() > 0 {
+		params["groupids"] = v.List()
+	}
+
+	if v := d.Get("name").(string); v != "" {
+		params["search"] = map[string]interface{}{
+			"name": v,
+		}
+	}
+	applySearch(d, params)
+	log.Debug("Lookup of templates with: %#v", params)
+
+	ids, list, err := pagedGet(d, "templateid", TEMPLATE_SORT_FIELDS, params, func(params zabbix.Params) ([]map[string]interface{}, error) {
+		templates, err := api.TemplatesGet(params)
+		entries := make([]map[string]interface{}, len(templates))
+		for i, t := range templates {
+			entries[i] = map[string]interface{}{
+				"id":   t.TemplateID,
+				"host": t.Host,
+				"name": t.Name,
+			}
+		}
+		return entries, err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(dataListID(params))
+	d.Set("ids", ids)
+	d.Set("templates", list)
+
+	return nil
+}
+
 // terraform template read handler (resource)
 func resourceTemplateRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of template with id %s", d.Id())
 
 	return templateRead(d, m, zabbix.Params{
+		"output":                TEMPLATE_OUTPUT,
 		"templateids":           d.Id(),
-		"selectMacros":          "extend",
-		"selectParentTemplates": "extend",
-		"selectGroups":          "extend",
+		"selectParentTemplates": []string{"templateid"},
+		"selectGroups":          []string{"groupid"},
+		"selectMacros":          macroOutput(m.(*zabbix.API)),
 	})
 }
 
+// templateObject template read back along with the macro types zabbix.Template lacks
+type templateObject struct {
+	zabbix.Template
+
+	Macros []UserMacro `json:"macros"`
+}
+
+// templatesGet template.get into templateObjects
+func templatesGet(api *zabbix.API, params zabbix.Params) (res []templateObject, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("template.get", params, &res)
+	return
+}
+
 // generic template read function
 func templateRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
 	api := m.(*zabbix.API)
 
-	templates, err := api.TemplatesGet(params)
+	templates, err := templatesGet(api, params)
 
 	if err != nil {
 		return err
@@ -172,12 +317,12 @@ func templateRead(d *schema.ResourceData, m interface{}, params zabbix.Params) e
 	}
 	t := templates[0]
 
-	log.Debug("Got template: %+v", t)
+	log.Debug("Got template: %s", t.TemplateID)
 
 	d.Set("description", t.Description)
 	d.Set("host", t.Host)
 	d.Set("name", t.Name)
-	d.Set("macro", flattenMacros(t.UserMacros))
+	d.Set("macro", flattenMacros(t.Macros, d))
 	d.Set("groups", flattenHostGroupIds(t.Groups))
 	d.Set("templates", flattenTemplateIds(t.ParentTemplates))
 	d.SetId(t.TemplateID)
@@ -194,11 +339,20 @@ func buildTemplateObject(d *schema.ResourceData) *zabbix.Template {
 		Groups:          buildHostGroupIds(d.Get("groups").(*schema.Set)),
 		LinkedTemplates: buildTemplateIds(d.Get("templates").(*schema.Set)),
 	}
-
-	item.UserMacros = macroGenerate(d)
 	return &item
 }
 
+// templateParams request params of a template with its typed macros
+func templateParams(d *schema.ResourceData, api *zabbix.API, template *zabbix.Template) (map[string]interface{}, error) {
+	macros, err := macroGenerate(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return withMacros(api, template, macros)
+}
+
 // terraform update resource handler
 func resourceTemplateUpdate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
@@ -206,30 +360,38 @@ func resourceTemplateUpdate(d *schema.ResourceData, m interface{}) error {
 	item := buildTemplateObject(d)
 	item.TemplateID = d.Id()
 
-	// templates may need a bit extra effort
-	if d.HasChange("templates") {
-		old, new := d.GetChange("templates")
-		diff := old.(*schema.Set).Difference(new.(*schema.Set))
+	// removed templates are unlinked, and cleared unless disabled
+	item.TemplatesClear = buildTemplateIdsClear(d)
 
-		// removals, we need to unlink and clear
-		if diff.Len() > 0 {
-			item.TemplatesClear = buildTemplateIds(diff)
-		}
-	}
+	params, err := templateParams(d, api, item)
 
-	items := []zabbix.Template{*item}
+	if err != nil {
+		return err
+	}
 
-	err := api.TemplatesUpdate(items)
+	err = retryTransient(api, d.Timeout(schema.TimeoutUpdate), func(api *zabbix.API) error {
+		_, err := api.CallWithError("template.update", params)
+		return err
+	})
 
 	if err != nil {
 		return err
 	}
 
-	return resourceTemplateRead(d, m)
+	return readAfterWrite(d, m, resourceTemplate().Schema, resourceTemplateRead)
 }
 
 // terraform delete handler
 func resourceTemplateDelete(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
-	return api.TemplatesDeleteByIds([]string{d.Id()})
+
+	if err := checkDeletionProtection(d, "template"); err != nil {
+		return err
+	}
+	if err := checkSafeDelete(d, api, "template", templateDependents(d.Id())); err != nil {
+		return err
+	}
+	return retryTransient(api, d.Timeout(schema.TimeoutDelete), func(api *zabbix.API) error {
+		return api.TemplatesDeleteByIds([]string{d.Id()})
+	})
 }
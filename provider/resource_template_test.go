@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceTemplateMacros(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	groupID := server.Seed("hostgroup", map[string]interface{}{"name": "tf-unit-group"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceTemplateMacros(server.APIURL(), groupID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_template.test", "macro.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_template.test", "macro.0.value", "tf-unit-value"),
+					resource.TestCheckResourceAttr("zabbix_template.test", "macro.1.value", ""),
+					resource.TestCheckResourceAttr("zabbix_template.test", "macro.1.secret_value", "tf-unit-secret"),
+					func(*terraform.State) error {
+						// macros come with the template.get of the read
+						if calls := server.Calls("usermacro.get"); len(calls) != 0 {
+							return fmt.Errorf("expected no usermacro.get call, got %d", len(calls))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceTemplateMacros(url, groupID string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_template" "test" {
+	host   = "tf-unit-template"
+	groups = [%q]
+	macro {
+		name  = "{$TF_UNIT}"
+		value = "tf-unit-value"
+	}
+	macro {
+		name         = "{$TF_UNIT_SECRET}"
+		type         = "secret"
+		secret_value = "tf-unit-secret"
+	}
+}
+`, testUnitProviderConfig(url), groupID)
+}
@@ -0,0 +1,321 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// TEMPLATE_YAML_RULES configuration.import rules of objects on a template, all of
+// them are created, updated and, with delete_missing, removed
+var TEMPLATE_YAML_RULES = []string{
+	"discoveryRules",
+	"graphs",
+	"httptests",
+	"items",
+	"templateDashboards",
+	"triggers",
+	"valueMaps",
+}
+
+// TEMPLATE_YAML_VOLATILE keys of the export that change without the template
+// changing, left out of the drift comparison
+var TEMPLATE_YAML_VOLATILE = []string{"version", "date"}
+
+// resourceTemplateYAML terraform resource handler
+func resourceTemplateYAML() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceTemplateYAMLImport),
+		ReadContext:   contextCRUD(resourceTemplateYAMLRead),
+		UpdateContext: contextCRUD(resourceTemplateYAMLImport),
+		DeleteContext: contextCRUD(resourceTemplateYAMLDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"document": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateTemplateYAML,
+				Description:  "Zabbix YAML export holding exactly one template, imported on apply and compared to an export on refresh",
+			},
+			"delete_missing": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Delete items, triggers and other template objects missing from the document, and unlink templates it does not link",
+			},
+			"host": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Technical name of the template",
+			},
+		},
+	}
+}
+
+// validateTemplateYAML check the document parses and holds a single template
+func validateTemplateYAML(v interface{}, k string) (ws []string, es []error) {
+	doc, err := parseYAMLDocument(v.(string))
+	if err == nil {
+		_, err = templateYAMLHost(doc)
+	}
+	if err != nil {
+		es = append(es, fmt.Errorf("%q: %s", k, err))
+	}
+	return
+}
+
+// parseYAMLDocument decode a yaml document into plain maps and lists, scalars
+// as strings since exports quote values a config may leave bare
+func parseYAMLDocument(src string) (map[string]interface{}, error) {
+	ty, err := ctyyaml.Standard.ImpliedType([]byte(src))
+	if err != nil {
+		return nil, err
+	}
+	v, err := ctyyaml.Standard.Unmarshal([]byte(src), ty)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ctyjson.Marshal(v, ty)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	m, ok := stringifyScalars(doc).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("document is not a mapping")
+	}
+	return m, nil
+}
+
+// stringifyScalars replace numbers and booleans by their string form
+func stringifyScalars(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = stringifyScalars(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringifyScalars(e)
+		}
+		return v
+	case nil, string:
+		return v
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// templateYAMLHost technical name of the single template of a document
+func templateYAMLHost(doc map[string]interface{}) (string, error) {
+	export, _ := doc["zabbix_export"].(map[string]interface{})
+	templates, _ := export["templates"].([]interface{})
+	if len(templates) != 1 {
+		return "", fmt.Errorf("document has to hold exactly one template, found %d", len(templates))
+	}
+	template, _ := templates[0].(map[string]interface{})
+	host, _ := template["template"].(string)
+	if host == "" {
+		return "", errors.New("template without a template name")
+	}
+	return host, nil
+}
+
+// templateYAMLRules import rules, template groups were split from host groups in 6.2
+func templateYAMLRules(api *zabbix.API, deleteMissing bool) map[string]interface{} {
+	rules := map[string]interface{}{
+		"templates": map[string]interface{}{
+			"createMissing":  true,
+			"updateExisting": true,
+		},
+		"templateLinkage": map[string]interface{}{
+			"createMissing": true,
+			"deleteMissing": deleteMissing,
+		},
+	}
+	if api.Config.Version >= 60200 {
+		rules["template_groups"] = map[string]interface{}{
+			"createMissing":  true,
+			"updateExisting": true,
+		}
+	} else {
+		rules["groups"] = map[string]interface{}{
+			"createMissing": true,
+		}
+	}
+	for _, r := range TEMPLATE_YAML_RULES {
+		rules[r] = map[string]interface{}{
+			"createMissing":  true,
+			"updateExisting": true,
+			"deleteMissing":  deleteMissing,
+		}
+	}
+	return rules
+}
+
+// resourceTemplateYAMLImport create and update handler, importing the document
+func resourceTemplateYAMLImport(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	// template level value maps and templateLinkage.deleteMissing came with 5.4
+	if err := requireVersion(api, 50400, "zabbix_template_yaml"); err != nil {
+		return err
+	}
+
+	document := d.Get("document").(string)
+	doc, err := parseYAMLDocument(document)
+	if err != nil {
+		return err
+	}
+	host, err := templateYAMLHost(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = api.CallWithError("configuration.import", zabbix.Params{
+		"format": "yaml",
+		"source": document,
+		"rules":  templateYAMLRules(api, d.Get("delete_missing").(bool)),
+	})
+	if err != nil {
+		return err
+	}
+
+	ids, err := lookupByField("template.get", "templateid", "host")(api, host)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("template %q not found after import", host)
+	}
+	log.Debug("Imported template %s as %s", host, ids[0])
+	d.SetId(ids[0])
+
+	return resourceTemplateYAMLRead(d, m)
+}
+
+// resourceTemplateYAMLRead export the template, the document is replaced by the
+// export when the template no longer matches it
+func resourceTemplateYAMLRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	templates, err := api.TemplatesGet(zabbix.Params{
+		"output":      []string{"templateid", "host"},
+		"templateids": d.Id(),
+	})
+	if err != nil {
+		return err
+	}
+	if len(templates) < 1 {
+		d.SetId("")
+		return nil
+	}
+	d.Set("host", templates[0].Host)
+
+	var exported string
+	err = api.CallWithErrorParse("configuration.export", zabbix.Params{
+		"format": "yaml",
+		"options": map[string]interface{}{
+			"templates": []string{d.Id()},
+		},
+	}, &exported)
+	if err != nil {
+		return err
+	}
+
+	configured := d.Get("document").(string)
+	if configured != "" {
+		inSync, err := templateYAMLInSync(configured, exported)
+		if err != nil {
+			return err
+		}
+		if inSync {
+			return nil
+		}
+		log.Debug("Template %s drifted from its document", d.Id())
+	}
+	d.Set("document", exported)
+
+	return nil
+}
+
+// templateYAMLInSync whether the export holds everything the configured document
+// sets, values the document leaves out are defaults the export may add
+func templateYAMLInSync(configured, exported string) (bool, error) {
+	want, err := parseYAMLDocument(configured)
+	if err != nil {
+		return false, err
+	}
+	have, err := parseYAMLDocument(exported)
+	if err != nil {
+		return false, err
+	}
+
+	for _, doc := range []map[string]interface{}{want, have} {
+		if export, ok := doc["zabbix_export"].(map[string]interface{}); ok {
+			for _, k := range TEMPLATE_YAML_VOLATILE {
+				delete(export, k)
+			}
+		}
+	}
+	return yamlSubset(want, have), nil
+}
+
+// yamlSubset whether have holds all of want, lists match regardless of order
+// as exports sort their entries
+func yamlSubset(want, have interface{}) bool {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		h, ok := have.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !yamlSubset(v, h[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		h, _ := have.([]interface{})
+		if len(h) != len(w) {
+			return false
+		}
+		used := make([]bool, len(h))
+		for _, v := range w {
+			found := false
+			for i, e := range h {
+				if !used[i] && yamlSubset(v, e) {
+					used[i], found = true, true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(want, have)
+}
+
+// resourceTemplateYAMLDelete terraform resource delete handler
+func resourceTemplateYAMLDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	return retryTransient(api, d.Timeout(schema.TimeoutDelete), func(api *zabbix.API) error {
+		return api.TemplatesDeleteByIds([]string{d.Id()})
+	})
+}
@@ -0,0 +1,79 @@
+package provider
+
+import "testing"
+
+func TestUnitTemplateYAMLInSync(t *testing.T) {
+	configured := `
+zabbix_export:
+  version: '6.4'
+  templates:
+    - template: tf-acc-yaml
+      name: tf-acc-yaml
+      items:
+        - name: Ping
+          key: agent.ping
+        - name: Uptime
+          key: system.uptime
+          history: 7d
+`
+	cases := map[string]struct {
+		exported string
+		inSync   bool
+	}{
+		"export with defaults and uuids": {`
+zabbix_export:
+  version: '7.0'
+  templates:
+    - uuid: 0a1b
+      template: tf-acc-yaml
+      name: tf-acc-yaml
+      items:
+        - uuid: 2c3d
+          name: Uptime
+          key: system.uptime
+          history: 7d
+          delay: '60'
+        - uuid: 4e5f
+          name: Ping
+          key: agent.ping
+`, true},
+		"changed value": {`
+zabbix_export:
+  templates:
+    - template: tf-acc-yaml
+      name: tf-acc-yaml
+      items:
+        - name: Ping
+          key: agent.ping
+        - name: Uptime
+          key: system.uptime
+          history: 14d
+`, false},
+		"extra item": {`
+zabbix_export:
+  templates:
+    - template: tf-acc-yaml
+      name: tf-acc-yaml
+      items:
+        - name: Ping
+          key: agent.ping
+        - name: Uptime
+          key: system.uptime
+          history: 7d
+        - name: Load
+          key: system.cpu.load
+`, false},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			inSync, err := templateYAMLInSync(configured, c.exported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inSync != c.inSync {
+				t.Errorf("expected in sync %v, got %v", c.inSync, inSync)
+			}
+		})
+	}
+}
@@ -1,19 +1,19 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // terraform resource handler for item type
 func resourceItemTrapper() *schema.Resource {
 	return &schema.Resource{
-		Create: itemGetCreateWrapper(itemTrapperModFunc, itemTrapperReadFunc),
-		Read:   itemGetReadWrapper(itemTrapperReadFunc),
-		Update: itemGetUpdateWrapper(itemTrapperModFunc, itemTrapperReadFunc),
-		Delete: resourceItemDelete,
+		CreateContext: contextCRUD(itemGetCreateWrapper(itemTrapperModFunc, itemTrapperReadFunc)),
+		ReadContext:   contextCRUD(itemGetReadWrapper(itemTrapperReadFunc)),
+		UpdateContext: contextCRUD(itemGetUpdateWrapper(itemTrapperModFunc, itemTrapperReadFunc)),
+		DeleteContext: contextCRUD(resourceItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: itemCommonSchema,
@@ -21,12 +21,12 @@ func resourceItemTrapper() *schema.Resource {
 }
 func resourceProtoItemTrapper() *schema.Resource {
 	return &schema.Resource{
-		Create: protoItemGetCreateWrapper(itemTrapperModFunc, itemTrapperReadFunc),
-		Read:   protoItemGetReadWrapper(itemTrapperReadFunc),
-		Update: protoItemGetUpdateWrapper(itemTrapperModFunc, itemTrapperReadFunc),
-		Delete: resourceProtoItemDelete,
+		CreateContext: contextCRUD(protoItemGetCreateWrapper(itemTrapperModFunc, itemTrapperReadFunc)),
+		ReadContext:   contextCRUD(protoItemGetReadWrapper(itemTrapperReadFunc)),
+		UpdateContext: contextCRUD(protoItemGetUpdateWrapper(itemTrapperModFunc, itemTrapperReadFunc)),
+		DeleteContext: contextCRUD(resourceProtoItemDelete),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema),
@@ -34,12 +34,13 @@ func resourceProtoItemTrapper() *schema.Resource {
 }
 func resourceLLDTrapper() *schema.Resource {
 	return &schema.Resource{
-		Create: lldGetCreateWrapper(lldTrapperModFunc, lldTrapperReadFunc),
-		Read:   lldGetReadWrapper(lldTrapperReadFunc),
-		Update: lldGetUpdateWrapper(lldTrapperModFunc, lldTrapperReadFunc),
-		Delete: resourceLLDDelete,
+		CreateContext: contextCRUD(lldGetCreateWrapper(lldTrapperModFunc, lldTrapperReadFunc)),
+		ReadContext:   contextCRUD(lldGetReadWrapper(lldTrapperReadFunc)),
+		UpdateContext: contextCRUD(lldGetUpdateWrapper(lldTrapperModFunc, lldTrapperReadFunc)),
+		DeleteContext: contextCRUD(resourceLLDDelete),
+		CustomizeDiff: lldCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: lldCommonSchema,
@@ -1,12 +1,15 @@
 package provider
 
 import (
+	"context"
+
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -21,15 +24,57 @@ var TRIGGER_PRIORITY = map[string]zabbix.SeverityType{
 var TRIGGER_PRIORITY_REV = map[zabbix.SeverityType]string{}
 var TRIGGER_PRIORITY_ARR = []string{}
 
+var TRIGGER_RECOVERY_MODE = map[string]int{
+	"expression":          0,
+	"recovery_expression": 1,
+	"none":                2,
+}
+var TRIGGER_RECOVERY_MODE_REV = map[int]string{}
+
+var TRIGGER_CORRELATION_MODE = map[string]int{
+	"all": 0,
+	"tag": 1,
+}
+var TRIGGER_CORRELATION_MODE_REV = map[int]string{}
+
 // generate the above structures
 var _ = func() bool {
 	for k, v := range TRIGGER_PRIORITY {
 		TRIGGER_PRIORITY_REV[v] = k
 		TRIGGER_PRIORITY_ARR = append(TRIGGER_PRIORITY_ARR, k)
 	}
+	for k, v := range TRIGGER_RECOVERY_MODE {
+		TRIGGER_RECOVERY_MODE_REV[v] = k
+	}
+	for k, v := range TRIGGER_CORRELATION_MODE {
+		TRIGGER_CORRELATION_MODE_REV[v] = k
+	}
 	return false
 }()
 
+// trigger references, a trigger id or the technical host name and the trigger
+// name separated by the first colon, host names cannot contain one
+var triggerReference = regexp.MustCompile(`^([0-9]+|[^:]+:.+)$`)
+
+// triggerObject trigger including the fields missing from the api library
+type triggerObject struct {
+	zabbix.Trigger
+	Opdata    string `json:"opdata"`
+	EventName string `json:"event_name"`
+	UrlName   string `json:"url_name"`
+}
+
+// triggerFieldVersions api version the optional trigger fields were introduced in
+var triggerFieldVersions = []struct {
+	Field string
+	Key   string
+	Since int
+}{
+	{"opdata", "opdata", 40400},
+	{"event_name", "event_name", 50200},
+	{"url_name", "url_name", 60400},
+}
+
 var schemaTrigger = map[string]*schema.Schema{
 	// api "description", gui rewrites to name, so shall we
 	"name": &schema.Schema{
@@ -74,16 +119,47 @@ var schemaTrigger = map[string]*schema.Schema{
 		Description:  "link to url relevent to trigger",
 		ValidateFunc: validation.IsURLWithHTTPorHTTPS,
 	},
+	"url_name": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "label of the url, zabbix 6.4+",
+	},
+	"opdata": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "operational data shown with the problem, e.g. Current: {ITEM.LASTVALUE1}",
+	},
+	"event_name": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "name of the generated problem events, defaults to the trigger name, zabbix 5.2+",
+	},
+	"recovery_mode": &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "OK event generation, one of: expression, recovery_expression, none; derived from recovery_expression and recovery_none if unset",
+		ValidateFunc:     validateEnum(TRIGGER_RECOVERY_MODE),
+		DiffSuppressFunc: suppressEnumDiff(TRIGGER_RECOVERY_MODE),
+		ConflictsWith:    []string{"recovery_none"},
+	},
 	"recovery_none": &schema.Schema{
 		Type:        schema.TypeBool,
 		Optional:    true,
 		Default:     false,
 		Description: "set recovery mode to none",
+		Deprecated:  "use recovery_mode = \"none\"",
 	},
 	"recovery_expression": &schema.Schema{
 		Type:        schema.TypeString,
 		Optional:    true,
-		Description: "use recovery expression (recovery_none must not be true)",
+		Description: "use recovery expression (recovery_mode must be recovery_expression or unset)",
+	},
+	"correlation_mode": &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "OK event closes, one of: all (problems), tag (problems with a matching correlation_tag value); derived from correlation_tag if unset",
+		ValidateFunc:     validateEnum(TRIGGER_CORRELATION_MODE),
+		DiffSuppressFunc: suppressEnumDiff(TRIGGER_CORRELATION_MODE),
 	},
 	"correlation_tag": &schema.Schema{
 		Type:        schema.TypeString,
@@ -105,57 +181,143 @@ var schemaTrigger = map[string]*schema.Schema{
 		},
 		Description: "Trigger Dependencies",
 	},
-	"tag": &schema.Schema{
-		Type:     schema.TypeSet,
+	"depends_on_triggers": &schema.Schema{
+		Type:     schema.TypeList,
 		Optional: true,
-		Elem: &schema.Resource{
-			Schema: map[string]*schema.Schema{
-				"key": &schema.Schema{
-					Type:         schema.TypeString,
-					Required:     true,
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Description:  "Tag Key",
-				},
-				"value": &schema.Schema{
-					Type:        schema.TypeString,
-					Optional:    true,
-					Description: "Tag Value",
-				},
-			},
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringMatch(triggerReference, "must be a trigger id or host:trigger name"),
 		},
+		Description:   "Trigger Dependencies, by trigger id or as host:trigger name",
+		ConflictsWith: []string{"dependencies"},
 	},
+	"tag": schemaTags(),
 }
 
 // terraform resource handler for triggers
 func resourceTrigger() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceTriggerCreate(false),
-		Read:   resourceTriggerRead(false),
-		Update: resourceTriggerUpdate(false),
-		Delete: resourceTriggerDelete(false),
+		CreateContext: contextCRUD(resourceTriggerCreate(false)),
+		ReadContext:   contextCRUD(resourceTriggerRead(false)),
+		UpdateContext: contextCRUD(resourceTriggerUpdate(false)),
+		DeleteContext: contextCRUD(resourceTriggerDelete(false)),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Schema: schemaTrigger,
+		Schema:        schemaTrigger,
+		CustomizeDiff: triggerCustomizeDiff,
 	}
 }
 func resourceProtoTrigger() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceTriggerCreate(true),
-		Read:   resourceTriggerRead(true),
-		Update: resourceTriggerUpdate(true),
-		Delete: resourceTriggerDelete(true),
+		CreateContext: contextCRUD(resourceTriggerCreate(true)),
+		ReadContext:   contextCRUD(resourceTriggerRead(true)),
+		UpdateContext: contextCRUD(resourceTriggerUpdate(true)),
+		DeleteContext: contextCRUD(resourceTriggerDelete(true)),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Schema: schemaTrigger,
+		Schema:        schemaTrigger,
+		CustomizeDiff: triggerCustomizeDiff,
+	}
+}
+
+// dataTrigger terraform trigger data handler
+func dataTrigger() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataTriggerRead),
+
+		Schema: map[string]*schema.Schema{
+			"host": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Technical name of the host or template the trigger belongs to",
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Trigger name",
+			},
+			"expression": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Trigger Expression, narrows the lookup if set",
+			},
+			"priority": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Trigger Priority level",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Trigger is enabled",
+			},
+			"tag": dataTagsSchema(),
+		},
+	}
+}
+
+// triggerRecoveryMode recovery mode as configured, or derived from the older fields
+func triggerRecoveryMode(d resourceGetter) int {
+	if v := d.Get("recovery_mode").(string); v != "" {
+		return enumValue(v, TRIGGER_RECOVERY_MODE)
+	}
+	if d.Get("recovery_none").(bool) {
+		return 2
+	}
+	if d.Get("recovery_expression").(string) != "" {
+		return 1
+	}
+	return 0
+}
+
+// triggerCorrelationMode correlation mode as configured, or derived from correlation_tag
+func triggerCorrelationMode(d resourceGetter) int {
+	if v := d.Get("correlation_mode").(string); v != "" {
+		return enumValue(v, TRIGGER_CORRELATION_MODE)
+	}
+	if d.Get("correlation_tag").(string) != "" {
+		return 1
+	}
+	return 0
+}
+
+// triggerCustomizeDiff check the recovery and correlation settings fit together
+func triggerCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	recovery := triggerRecoveryMode(d)
+	expression := d.Get("recovery_expression").(string)
+
+	if recovery == 1 && expression == "" {
+		return errors.New("recovery_expression is required when recovery_mode is recovery_expression")
+	}
+	if recovery != 1 && expression != "" {
+		return errors.New("recovery_expression is only used when recovery_mode is recovery_expression")
+	}
+
+	correlation := triggerCorrelationMode(d)
+	tag := d.Get("correlation_tag").(string)
+
+	if correlation == 1 && tag == "" {
+		return errors.New("correlation_tag is required when correlation_mode is tag")
+	}
+	if correlation != 1 && tag != "" {
+		return errors.New("correlation_tag is only used when correlation_mode is tag")
+	}
+	if correlation == 1 && recovery == 2 {
+		return errors.New("correlation_mode tag needs OK events, recovery_mode must not be none")
 	}
+
+	return nil
 }
 
 // Build Trigger struct for create/modify
-func buildTriggerObject(d *schema.ResourceData) zabbix.Trigger {
+func buildTriggerObject(d *schema.ResourceData) triggerObject {
 	item := zabbix.Trigger{
 		Description:        d.Get("name").(string),
 		Expression:         d.Get("expression").(string),
@@ -178,79 +340,210 @@ func buildTriggerObject(d *schema.ResourceData) zabbix.Trigger {
 		item.Type = 1
 	}
 
-	if d.Get("recovery_none").(bool) {
-		item.RecoveryMode = 2
-	} else if v := d.Get("recovery_expression").(string); v != "" {
-		item.RecoveryMode = 1
-		item.RecoveryExpression = v
+	item.RecoveryMode = triggerRecoveryMode(d)
+	if item.RecoveryMode == 1 {
+		item.RecoveryExpression = d.Get("recovery_expression").(string)
 	}
 
-	if v := d.Get("correlation_tag").(string); v != "" {
-		item.CorrelationMode = 1
-		item.CorrelationTag = v
+	item.CorrelationMode = triggerCorrelationMode(d)
+	if item.CorrelationMode == 1 {
+		item.CorrelationTag = d.Get("correlation_tag").(string)
 	}
 
 	if d.Get("manual_close").(bool) {
 		item.ManualClose = 1
 	}
 
-	item.Dependencies = buildTriggerIds(d.Get("dependencies").(*schema.Set))
 	item.Tags = tagGenerate(d)
 
-	return item
+	return triggerObject{
+		Trigger:   item,
+		Opdata:    d.Get("opdata").(string),
+		EventName: d.Get("event_name").(string),
+		UrlName:   d.Get("url_name").(string),
+	}
+}
+
+// triggerParams request params for a trigger, leaving out the fields the
+// connected server does not know yet
+func triggerParams(api *zabbix.API, item triggerObject) (map[string]interface{}, error) {
+	params, err := versionedParams(api, item, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range triggerFieldVersions {
+		if api.Config.Version >= f.Since {
+			continue
+		}
+		if params[f.Key] != "" {
+			if err := requireVersion(api, f.Since, f.Field); err != nil {
+				return nil, err
+			}
+		}
+		delete(params, f.Key)
+	}
+
+	return params, nil
+}
+
+// resolveTriggerReference look up the id of a trigger referenced as host:name,
+// prototypes may also depend on trigger prototypes of the same host
+func resolveTriggerReference(api *zabbix.API, prototype bool, ref string) (string, error) {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) == 1 {
+		return ref, nil
+	}
+
+	methods := []string{"trigger.get"}
+	if prototype {
+		methods = []string{"triggerprototype.get", "trigger.get"}
+	}
+
+	for _, method := range methods {
+		var triggers []triggerObject
+		err := api.CallWithErrorParse(method, zabbix.Params{
+			"output": []string{"triggerid"},
+			"host":   parts[0],
+			"filter": map[string]interface{}{
+				"description": parts[1],
+			},
+		}, &triggers)
+		if err != nil {
+			return "", err
+		}
+
+		if len(triggers) > 1 {
+			return "", fmt.Errorf("multiple triggers %q found on host %q, reference it by id", parts[1], parts[0])
+		}
+		if len(triggers) == 1 {
+			return triggers[0].TriggerID, nil
+		}
+	}
+
+	return "", fmt.Errorf("trigger %q not found on host %q", parts[1], parts[0])
+}
+
+// resolveTriggerDependencies trigger ids of depends_on_triggers, in order
+func resolveTriggerDependencies(api *zabbix.API, prototype bool, d *schema.ResourceData) ([]string, error) {
+	refs := d.Get("depends_on_triggers").([]interface{})
+	ids := make([]string, len(refs))
+
+	for i, ref := range refs {
+		id, err := resolveTriggerReference(api, prototype, ref.(string))
+		if err != nil {
+			return nil, fmt.Errorf("depends_on_triggers.%d: %s", i, err)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// buildTriggerDependencies dependency ids of either dependencies or depends_on_triggers
+func buildTriggerDependencies(api *zabbix.API, prototype bool, d *schema.ResourceData) (zabbix.TriggerIDs, error) {
+	ids, err := resolveTriggerDependencies(api, prototype, d)
+	if err != nil || len(ids) == 0 {
+		return buildTriggerIds(d.Get("dependencies").(*schema.Set)), err
+	}
+
+	deps := make(zabbix.TriggerIDs, len(ids))
+	for i, id := range ids {
+		deps[i] = zabbix.TriggerID{TriggerID: id}
+	}
+	return deps, nil
+}
+
+// flattenTriggerDependencies keep the configured references that are still
+// dependencies, and list any other dependency by id so the drift shows up
+func flattenTriggerDependencies(api *zabbix.API, prototype bool, d *schema.ResourceData, deps zabbix.TriggerIDs) []interface{} {
+	current := map[string]bool{}
+	for _, v := range deps {
+		current[v.TriggerID] = true
+	}
+
+	list := []interface{}{}
+	for _, ref := range d.Get("depends_on_triggers").([]interface{}) {
+		// a reference that no longer resolves is dropped, the next apply reports why
+		id, err := resolveTriggerReference(api, prototype, ref.(string))
+		if err != nil {
+			log.Debug("dropping trigger dependency %s: %s", ref, err)
+			continue
+		}
+		if current[id] {
+			list = append(list, ref)
+			delete(current, id)
+		}
+	}
+	for _, v := range deps {
+		if current[v.TriggerID] {
+			list = append(list, v.TriggerID)
+		}
+	}
+
+	return list
+}
+
+// triggerMethod api method of triggers or trigger prototypes
+func triggerMethod(prototype bool, method string) string {
+	if prototype {
+		return "triggerprototype." + method
+	}
+	return "trigger." + method
 }
 
 // create trigger terraform handler
-func resourceTriggerCreate(prototype bool) schema.CreateFunc {
+func resourceTriggerCreate(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
 		item := buildTriggerObject(d)
 
-		items := []zabbix.Trigger{item}
+		deps, err := buildTriggerDependencies(api, prototype, d)
+		if err != nil {
+			return err
+		}
+		item.Dependencies = deps
 
-		var err error
-		if prototype {
-			err = api.ProtoTriggersCreate(items)
-		} else {
-			err = api.TriggersCreate(items)
+		params, err := triggerParams(api, item)
+		if err != nil {
+			return err
 		}
+		delete(params, "triggerid")
 
+		response, err := api.CallWithError(triggerMethod(prototype, "create"), params)
 		if err != nil {
 			return err
 		}
 
-		log.Trace("crated trigger: %+v", items[0])
+		result := response.Result.(map[string]interface{})
+		item.TriggerID = result["triggerids"].([]interface{})[0].(string)
+
+		log.Trace("created trigger: %s", item.TriggerID)
 
-		d.SetId(items[0].TriggerID)
+		d.SetId(item.TriggerID)
 
 		return resourceTriggerRead(prototype)(d, m)
 	}
 }
 
 // read tirgger terraform handler
-func resourceTriggerRead(prototype bool) schema.ReadFunc {
+func resourceTriggerRead(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
 		log.Debug("Lookup of trigger with id %s", d.Id())
 
 		params := zabbix.Params{
+			"output":             "extend",
 			"triggerids":         d.Id(),
 			"expandExpression":   "extend",
-			"selectDependencies": "extend",
-			"selectTags":         "extend",
-		}
-
-		var triggers zabbix.Triggers
-		var err error
-
-		if prototype {
-			triggers, err = api.ProtoTriggersGet(params)
-		} else {
-			triggers, err = api.TriggersGet(params)
+			"selectDependencies": []string{"triggerid"},
+			"selectTags":         []string{"tag", "value"},
 		}
 
+		var triggers []triggerObject
+		err := api.CallWithErrorParse(triggerMethod(prototype, "get"), params, &triggers)
 		if err != nil {
 			return err
 		}
@@ -264,7 +557,7 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 		}
 		t := triggers[0]
 
-		log.Debug("Got trigger: %+v", t)
+		log.Debug("Got trigger: %s", t.TriggerID)
 
 		d.Set("name", t.Description)
 		d.Set("expression", t.Expression)
@@ -273,6 +566,9 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 		d.Set("enabled", t.Status == 0)
 		d.Set("multiple", t.Type == 1)
 		d.Set("url", t.Url)
+		d.Set("url_name", t.UrlName)
+		d.Set("opdata", t.Opdata)
+		d.Set("event_name", t.EventName)
 		d.Set("recovery_expression", t.RecoveryExpression)
 		d.Set("correlation_tag", t.CorrelationTag)
 		d.Set("manual_close", t.ManualClose == 1)
@@ -284,6 +580,15 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 			d.Set("recovery_none", false)
 		}
 
+		// the modes are derived from the other fields unless configured explicitly
+		if d.Get("recovery_mode").(string) != "" {
+			setEnum(d, "recovery_mode", t.RecoveryMode, TRIGGER_RECOVERY_MODE, TRIGGER_RECOVERY_MODE_REV)
+			d.Set("recovery_none", false)
+		}
+		if d.Get("correlation_mode").(string) != "" {
+			setEnum(d, "correlation_mode", t.CorrelationMode, TRIGGER_CORRELATION_MODE, TRIGGER_CORRELATION_MODE_REV)
+		}
+
 		// should not occur, but need to express somehow, in a way that allows cleanup
 		if t.RecoveryMode == 1 && t.RecoveryExpression == "" {
 			// this should trigger a mismatch, and by setting to 0 len str it should flip recovery mode
@@ -295,8 +600,12 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 		}
 
 		dependenciesSet := schema.NewSet(schema.HashString, []interface{}{})
-		for _, v := range t.Dependencies {
-			dependenciesSet.Add(v.TriggerID)
+		if len(d.Get("depends_on_triggers").([]interface{})) > 0 {
+			d.Set("depends_on_triggers", flattenTriggerDependencies(api, prototype, d, t.Dependencies))
+		} else {
+			for _, v := range t.Dependencies {
+				dependenciesSet.Add(v.TriggerID)
+			}
 		}
 		d.Set("dependencies", dependenciesSet)
 
@@ -305,7 +614,7 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 }
 
 // update trigger terraform handler
-func resourceTriggerUpdate(prototype bool) schema.UpdateFunc {
+func resourceTriggerUpdate(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 
@@ -313,26 +622,87 @@ func resourceTriggerUpdate(prototype bool) schema.UpdateFunc {
 
 		item.TriggerID = d.Id()
 
-		items := []zabbix.Trigger{item}
-
-		var err error
-
-		if prototype {
-			err = api.ProtoTriggersUpdate(items)
-		} else {
-			err = api.TriggersUpdate(items)
+		deps, err := buildTriggerDependencies(api, prototype, d)
+		if err != nil {
+			return err
 		}
+		item.Dependencies = deps
 
+		params, err := triggerParams(api, item)
 		if err != nil {
 			return err
 		}
 
+		if _, err := api.CallWithError(triggerMethod(prototype, "update"), params); err != nil {
+			return err
+		}
+
 		return resourceTriggerRead(prototype)(d, m)
 	}
 }
 
+// read trigger data source handler
+func dataTriggerRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"host":             d.Get("host").(string),
+		"expandExpression": "extend",
+		"selectTags":       []string{"tag", "value"},
+		"filter": map[string]interface{}{
+			"description": d.Get("name").(string),
+		},
+	}
+	if v := d.Get("tag").(*schema.Set); v.Len() > 0 {
+		params["tags"] = tagFilter(d)
+	}
+	log.Debug("Lookup of trigger with: %#v", params)
+
+	triggers, err := api.TriggersGet(params)
+
+	if err != nil {
+		return err
+	}
+
+	// the stored expression references function ids, so compare the expanded form here
+	if v := d.Get("expression").(string); v != "" {
+		matched := zabbix.Triggers{}
+		for _, t := range triggers {
+			if t.Expression == v {
+				matched = append(matched, t)
+			}
+		}
+		triggers = matched
+	}
+
+	if len(triggers) < 1 {
+		return errors.New("no trigger found")
+	}
+	if len(triggers) > 1 {
+		// list the candidates so the lookup can be narrowed with an expression
+		candidates := make([]string, len(triggers))
+		for i, t := range triggers {
+			candidates[i] = fmt.Sprintf("%s (%s)", t.TriggerID, t.Expression)
+		}
+		return fmt.Errorf("multiple triggers named %q found on host %q, set expression to pick one of: %s",
+			d.Get("name").(string), d.Get("host").(string), strings.Join(candidates, ", "))
+	}
+	t := triggers[0]
+
+	log.Debug("Got trigger: %s", t.TriggerID)
+
+	d.SetId(t.TriggerID)
+	d.Set("name", t.Description)
+	d.Set("expression", t.Expression)
+	d.Set("priority", TRIGGER_PRIORITY_REV[t.Priority])
+	d.Set("enabled", t.Status == 0)
+	d.Set("tag", flattenTags(t.Tags))
+
+	return nil
+}
+
 // delete trigger terraform handler
-func resourceTriggerDelete(prototype bool) schema.DeleteFunc {
+func resourceTriggerDelete(prototype bool) crudFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
 		api := m.(*zabbix.API)
 		if prototype {
@@ -1,23 +1,29 @@
 package provider
 
 import (
+	"context"
+
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
 // resourceUser terraform resource handler
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceUserCreate,
-		Read:   resourceUserRead,
-		Update: resourceUserUpdate,
-		Delete: resourceUserDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
+		CreateContext: contextCRUD(resourceUserCreate),
+		ReadContext:   contextCRUD(resourceUserRead),
+		UpdateContext: contextCRUD(resourceUserUpdate),
+		DeleteContext: contextCRUD(resourceUserDelete),
+		Importer:      importByName(lookupUserByName),
+
+		CustomizeDiff: customdiff.All(userProvisionedCustomizeDiff, hashedSecretCustomizeDiff("password_wo", "password_wo_version")),
 
 		Schema: map[string]*schema.Schema{
 			"username": &schema.Schema{
@@ -27,17 +33,42 @@ func resourceUser() *schema.Resource {
 				Required:     true,
 			},
 			"password": &schema.Schema{
-				Type:         schema.TypeString,
-				ValidateFunc: validation.StringIsNotWhiteSpace,
-				Description:  "User's password.",
+				Type:          schema.TypeString,
+				ValidateFunc:  validation.StringIsNotWhiteSpace,
+				Description:   "User's password.",
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"password_wo"},
+			},
+			"password_wo": &schema.Schema{
+				Type:          schema.TypeString,
+				ValidateFunc:  validation.StringIsNotWhiteSpace,
+				Description:   "User's password, write-only, the state only keeps a hash of it.",
+				Optional:      true,
+				Sensitive:     true,
+				StateFunc:     hashSecret,
+				ConflictsWith: []string{"password"},
+			},
+			"password_wo_version": &schema.Schema{
+				Type:         schema.TypeInt,
+				Description:  "Change this along with password_wo to mark a new password, changing it alone is rejected as password_wo is only known by its hash.",
 				Optional:     true,
-				Sensitive:    true,
+				RequiredWith: []string{"password_wo"},
 			},
 			"roleid": &schema.Schema{
 				Type:         schema.TypeString,
 				ValidateFunc: validation.StringIsNotWhiteSpace,
 				Description:  "Role ID of the user.",
-				Required:     true,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"roleid", "role"},
+			},
+			"role": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Role name of the user, resolved to roleid.",
+				Optional:     true,
+				ExactlyOneOf: []string{"roleid", "role"},
 			},
 			"name": &schema.Schema{
 				Type:         schema.TypeString,
@@ -58,10 +89,206 @@ func resourceUser() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				Set:      schema.HashString,
 			},
+			"lang": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Language code of the user's frontend, e.g. en_US, or default.",
+				Optional:     true,
+				Computed:     true,
+			},
+			"theme": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(USER_THEMES, false),
+				Description:  "User's frontend theme, one of: " + strings.Join(USER_THEMES, ", "),
+				Optional:     true,
+				Computed:     true,
+			},
+			"timezone": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "User's time zone, e.g. Europe/Riga, or default. Zabbix >= 5.2.",
+				Optional:     true,
+				Computed:     true,
+			},
+			"autologin": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Keep the user logged in between sessions.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"autologout": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateTimeSuffix,
+				Description:  "Inactivity time after which the user is logged out, 0 to disable.",
+				Optional:     true,
+				Computed:     true,
+			},
+			"refresh": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateTimeSuffix,
+				Description:  "Automatic refresh period of the frontend.",
+				Optional:     true,
+				Computed:     true,
+			},
+			"rows_per_page": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(1, 999999),
+				Description:  "Object rows shown per page.",
+				Optional:     true,
+				Computed:     true,
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "URL of the page to redirect the user to after logging in.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"userdirectoryid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "ID of the user directory the user was provisioned from.",
+				Computed:    true,
+			},
+			"provisioned": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the user is provisioned from a user directory.",
+				Computed:    true,
+			},
 		},
 	}
 }
 
+// USER_THEMES frontend themes a user can pick
+var USER_THEMES = []string{
+	"default",
+	"blue-theme",
+	"dark-theme",
+	"hc-light",
+	"hc-dark",
+}
+
+// USER_PROVISIONED_FIELDS fields of provisioned users owned by the user directory,
+// mapped to the user.update parameter they are sent as
+var USER_PROVISIONED_FIELDS = map[string]string{
+	"username":            "username",
+	"password":            "passwd",
+	"password_wo":         "passwd",
+	"password_wo_version": "passwd",
+	"roleid":              "roleid",
+	"role":                "roleid",
+	"name":                "name",
+	"surname":             "surname",
+	"groups":              "usrgrps",
+}
+
+// userObject user including the profile settings zabbix.User lacks
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/user/object
+type userObject struct {
+	zabbix.User
+	Lang        string `json:"lang,omitempty"`
+	Theme       string `json:"theme,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+	Autologin   *int   `json:"autologin,omitempty,string"`
+	Autologout  string `json:"autologout,omitempty"`
+	Refresh     string `json:"refresh,omitempty"`
+	RowsPerPage int    `json:"rows_per_page,omitempty,string"`
+	URL         string `json:"url,omitempty"`
+
+	// read only
+	UserDirectoryID string `json:"userdirectoryid,omitempty"`
+}
+
+// provisioned whether the user was provisioned from a user directory
+func (u *userObject) provisioned() bool {
+	return u.UserDirectoryID != "" && u.UserDirectoryID != "0"
+}
+
+// userProvisionedCustomizeDiff reject changes to fields a user directory owns,
+// zabbix refuses to update them on provisioned users
+func userProvisionedCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.Get("provisioned").(bool) {
+		return nil
+	}
+
+	changed := []string{}
+	for field := range USER_PROVISIONED_FIELDS {
+		if d.HasChange(field) {
+			changed = append(changed, field)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Strings(changed)
+
+	return fmt.Errorf("user %q is provisioned from user directory %s, %s can only be changed in the directory",
+		d.Get("username"), d.Get("userdirectoryid"), strings.Join(changed, ", "))
+}
+
+// userOutput user fields read back, for the server version
+func userOutput(api *zabbix.API) []string {
+	fields := []string{"userid", "name", "surname", "lang", "theme", "autologin", "autologout", "refresh", "rows_per_page", "url"}
+	if api.Config.Version >= 50200 {
+		fields = append(fields, "roleid", "timezone")
+	}
+	if api.Config.Version >= 50400 {
+		fields = append(fields, "username")
+	} else {
+		fields = append(fields, "alias")
+	}
+	if api.Config.Version >= 60200 {
+		fields = append(fields, "userdirectoryid")
+	}
+	return fields
+}
+
+// buildUserObject create a user from terraform data, profile settings that are
+// not configured are left to the server
+func buildUserObject(d *schema.ResourceData, api *zabbix.API) (*userObject, error) {
+	roleID, err := resourceUserRoleID(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	password, _ := resourceUserPassword(d)
+
+	item := userObject{
+		User: zabbix.User{
+			UserID:   d.Id(),
+			Username: d.Get("username").(string),
+			Password: password,
+			RoleID:   roleID,
+			Name:     d.Get("name").(string),
+			Surname:  d.Get("surname").(string),
+			Groups:   resourceUserGroupsV1(d),
+		},
+		Lang:        d.Get("lang").(string),
+		Theme:       d.Get("theme").(string),
+		Timezone:    d.Get("timezone").(string),
+		Autologout:  d.Get("autologout").(string),
+		Refresh:     d.Get("refresh").(string),
+		RowsPerPage: d.Get("rows_per_page").(int),
+		URL:         d.Get("url").(string),
+	}
+
+	if v, ok := d.GetOkExists("autologin"); ok {
+		autologin := 0
+		if v.(bool) {
+			autologin = 1
+		}
+		item.Autologin = &autologin
+	}
+
+	// time zones were added in 5.2
+	if item.Timezone != "" {
+		if err := requireVersion(api, 50200, "timezone on users"); err != nil {
+			return nil, errorAt("timezone", err)
+		}
+	}
+
+	return &item, nil
+}
+
 func resourceUserGroupsV1(d *schema.ResourceData) []zabbix.UserGroupID {
 	rawGroups := d.Get("groups").(*schema.Set).List()
 	groups := make([]zabbix.UserGroupID, len(rawGroups))
@@ -73,19 +300,134 @@ func resourceUserGroupsV1(d *schema.ResourceData) []zabbix.UserGroupID {
 	return groups
 }
 
+// resourceUserRoleID role id of the user, resolving the role name if given instead
+func resourceUserRoleID(d *schema.ResourceData, api *zabbix.API) (string, error) {
+	name, ok := d.GetOk("role")
+	if !ok {
+		return d.Get("roleid").(string), nil
+	}
+
+	roles, err := rolesGet(api, zabbix.Params{
+		"output": []string{"roleid", "name"},
+		"filter": map[string]interface{}{
+			"name": name,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(roles) != 1 {
+		return "", fmt.Errorf("role %q not found", name)
+	}
+	return roles[0].RoleID, nil
+}
+
+// resourceUserPassword password to send, false if it should be left untouched;
+// password_wo is only sent on create or when it changed, any other time the
+// state only holds its hash
+func resourceUserPassword(d *schema.ResourceData) (string, bool) {
+	if _, ok := d.GetOk("password_wo"); !ok {
+		return d.Get("password").(string), true
+	}
+	if d.IsNewResource() || d.HasChange("password_wo") {
+		return d.Get("password_wo").(string), true
+	}
+	return "", false
+}
+
 // dataUser terraform data handler
 func dataUser() *schema.Resource {
 	return &schema.Resource{
-		Read: dataUserRead,
+		ReadContext: contextCRUD(dataUserRead),
 
-		Schema: map[string]*schema.Schema{
+		Schema: mergeSchemas(searchSchema(false), map[string]*schema.Schema{
 			"username": &schema.Schema{
 				Type:         schema.TypeString,
 				ValidateFunc: validation.StringIsNotWhiteSpace,
 				Description:  "User's name.",
 				Required:     true,
 			},
-		},
+		}),
+	}
+}
+
+// userLookup subset of the user object returned to the plural user data source
+type userLookup struct {
+	UserID      string `json:"userid"`
+	Username    string `json:"username"`
+	RoleID      string `json:"roleid"`
+	UsersStatus int    `json:"users_status,string"`
+}
+
+// USER_SORT_FIELDS sort_field values of zabbix_users and the user.get
+// sortfield of each, empty for those it can not sort by
+var USER_SORT_FIELDS = map[string]string{
+	"id":       "userid",
+	"username": "username",
+	"roleid":   "",
+	"enabled":  "",
+}
+
+// dataUsers terraform plural user data handler
+func dataUsers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataUsersRead),
+
+		Schema: mergeSchemas(pagingSchema(), searchSchema(true), sortSchema(USER_SORT_FIELDS), map[string]*schema.Schema{
+			"username": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "User's name to filter on, * may be used as a wildcard.",
+				Optional:     true,
+			},
+			"groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User group IDs to filter on.",
+				Optional:    true,
+			},
+			"roleid": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Role ID to filter on.",
+				Optional:     true,
+			},
+			"ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Matching user IDs.",
+				Computed:    true,
+			},
+			"users": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Matching users.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "ID of the user.",
+							Computed:    true,
+						},
+						"username": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "User's name.",
+							Computed:    true,
+						},
+						"roleid": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Role ID of the user.",
+							Computed:    true,
+						},
+						"enabled": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Whether the user is enabled through its user groups.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		}),
 	}
 }
 
@@ -93,35 +435,45 @@ func dataUser() *schema.Resource {
 func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
-	item := zabbix.User{
-		Username: d.Get("username").(string),
-		Password: d.Get("password").(string),
-		RoleID:   d.Get("roleid").(string),
-		Name:     d.Get("name").(string),
-		Surname:  d.Get("surname").(string),
-		Groups:   resourceUserGroupsV1(d),
+	item, err := buildUserObject(d, api)
+
+	if err != nil {
+		return err
 	}
 
-	items := []zabbix.User{item}
+	params, err := versionedParams(api, item, nil)
+
+	if err != nil {
+		return err
+	}
+	delete(params, "userid")
 
-	err := api.UsersCreate(items)
+	response, err := api.CallWithError("user.create", params)
 
 	if err != nil {
 		return err
 	}
 
-	log.Trace("created User: %+v", items[0])
+	result := response.Result.(map[string]interface{})
+	item.UserID = result["userids"].([]interface{})[0].(string)
 
-	d.SetId(items[0].UserID)
+	log.Trace("created User: %s", item.UserID)
 
-	return resourceUserRead(d, m)
+	d.SetId(item.UserID)
+
+	return readAfterWrite(d, m, resourceUser().Schema, resourceUserRead)
 }
 
 // userRead terraform user read function
 func userRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
 	api := m.(*zabbix.API)
 
-	Users, err := api.UsersGet(params)
+	if _, present := params["output"]; !present {
+		params["output"] = userOutput(api)
+	}
+
+	var Users []userObject
+	err := api.CallWithErrorParse("user.get", params, &Users)
 
 	if err != nil {
 		return err
@@ -136,24 +488,111 @@ func userRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error
 	}
 	t := Users[0]
 
-	log.Debug("Got User: %+v", t)
+	log.Debug("Got User: %s", t.UserID)
 
 	d.SetId(t.UserID)
 	d.Set("username", t.Username)
 	d.Set("roleid", t.RoleID)
 	d.Set("name", t.Name)
+
+	// report the current role name, so a role change outside terraform shows up
+	if _, ok := d.GetOk("role"); ok {
+		roles, err := rolesGet(api, zabbix.Params{
+			"output":  []string{"roleid", "name"},
+			"roleids": t.RoleID,
+		})
+		if err != nil {
+			return err
+		}
+		if len(roles) == 1 {
+			d.Set("role", roles[0].Name)
+		}
+	}
 	d.Set("surname", t.Surname)
+	d.Set("lang", t.Lang)
+	d.Set("theme", t.Theme)
+	d.Set("timezone", t.Timezone)
+	d.Set("autologin", t.Autologin != nil && *t.Autologin == 1)
+	d.Set("autologout", t.Autologout)
+	d.Set("refresh", t.Refresh)
+	d.Set("rows_per_page", t.RowsPerPage)
+	d.Set("url", t.URL)
+	d.Set("userdirectoryid", t.UserDirectoryID)
+	d.Set("provisioned", t.provisioned())
+
+	if _, ok := params["selectUsrgrps"]; ok {
+		groups := make([]string, len(t.Groups))
+		for i, g := range t.Groups {
+			groups[i] = g.UserGroupID
+		}
+		d.Set("groups", groups)
+	}
 
 	return nil
 }
 
 // dataUserRead terraform data resource read handler
 func dataUserRead(d *schema.ResourceData, m interface{}) error {
-	return userRead(d, m, zabbix.Params{
+	params := zabbix.Params{
 		"filter": map[string]interface{}{
 			"username": d.Get("username"),
 		},
+	}
+	applySearch(d, params, "username")
+
+	return userRead(d, m, params)
+}
+
+// dataUsersRead terraform plural data resource read handler
+func dataUsersRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"output":    []string{"userid", "username", "roleid"},
+		"getAccess": true,
+		"filter":    map[string]interface{}{},
+	}
+
+	if v, ok := d.GetOk("username"); ok {
+		params["search"] = map[string]interface{}{
+			"username": v,
+		}
+	}
+
+	if v := d.Get("groups").(*schema.Set); v.Len() > 0 {
+		params["usrgrpids"] = v.List()
+	}
+
+	if v, ok := d.GetOk("roleid"); ok {
+		params["filter"].(map[string]interface{})["roleid"] = v
+	}
+	applySearch(d, params)
+	log.Debug("performing data lookup with params: %#v", params)
+
+	ids, list, err := pagedGet(d, "userid", USER_SORT_FIELDS, params, func(params zabbix.Params) ([]map[string]interface{}, error) {
+		var users []userLookup
+		err := api.CallWithErrorParse("user.get", params, &users)
+		entries := make([]map[string]interface{}, len(users))
+		for i, u := range users {
+			entries[i] = map[string]interface{}{
+				"id":       u.UserID,
+				"username": u.Username,
+				"roleid":   u.RoleID,
+				"enabled":  u.UsersStatus == 0,
+			}
+		}
+		return entries, err
 	})
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(dataListID(params))
+	d.Set("ids", ids)
+	d.Set("users", list)
+
+	return nil
 }
 
 // resourceUserRead terraform resource read handler
@@ -161,7 +600,8 @@ func resourceUserRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of User with id %s", d.Id())
 
 	return userRead(d, m, zabbix.Params{
-		"userids": d.Id(),
+		"userids":       d.Id(),
+		"selectUsrgrps": []string{"usrgrpid"},
 	})
 }
 
@@ -169,25 +609,34 @@ func resourceUserRead(d *schema.ResourceData, m interface{}) error {
 func resourceUserUpdate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
-	item := zabbix.User{
-		UserID:   d.Id(),
-		Username: d.Get("username").(string),
-		Password: d.Get("password").(string),
-		RoleID:   d.Get("roleid").(string),
-		Name:     d.Get("name").(string),
-		Surname:  d.Get("surname").(string),
-		Groups:   resourceUserGroupsV1(d),
+	item, err := buildUserObject(d, api)
+
+	if err != nil {
+		return err
 	}
 
-	items := []zabbix.User{item}
+	params, err := versionedParams(api, item, nil)
+
+	if err != nil {
+		return err
+	}
+	if _, send := resourceUserPassword(d); !send {
+		delete(params, "passwd")
+	}
+	// the directory owns these, zabbix rejects them even when unchanged
+	if d.Get("provisioned").(bool) {
+		for _, param := range USER_PROVISIONED_FIELDS {
+			delete(params, param)
+		}
+	}
 
-	err := api.UsersUpdate(items)
+	_, err = api.CallWithError("user.update", params)
 
 	if err != nil {
 		return err
 	}
 
-	return resourceUserRead(d, m)
+	return readAfterWrite(d, m, resourceUser().Schema, resourceUserRead)
 }
 
 // resourceUserDelete terraform resource delete handler
@@ -0,0 +1,264 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// ProvisionGroup represent a Zabbix user directory provisioning group mapping
+// https://www.zabbix.com/documentation/current/en/manual/api/reference/userdirectory/object#provisioning-groups
+type ProvisionGroup struct {
+	Name       string               `json:"name"`
+	RoleID     string               `json:"roleid"`
+	UserGroups []zabbix.UserGroupID `json:"user_groups"`
+}
+
+// userDirectoryMutex mappings are stored as one list on the user directory,
+// serialize the read-modify-write of parallel resources
+var userDirectoryMutex sync.Mutex
+
+// resourceUserDirectoryGroup terraform resource handler
+func resourceUserDirectoryGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceUserDirectoryGroupCreate),
+		ReadContext:   contextCRUD(resourceUserDirectoryGroupRead),
+		UpdateContext: contextCRUD(resourceUserDirectoryGroupUpdate),
+		DeleteContext: contextCRUD(resourceUserDirectoryGroupDelete),
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"userdirectoryid": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "ID of the user directory.",
+				Required:     true,
+				ForceNew:     true,
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the directory group, * may be used as a wildcard.",
+				Required:     true,
+				ForceNew:     true,
+			},
+			"roleid": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Role ID given to provisioned users of the group.",
+				Required:     true,
+			},
+			"user_groups": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "User group IDs provisioned users of the group are added to.",
+				Required:    true,
+				MinItems:    1,
+			},
+		},
+	}
+}
+
+// userDirectoryGroupID resource id of a mapping, directory ids are numeric so
+// the first colon separates them from the group name
+func userDirectoryGroupID(directoryID, name string) string {
+	return directoryID + ":" + name
+}
+
+// parseUserDirectoryGroupID split a resource id into directory id and group name
+func parseUserDirectoryGroupID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid id %q, expected <userdirectoryid>:<group name>", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// provisionGroupsGet provisioning groups of a user directory, nil if the directory is gone
+func provisionGroupsGet(api *zabbix.API, directoryID string) ([]ProvisionGroup, error) {
+	if err := requireVersion(api, 60400, "user directory provisioning groups"); err != nil {
+		return nil, err
+	}
+
+	var res []struct {
+		UserDirectoryID string           `json:"userdirectoryid"`
+		ProvisionGroups []ProvisionGroup `json:"provision_groups"`
+	}
+	err := api.CallWithErrorParse("userdirectory.get", zabbix.Params{
+		"output":                []string{"userdirectoryid"},
+		"userdirectoryids":      directoryID,
+		"selectProvisionGroups": "extend",
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) < 1 {
+		return nil, nil
+	}
+	if res[0].ProvisionGroups == nil {
+		return []ProvisionGroup{}, nil
+	}
+	return res[0].ProvisionGroups, nil
+}
+
+// provisionGroupsModify apply fn to the provisioning groups of a user directory
+// and store the result
+func provisionGroupsModify(api *zabbix.API, directoryID string, fn func([]ProvisionGroup) ([]ProvisionGroup, error)) error {
+	userDirectoryMutex.Lock()
+	defer userDirectoryMutex.Unlock()
+
+	groups, err := provisionGroupsGet(api, directoryID)
+	if err != nil {
+		return err
+	}
+	if groups == nil {
+		return fmt.Errorf("user directory %s not found", directoryID)
+	}
+
+	groups, err = fn(groups)
+	if err != nil {
+		return err
+	}
+
+	_, err = api.CallWithError("userdirectory.update", zabbix.Params{
+		"userdirectoryid":  directoryID,
+		"provision_groups": groups,
+	})
+	return err
+}
+
+// buildProvisionGroup create a provisioning group from terraform data
+func buildProvisionGroup(d *schema.ResourceData) ProvisionGroup {
+	rawGroups := d.Get("user_groups").(*schema.Set).List()
+	userGroups := make([]zabbix.UserGroupID, len(rawGroups))
+	for i, raw := range rawGroups {
+		userGroups[i] = zabbix.UserGroupID{
+			UserGroupID: raw.(string),
+		}
+	}
+
+	return ProvisionGroup{
+		Name:       d.Get("name").(string),
+		RoleID:     d.Get("roleid").(string),
+		UserGroups: userGroups,
+	}
+}
+
+// resourceUserDirectoryGroupCreate terraform resource create handler
+func resourceUserDirectoryGroupCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	directoryID := d.Get("userdirectoryid").(string)
+	item := buildProvisionGroup(d)
+
+	err := provisionGroupsModify(api, directoryID, func(groups []ProvisionGroup) ([]ProvisionGroup, error) {
+		for _, g := range groups {
+			if g.Name == item.Name {
+				return nil, fmt.Errorf("user directory %s already maps group %q, import it instead", directoryID, item.Name)
+			}
+		}
+		return append(groups, item), nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	log.Trace("created provisioning group: %s", item.Name)
+
+	d.SetId(userDirectoryGroupID(directoryID, item.Name))
+
+	return resourceUserDirectoryGroupRead(d, m)
+}
+
+// resourceUserDirectoryGroupRead terraform resource read handler
+func resourceUserDirectoryGroupRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	directoryID, name, err := parseUserDirectoryGroupID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Lookup of provisioning group %q on user directory %s", name, directoryID)
+
+	groups, err := provisionGroupsGet(api, directoryID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		if g.Name != name {
+			continue
+		}
+		log.Debug("Got provisioning group: %s", g.Name)
+
+		userGroups := make([]string, len(g.UserGroups))
+		for i, u := range g.UserGroups {
+			userGroups[i] = u.UserGroupID
+		}
+
+		d.Set("userdirectoryid", directoryID)
+		d.Set("name", g.Name)
+		d.Set("roleid", g.RoleID)
+		d.Set("user_groups", userGroups)
+		return nil
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceUserDirectoryGroupUpdate terraform resource update handler
+func resourceUserDirectoryGroupUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	directoryID := d.Get("userdirectoryid").(string)
+	item := buildProvisionGroup(d)
+
+	err := provisionGroupsModify(api, directoryID, func(groups []ProvisionGroup) ([]ProvisionGroup, error) {
+		for i, g := range groups {
+			if g.Name == item.Name {
+				groups[i] = item
+				return groups, nil
+			}
+		}
+		return append(groups, item), nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return resourceUserDirectoryGroupRead(d, m)
+}
+
+// resourceUserDirectoryGroupDelete terraform resource delete handler
+func resourceUserDirectoryGroupDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	directoryID, name, err := parseUserDirectoryGroupID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	return provisionGroupsModify(api, directoryID, func(groups []ProvisionGroup) ([]ProvisionGroup, error) {
+		kept := []ProvisionGroup{}
+		for _, g := range groups {
+			if g.Name != name {
+				kept = append(kept, g)
+			}
+		}
+		return kept, nil
+	})
+}
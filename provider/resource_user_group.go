@@ -1,25 +1,34 @@
 package provider
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
+// host group permissions were called rights before 6.2
+var USER_GROUP_RENAMES = map[string]versionedField{
+	"hostgroup_rights": {Since: 60200, Before: "rights"},
+}
+
+// USER_GROUP_OUTPUT user group fields read back
+var USER_GROUP_OUTPUT = []string{"usrgrpid", "name", "debug_mode", "gui_access", "users_status"}
+
 // resourceUserGroup terraform resource handler
 func resourceUserGroup() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceUserGroupCreate,
-		Read:   resourceUserGroupRead,
-		Update: resourceUserGroupUpdate,
-		Delete: resourceUserGroupDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
+		CreateContext: contextCRUD(resourceUserGroupCreate),
+		ReadContext:   contextCRUD(resourceUserGroupRead),
+		UpdateContext: contextCRUD(resourceUserGroupUpdate),
+		DeleteContext: contextCRUD(resourceUserGroupDelete),
+		Importer:      importByName(lookupByField("usergroup.get", "usrgrpid", "name")),
 
 		Schema: map[string]*schema.Schema{
+			"deletion_protection": deletionProtectionSchema(),
 			"name": &schema.Schema{
 				Type:         schema.TypeString,
 				ValidateFunc: validation.StringIsNotWhiteSpace,
@@ -47,57 +56,300 @@ func resourceUserGroup() *schema.Resource {
 				Optional:     true,
 				Default:      0,
 			},
-			"host_permission": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Computed: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"id": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"permission": {
-							Type:         schema.TypeInt,
-							ValidateFunc: validation.IntBetween(0, 3),
-							Required:     true,
-						},
-					},
+			"host_permission": userGroupPermissionSchema("Host", true),
+			// not computed, so removing the last block is planned and clears the rights
+			"template_permission": userGroupPermissionSchema("Template", false),
+			"user_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "IDs of the users in the group, membership is left alone when not set.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// userGroupObject user group including the template group rights zabbix.UserGroup lacks
+type userGroupObject struct {
+	zabbix.UserGroup
+	TemplatePermissions *[]zabbix.UserGroupPermission `json:"templategroup_rights,omitempty"`
+	Users               *[]zabbix.UserID              `json:"users,omitempty"`
+
+	// members before 5.2
+	UserIDs *[]string `json:"userids,omitempty"`
+}
+
+// userGroupPermissionSchema permission list on host or template groups, computed
+// lists keep rights granted outside of terraform when not configured
+func userGroupPermissionSchema(kind string, computed bool) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Computed: computed,
+		Set:      hashUserGroupPermission,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Computed: true,
+				},
+				"name": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Computed:    true,
+					Description: kind + " group name, resolved to its id when id is not set.",
+				},
+				"permission": {
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(0, 3),
+					Required:     true,
 				},
 			},
 		},
 	}
 }
 
-func resourceHostGroupPermissionsV1(d *schema.ResourceData) []zabbix.UserGroupPermission {
+// hashUserGroupPermission a permission is identified by the group name when given,
+// so the id resolved into state does not change it
+func hashUserGroupPermission(i interface{}) int {
+	m := i.(map[string]interface{})
+	group, _ := m["name"].(string)
+	if group == "" {
+		group, _ = m["id"].(string)
+	}
+	permission, _ := m["permission"].(int)
+	return hashString(fmt.Sprintf("%s-%d", group, permission))
+}
+
+func resourceHostGroupPermissionsV1(d *schema.ResourceData, api *zabbix.API) ([]zabbix.UserGroupPermission, error) {
+	return resourceGroupPermissions(d, "host_permission", "host group", func(names []string) (map[string]string, error) {
+		groups, err := api.HostGroupsGet(zabbix.Params{
+			"output": []string{"groupid", "name"},
+			"filter": map[string]interface{}{
+				"name": names,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		ids := map[string]string{}
+		for _, g := range groups {
+			ids[g.Name] = g.GroupID
+		}
+		return ids, nil
+	})
+}
+
+// resourceTemplateGroupPermissions template group rights, nil when they are
+// neither configured nor removed from the config
+func resourceTemplateGroupPermissions(d *schema.ResourceData, api *zabbix.API) (*[]zabbix.UserGroupPermission, error) {
+	permissions, err := resourceGroupPermissions(d, "template_permission", "template group", func(names []string) (map[string]string, error) {
+		var groups []struct {
+			GroupID string `json:"groupid"`
+			Name    string `json:"name"`
+		}
+		err := api.CallWithErrorParse("templategroup.get", zabbix.Params{
+			"output": []string{"groupid", "name"},
+			"filter": map[string]interface{}{
+				"name": names,
+			},
+		}, &groups)
+		if err != nil {
+			return nil, err
+		}
+		ids := map[string]string{}
+		for _, g := range groups {
+			ids[g.Name] = g.GroupID
+		}
+		return ids, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(permissions) > 0 {
+		if err := requireVersion(api, 60200, "template_permission"); err != nil {
+			return nil, errorAt("template_permission", err)
+		}
+		return &permissions, nil
+	}
+	// clear the rights once the last block is removed
+	if !d.IsNewResource() && d.HasChange("template_permission") {
+		return &[]zabbix.UserGroupPermission{}, nil
+	}
+	return nil, nil
+}
+
+// resourceGroupPermissions permissions of a permission list, resolving groups
+// given by name with a single lookup
+func resourceGroupPermissions(d *schema.ResourceData, field, kind string, lookup func(names []string) (map[string]string, error)) ([]zabbix.UserGroupPermission, error) {
 	var permissionsRequests []zabbix.UserGroupPermission
 
-	permissions := d.Get("host_permission").([]interface{})
+	permissions := d.Get(field).(*schema.Set).List()
+
+	names := []string{}
+	for i := range permissions {
+		permission := permissions[i].(map[string]interface{})
+		if permission["name"].(string) != "" {
+			names = append(names, permission["name"].(string))
+		}
+	}
+	ids := map[string]string{}
+	if len(names) > 0 {
+		var err error
+		if ids, err = lookup(names); err != nil {
+			return nil, err
+		}
+	}
+
 	for i := range permissions {
 		permission := permissions[i].(map[string]interface{})
+		id := permission["id"].(string)
+
+		// a name always wins, the id in state may belong to a previous name
+		if name := permission["name"].(string); name != "" {
+			if id = ids[name]; id == "" {
+				return nil, fmt.Errorf("%s: %s %q not found", field, kind, name)
+			}
+			permission["id"] = id
+		}
+		if id == "" {
+			return nil, fmt.Errorf("%s: one of id or name must be set", field)
+		}
 		permissionsRequest := zabbix.UserGroupPermission{
-			ID:         permission["id"].(string),
+			ID:         id,
 			Permission: permission["permission"].(int),
 		}
 
 		permissionsRequests = append(permissionsRequests, permissionsRequest)
 	}
-	return permissionsRequests
+
+	// keep the resolved ids in state
+	d.Set(field, permissions)
+
+	return permissionsRequests, nil
+}
+
+// buildUserGroupObject create a user group from terraform data
+func buildUserGroupObject(d *schema.ResourceData, api *zabbix.API) (*userGroupObject, error) {
+	permissions, err := resourceHostGroupPermissionsV1(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templatePermissions, err := resourceTemplateGroupPermissions(d, api)
+
+	if err != nil {
+		return nil, err
+	}
+
+	item := userGroupObject{
+		UserGroup: zabbix.UserGroup{
+			UserGroupID: d.Id(),
+			Name:        d.Get("name").(string),
+			DebugMode:   d.Get("debug_mode").(int),
+			GUIAccess:   d.Get("gui_access").(int),
+			Status:      d.Get("status").(int),
+			Permissions: permissions,
+		},
+		TemplatePermissions: templatePermissions,
+	}
+
+	// membership is sent when configured or changed, emptying user_ids removes
+	// every member
+	if v := d.Get("user_ids").(*schema.Set); v.Len() > 0 || d.HasChange("user_ids") {
+		ids := []string{}
+		users := []zabbix.UserID{}
+		for _, id := range v.List() {
+			ids = append(ids, id.(string))
+			users = append(users, zabbix.UserID{UserID: id.(string)})
+		}
+		if api.Config.Version < 50200 {
+			item.UserIDs = &ids
+		} else {
+			item.Users = &users
+		}
+	}
+
+	return &item, nil
 }
 
 // dataUserGroup terraform data handler
 func dataUserGroup() *schema.Resource {
 	return &schema.Resource{
-		Read: dataUserGroupRead,
+		ReadContext: contextCRUD(dataUserGroupRead),
 
-		Schema: map[string]*schema.Schema{
+		Schema: mergeSchemas(searchSchema(false), map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:         schema.TypeString,
 				ValidateFunc: validation.StringIsNotWhiteSpace,
 				Description:  "Name of the user group.",
 				Required:     true,
 			},
-		},
+		}),
+	}
+}
+
+// USER_GROUP_SORT_FIELDS sort_field values of zabbix_user_groups and the usergroup.get
+// sortfield of each, empty for those it can not sort by
+var USER_GROUP_SORT_FIELDS = map[string]string{
+	"id":     "usrgrpid",
+	"name":   "name",
+	"status": "",
+}
+
+// dataUserGroups terraform plural data handler
+func dataUserGroups() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: contextCRUD(dataUserGroupsRead),
+
+		Schema: mergeSchemas(pagingSchema(), searchSchema(true), sortSchema(USER_GROUP_SORT_FIELDS), map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the user group to filter on, * may be used as a wildcard.",
+				Optional:     true,
+			},
+			"status": &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntBetween(0, 1),
+				Description:  "Status of the user group to filter on. Possible values: 0 - enabled; 1 - disabled.",
+				Optional:     true,
+			},
+			"ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Matching user group IDs.",
+				Computed:    true,
+			},
+			"user_groups": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "Matching user groups.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "ID of the user group.",
+							Computed:    true,
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "Name of the user group.",
+							Computed:    true,
+						},
+						"status": &schema.Schema{
+							Type:        schema.TypeInt,
+							Description: "Whether the user group is enabled or disabled.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		}),
 	}
 }
 
@@ -105,27 +357,32 @@ func dataUserGroup() *schema.Resource {
 func resourceUserGroupCreate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
-	item := zabbix.UserGroup{
-		Name:        d.Get("name").(string),
-		DebugMode:   d.Get("debug_mode").(int),
-		GUIAccess:   d.Get("gui_access").(int),
-		Status:      d.Get("status").(int),
-		Permissions: resourceHostGroupPermissionsV1(d),
+	item, err := buildUserGroupObject(d, api)
+
+	if err != nil {
+		return err
 	}
 
-	items := []zabbix.UserGroup{item}
+	params, err := versionedParams(api, item, USER_GROUP_RENAMES)
 
-	err := api.UserGroupsCreate(items)
+	if err != nil {
+		return err
+	}
+
+	response, err := api.CallWithError("usergroup.create", params)
 
 	if err != nil {
 		return err
 	}
 
-	log.Trace("created UserGroup: %+v", items[0])
+	result := response.Result.(map[string]interface{})
+	item.UserGroupID = result["usrgrpids"].([]interface{})[0].(string)
+
+	log.Trace("created UserGroup: %s", item.UserGroupID)
 
-	d.SetId(items[0].UserGroupID)
+	d.SetId(item.UserGroupID)
 
-	return resourceUserGroupRead(d, m)
+	return readAfterWrite(d, m, resourceUserGroup().Schema, resourceUserGroupRead)
 }
 
 // userGroupRead terraform user group read function
@@ -147,7 +404,7 @@ func userGroupRead(d *schema.ResourceData, m interface{}, params zabbix.Params)
 	}
 	t := UserGroups[0]
 
-	log.Debug("Got UserGroup: %+v", t)
+	log.Debug("Got UserGroup: %s", t.UserGroupID)
 
 	d.SetId(t.UserGroupID)
 	d.Set("name", t.Name)
@@ -160,48 +417,240 @@ func userGroupRead(d *schema.ResourceData, m interface{}, params zabbix.Params)
 
 // dataUserGroupRead terraform data resource read handler
 func dataUserGroupRead(d *schema.ResourceData, m interface{}) error {
-	return userGroupRead(d, m, zabbix.Params{
+	params := zabbix.Params{
+		"output": USER_GROUP_OUTPUT,
 		"filter": map[string]interface{}{
 			"name": d.Get("name"),
 		},
+	}
+	applySearch(d, params, "name")
+
+	return userGroupRead(d, m, params)
+}
+
+// dataUserGroupsRead terraform plural data resource read handler
+func dataUserGroupsRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	params := zabbix.Params{
+		"output": []string{"usrgrpid", "name", "debug_mode", "gui_access", "users_status"},
+		"filter": map[string]interface{}{},
+	}
+
+	if v, ok := d.GetOk("name"); ok {
+		params["search"] = map[string]interface{}{
+			"name": v,
+		}
+	}
+
+	if v, ok := d.GetOkExists("status"); ok {
+		params["filter"].(map[string]interface{})["users_status"] = v
+	}
+	applySearch(d, params)
+	log.Debug("performing data lookup with params: %#v", params)
+
+	ids, list, err := pagedGet(d, "usrgrpid", USER_GROUP_SORT_FIELDS, params, func(params zabbix.Params) ([]map[string]interface{}, error) {
+		userGroups, err := api.UserGroupsGet(params)
+		entries := make([]map[string]interface{}, len(userGroups))
+		for i, t := range userGroups {
+			entries[i] = map[string]interface{}{
+				"id":     t.UserGroupID,
+				"name":   t.Name,
+				"status": t.Status,
+			}
+		}
+		return entries, err
 	})
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(dataListID(params))
+	d.Set("ids", ids)
+	d.Set("user_groups", list)
+
+	return nil
 }
 
 // resourceUserGroupRead terraform resource read handler
 func resourceUserGroupRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of UserGroup with id %s", d.Id())
 
-	return userGroupRead(d, m, zabbix.Params{
+	err := userGroupRead(d, m, zabbix.Params{
+		"output":    USER_GROUP_OUTPUT,
 		"usrgrpids": d.Id(),
 	})
+	if err != nil || d.Id() == "" {
+		return err
+	}
+
+	api := m.(*zabbix.API)
+	host, template, err := userGroupRights(api, d.Id())
+	if err != nil {
+		return err
+	}
+	permissions, err := flattenUserGroupPermissions(d, api, "host_permission", "hostgroup.get", host)
+	if err != nil {
+		return err
+	}
+	d.Set("host_permission", permissions)
+	if api.Config.Version >= 60200 {
+		permissions, err := flattenUserGroupPermissions(d, api, "template_permission", "templategroup.get", template)
+		if err != nil {
+			return err
+		}
+		d.Set("template_permission", permissions)
+	}
+
+	// only report members when they are managed from this side
+	if _, ok := d.GetOk("user_ids"); ok {
+		members, err := userGroupMembers(m.(*zabbix.API), d.Id())
+		if err != nil {
+			return err
+		}
+		d.Set("user_ids", members)
+	}
+
+	return nil
+}
+
+// userGroupRight permission on a host or template group as returned by the api,
+// permission is a string on real servers
+type userGroupRight struct {
+	ID         string      `json:"id"`
+	Permission json.Number `json:"permission"`
+}
+
+// userGroupRights host and template group permissions of a user group, host
+// group permissions were named rights before 6.2
+func userGroupRights(api *zabbix.API, id string) (host, template []userGroupRight, err error) {
+	var res []struct {
+		Rights              []userGroupRight `json:"rights"`
+		HostGroupRights     []userGroupRight `json:"hostgroup_rights"`
+		TemplateGroupRights []userGroupRight `json:"templategroup_rights"`
+	}
+	params := zabbix.Params{
+		"output":    []string{"usrgrpid"},
+		"usrgrpids": id,
+	}
+	if api.Config.Version >= 60200 {
+		params["selectHostGroupRights"] = []string{"id", "permission"}
+		params["selectTemplateGroupRights"] = []string{"id", "permission"}
+	} else {
+		params["selectRights"] = []string{"id", "permission"}
+	}
+	if err = api.CallWithErrorParse("usergroup.get", params, &res); err != nil || len(res) < 1 {
+		return
+	}
+
+	if api.Config.Version >= 60200 {
+		return res[0].HostGroupRights, res[0].TemplateGroupRights, nil
+	}
+	return res[0].Rights, nil, nil
+}
+
+// flattenUserGroupPermissions permission blocks of rights, groups configured by
+// name keep it so the hash of their block does not change, all others are
+// reported by id. method looks up the group names
+func flattenUserGroupPermissions(d *schema.ResourceData, api *zabbix.API, field, method string, rights []userGroupRight) ([]interface{}, error) {
+	byName := map[string]bool{}
+	for _, raw := range d.Get(field).(*schema.Set).List() {
+		if name, _ := raw.(map[string]interface{})["name"].(string); name != "" {
+			byName[name] = true
+		}
+	}
+
+	names := map[string]string{}
+	if len(byName) > 0 && len(rights) > 0 {
+		ids := make([]string, len(rights))
+		for i, r := range rights {
+			ids[i] = r.ID
+		}
+		var groups []struct {
+			GroupID string `json:"groupid"`
+			Name    string `json:"name"`
+		}
+		err := api.CallWithErrorParse(method, zabbix.Params{
+			"output":   []string{"groupid", "name"},
+			"groupids": ids,
+		}, &groups)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			if byName[g.Name] {
+				names[g.GroupID] = g.Name
+			}
+		}
+	}
+
+	list := make([]interface{}, len(rights))
+	for i, r := range rights {
+		permission, _ := r.Permission.Int64()
+		list[i] = map[string]interface{}{
+			"id":         r.ID,
+			"name":       names[r.ID],
+			"permission": int(permission),
+		}
+	}
+	return list, nil
+}
+
+// userGroupMembers ids of the users in a user group
+func userGroupMembers(api *zabbix.API, id string) ([]string, error) {
+	var res []struct {
+		Users []zabbix.UserID `json:"users"`
+	}
+	err := api.CallWithErrorParse("usergroup.get", zabbix.Params{
+		"output":      []string{"usrgrpid"},
+		"usrgrpids":   id,
+		"selectUsers": []string{"userid"},
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []string{}
+	for _, g := range res {
+		for _, u := range g.Users {
+			members = append(members, u.UserID)
+		}
+	}
+	return members, nil
 }
 
 // resourceUserGroupUpdate terraform resource update handler
 func resourceUserGroupUpdate(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
 
-	item := zabbix.UserGroup{
-		UserGroupID: d.Id(),
-		Name:        d.Get("name").(string),
-		DebugMode:   d.Get("debug_mode").(int),
-		GUIAccess:   d.Get("gui_access").(int),
-		Status:      d.Get("status").(int),
-		Permissions: resourceHostGroupPermissionsV1(d),
+	item, err := buildUserGroupObject(d, api)
+
+	if err != nil {
+		return err
 	}
 
-	items := []zabbix.UserGroup{item}
+	params, err := versionedParams(api, item, USER_GROUP_RENAMES)
 
-	err := api.UserGroupsUpdate(items)
+	if err != nil {
+		return err
+	}
+
+	_, err = api.CallWithError("usergroup.update", params)
 
 	if err != nil {
 		return err
 	}
 
-	return resourceUserGroupRead(d, m)
+	return readAfterWrite(d, m, resourceUserGroup().Schema, resourceUserGroupRead)
 }
 
 // resourceUserGroupDelete terraform resource delete handler
 func resourceUserGroupDelete(d *schema.ResourceData, m interface{}) error {
 	api := m.(*zabbix.API)
+
+	if err := checkDeletionProtection(d, "user group"); err != nil {
+		return err
+	}
 	return api.UserGroupsDeleteByIds([]string{d.Id()})
 }
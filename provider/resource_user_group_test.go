@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hoonii2/terraform-provider-zabbix/internal/mockzabbix"
+)
+
+func TestUnitResourceUserGroupRights(t *testing.T) {
+	cases := map[string]string{
+		"6.0.0": "rights",
+		"6.2.0": "hostgroup_rights",
+	}
+
+	for version, field := range cases {
+		t.Run(version, func(t *testing.T) {
+			server := testUnitServer(t, version)
+
+			groupID := server.Seed("hostgroup", map[string]interface{}{"name": "tf-acc-linux"})
+
+			resource.UnitTest(t, resource.TestCase{
+				ProviderFactories: testAccProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: testUnitResourceUserGroup(server.APIURL()),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("zabbix_user_group.test", "name", "tf-acc-group"),
+							testUnitUserGroupCreatedWith(server, field, groupID),
+						),
+					},
+					{
+						// imported groups are reported by id, configured ones by name
+						Config:                  testUnitResourceUserGroup(server.APIURL()),
+						ResourceName:            "zabbix_user_group.test",
+						ImportState:             true,
+						ImportStateVerify:       true,
+						ImportStateVerifyIgnore: []string{"host_permission"},
+					},
+				},
+			})
+		})
+	}
+}
+
+// testUnitUserGroupCreatedWith check the permissions were sent in the field the version expects
+func testUnitUserGroupCreatedWith(server *mockzabbix.Server, field, groupID string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		calls := server.Calls("usergroup.create")
+		if len(calls) != 1 {
+			return fmt.Errorf("expected 1 usergroup.create call, got %d", len(calls))
+		}
+
+		var params map[string]json.RawMessage
+		if err := json.Unmarshal(calls[0].Params, &params); err != nil {
+			return err
+		}
+		var rights []map[string]interface{}
+		if err := json.Unmarshal(params[field], &rights); err != nil {
+			return fmt.Errorf("usergroup.create without %s: %s", field, calls[0].Params)
+		}
+		if len(rights) != 1 || rights[0]["id"] != groupID {
+			return fmt.Errorf("unexpected %s: %v", field, rights)
+		}
+		return nil
+	}
+}
+
+func testUnitResourceUserGroup(url string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_user_group" "test" {
+	name = "tf-acc-group"
+	host_permission {
+		name       = "tf-acc-linux"
+		permission = 2
+	}
+}
+`, testUnitProviderConfig(url))
+}
+
+func TestUnitResourceUserGroupMembers(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	userID := server.Seed("user", map[string]interface{}{"username": "tf-unit-user"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceUserGroupMembers(server.APIURL(), fmt.Sprintf("%q", userID)),
+				Check:  resource.TestCheckResourceAttr("zabbix_user_group.test", "user_ids.#", "1"),
+			},
+			{
+				// emptying the list removes the members instead of leaving them alone
+				Config: testUnitResourceUserGroupMembers(server.APIURL(), ""),
+				Check: func(s *terraform.State) error {
+					calls := server.Calls("usergroup.update")
+					if len(calls) == 0 {
+						return fmt.Errorf("no usergroup.update call")
+					}
+					var params map[string]json.RawMessage
+					if err := json.Unmarshal(calls[len(calls)-1].Params, &params); err != nil {
+						return err
+					}
+					if string(params["users"]) != "[]" {
+						return fmt.Errorf("expected usergroup.update to send no users, got %s", calls[len(calls)-1].Params)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testUnitResourceUserGroupMembers(url, userIDs string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_user_group" "test" {
+	name     = "tf-unit-group"
+	user_ids = [%s]
+}
+`, testUnitProviderConfig(url), userIDs)
+}
+
+func TestUnitResourceUserGroupTemplateRightsRemoved(t *testing.T) {
+	server := testUnitServer(t, "6.2.0")
+
+	server.Seed("templategroup", map[string]interface{}{"name": "tf-unit-templates"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceUserGroupTemplateRights(server.APIURL(), true),
+				Check:  resource.TestCheckResourceAttr("zabbix_user_group.test", "template_permission.#", "1"),
+			},
+			{
+				// removing the last block clears the rights
+				Config: testUnitResourceUserGroupTemplateRights(server.APIURL(), false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_user_group.test", "template_permission.#", "0"),
+					func(s *terraform.State) error {
+						calls := server.Calls("usergroup.update")
+						if len(calls) == 0 {
+							return fmt.Errorf("no usergroup.update call")
+						}
+						var params map[string]json.RawMessage
+						if err := json.Unmarshal(calls[len(calls)-1].Params, &params); err != nil {
+							return err
+						}
+						if string(params["templategroup_rights"]) != "[]" {
+							return fmt.Errorf("expected usergroup.update to clear templategroup_rights, got %s", calls[len(calls)-1].Params)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceUserGroupTemplateRights(url string, granted bool) string {
+	rights := ""
+	if granted {
+		rights = `
+	template_permission {
+		name       = "tf-unit-templates"
+		permission = 2
+	}`
+	}
+	return fmt.Sprintf(`%s
+resource "zabbix_user_group" "test" {
+	name = "tf-unit-group"%s
+}
+`, testUnitProviderConfig(url), rights)
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hoonii2/terraform-provider-zabbix/internal/mockzabbix"
+)
+
+func TestUnitResourceUserPasswordVersion(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	roleID := server.Seed("role", map[string]interface{}{"name": "User role"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceUserPassword(server.APIURL(), roleID, "first-secret", 1),
+				Check:  testUnitCheckCallParam(server, "user.create", "passwd", "first-secret"),
+			},
+			{
+				Config:      testUnitResourceUserPassword(server.APIURL(), roleID, "first-secret", 2),
+				ExpectError: regexp.MustCompile("password_wo_version changed but password_wo did not"),
+			},
+			{
+				Config: testUnitResourceUserPassword(server.APIURL(), roleID, "second-secret", 2),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckCallParam(server, "user.update", "passwd", "second-secret"),
+					resource.TestCheckResourceAttr("zabbix_user.test", "password_wo_version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitDataUsersLimit(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	// ids in another order than the names, a limit without sortfield returns
+	// the lowest ids rather than the first names
+	for _, name := range []string{"b-user", "c-user", "a-user"} {
+		server.Seed("user", map[string]interface{}{"username": name, "roleid": "1", "users_status": "0"})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitDataUsersLimit(server.APIURL(), "username", "asc", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.zabbix_users.test", "users.#", "2"),
+					resource.TestCheckResourceAttr("data.zabbix_users.test", "users.0.username", "a-user"),
+					resource.TestCheckResourceAttr("data.zabbix_users.test", "users.1.username", "b-user"),
+				),
+			},
+			{
+				Config: testUnitDataUsersLimit(server.APIURL(), "id", "desc", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.zabbix_users.test", "users.#", "1"),
+					resource.TestCheckResourceAttr("data.zabbix_users.test", "users.0.username", "a-user"),
+				),
+			},
+			{
+				Config:      testUnitDataUsersLimit(server.APIURL(), "roleid", "asc", 1),
+				ExpectError: regexp.MustCompile(`limit can not be combined with sort_field "roleid"`),
+			},
+		},
+	})
+}
+
+func testUnitDataUsersLimit(url, field, order string, limit int) string {
+	return fmt.Sprintf(`%s
+data "zabbix_users" "test" {
+	limit      = %d
+	sort_field = %q
+	sort_order = %q
+}
+`, testUnitProviderConfig(url), limit, field, order)
+}
+
+// testUnitCheckCallParam check the last call of method sent param as want, for
+// write-only secrets the configured value rather than its hash
+func testUnitCheckCallParam(server *mockzabbix.Server, method, param, want string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		calls := server.Calls(method)
+		if len(calls) == 0 {
+			return fmt.Errorf("no %s call", method)
+		}
+		// some methods take a list of objects, check the first
+		raw := calls[len(calls)-1].Params
+		var list []json.RawMessage
+		if json.Unmarshal(raw, &list) == nil && len(list) > 0 {
+			raw = list[0]
+		}
+		var params map[string]interface{}
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return err
+		}
+		if got, _ := params[param].(string); got != want {
+			return fmt.Errorf("%s sent %s %q, expected %q", method, param, got, want)
+		}
+		return nil
+	}
+}
+
+func testUnitResourceUserPassword(url, roleID, password string, version int) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_user" "test" {
+	username            = "tf-unit-user"
+	roleid              = %q
+	password_wo         = %q
+	password_wo_version = %d
+}
+`, testUnitProviderConfig(url), roleID, password, version)
+}
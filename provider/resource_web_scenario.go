@@ -0,0 +1,598 @@
+package provider
+
+import (
+	"context"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// what a step retrieves from the response
+var WEB_RETRIEVE_MODE = map[string]int{
+	"body":    0,
+	"headers": 1,
+	"both":    2,
+}
+var WEB_RETRIEVE_MODE_REV = map[int]string{}
+
+// http authentication of a scenario
+var WEB_AUTHENTICATION = map[string]int{
+	"none":     0,
+	"basic":    1,
+	"ntlm":     2,
+	"kerberos": 3,
+}
+var WEB_AUTHENTICATION_REV = map[int]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range WEB_RETRIEVE_MODE {
+		WEB_RETRIEVE_MODE_REV[v] = k
+	}
+	for k, v := range WEB_AUTHENTICATION {
+		WEB_AUTHENTICATION_REV[v] = k
+	}
+	return false
+}()
+
+// webScenario web scenario, the api library has no support for them
+type webScenario struct {
+	HTTPTestID     string            `json:"httptestid,omitempty"`
+	HostID         string            `json:"hostid,omitempty"`
+	Name           string            `json:"name"`
+	Delay          string            `json:"delay"`
+	Retries        int               `json:"retries,string"`
+	Status         int               `json:"status,string"`
+	Agent          string            `json:"agent"`
+	Authentication int               `json:"authentication,string"`
+	HTTPUser       string            `json:"http_user"`
+	HTTPPassword   string            `json:"http_password"`
+	SSLCertFile    string            `json:"ssl_cert_file"`
+	SSLKeyFile     string            `json:"ssl_key_file"`
+	SSLKeyPassword string            `json:"ssl_key_password"`
+	VerifyPeer     int               `json:"verify_peer,string"`
+	VerifyHost     int               `json:"verify_host,string"`
+	Variables      []webField        `json:"variables"`
+	Headers        []webField        `json:"headers"`
+	Steps          []webScenarioStep `json:"steps"`
+	// tags came with 5.4, older servers refuse the parameter
+	Tags *zabbix.Tags `json:"tags,omitempty"`
+}
+
+// webField name/value pair of variables, headers and form fields
+type webField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type webScenarioStep struct {
+	HTTPStepID      string          `json:"httpstepid,omitempty"`
+	Name            string          `json:"name"`
+	No              int             `json:"no,string"`
+	URL             string          `json:"url"`
+	Timeout         string          `json:"timeout"`
+	PostType        int             `json:"post_type,string"`
+	Posts           json.RawMessage `json:"posts"`
+	Variables       []webField      `json:"variables"`
+	Headers         []webField      `json:"headers"`
+	Required        string          `json:"required"`
+	StatusCodes     string          `json:"status_codes"`
+	FollowRedirects int             `json:"follow_redirects,string"`
+	RetrieveMode    int             `json:"retrieve_mode,string"`
+}
+
+// webFieldSchema list of name/value pairs, variables names are {macro} like
+func webFieldSchema(description string, variables bool) *schema.Schema {
+	name := &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "Name",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	}
+	if variables {
+		name.Description = "Variable name, like {name}"
+		name.ValidateFunc = validation.StringMatch(regexp.MustCompile("^\\{[^{}]+\\}$"), "must be enclosed in braces, like {name}")
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": name,
+				"value": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+					Description: "Value",
+				},
+			},
+		},
+	}
+}
+
+// validateStatusCodes accept a comma separated list of codes, ranges and user macros
+func validateStatusCodes(v interface{}, k string) (ws []string, es []error) {
+	if v.(string) == "" {
+		return
+	}
+	code := regexp.MustCompile("^([0-9]+(-[0-9]+)?|\\{\\$.+\\})$")
+	for _, part := range strings.Split(v.(string), ",") {
+		if !code.MatchString(strings.TrimSpace(part)) {
+			es = append(es, fmt.Errorf("%q: %q is not a status code, range like 200-299 or user macro", k, part))
+		}
+	}
+	return
+}
+
+// resourceWebScenario terraform web scenario resource entrypoint
+func resourceWebScenario() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: contextCRUD(resourceWebScenarioCreate),
+		ReadContext:   contextCRUD(resourceWebScenarioRead),
+		UpdateContext: contextCRUD(resourceWebScenarioUpdate),
+		DeleteContext: contextCRUD(resourceWebScenarioDelete),
+		CustomizeDiff: webScenarioCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"hostid": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Host/Template ID the web scenario belongs to",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Web scenario name, unique on the host",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			"delay": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1m",
+				Description:  "Execution interval",
+				ValidateFunc: validateTimeSuffix,
+			},
+			"retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				Description:  "Attempts to run each step before failing",
+				ValidateFunc: validation.IntBetween(1, 10),
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Enable the web scenario",
+			},
+			"agent": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "Zabbix",
+				Description: "User agent string sent with the requests",
+			},
+			"authentication": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "none",
+				Description:      "HTTP authentication, one of: none, basic, ntlm, kerberos",
+				ValidateFunc:     validateEnum(WEB_AUTHENTICATION),
+				DiffSuppressFunc: suppressEnumDiff(WEB_AUTHENTICATION),
+			},
+			"http_user": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "HTTP authentication user",
+			},
+			"http_password": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Sensitive:   true,
+				Description: "HTTP authentication password",
+			},
+			"ssl_cert_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Client certificate file, in the server's SSLCertLocation",
+			},
+			"ssl_key_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Client certificate private key file, in the server's SSLKeyLocation",
+			},
+			"ssl_key_password": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Sensitive:   true,
+				Description: "Password of the private key file",
+			},
+			"verify_peer": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Verify the certificate of the web server",
+			},
+			"verify_host": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Verify the web server name matches its certificate",
+			},
+			"variables": webFieldSchema("Scenario variables, usable in all steps", true),
+			"headers":   webFieldSchema("HTTP headers sent with every step", false),
+			"tag":       schemaTags(),
+			"step": &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "Steps, executed in order",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Step name",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"url": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "URL to request",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"timeout": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "15s",
+							Description:  "Request timeout",
+							ValidateFunc: validateTimeSuffix,
+						},
+						"posts": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Raw post data, conflicts with post_field",
+						},
+						"post_field": webFieldSchema("Form fields to post, conflicts with posts", false),
+						"variables":  webFieldSchema("Variables extracted from the response, usable in the following steps", true),
+						"headers":    webFieldSchema("HTTP headers sent with the step", false),
+						"required": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Regular expression the response must match",
+						},
+						"status_codes": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							Description:  "Comma separated HTTP status codes or ranges the response must have",
+							ValidateFunc: validateStatusCodes,
+						},
+						"follow_redirects": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Follow HTTP redirects",
+						},
+						"retrieve_mode": &schema.Schema{
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "body",
+							Description:      "Part of the response to retrieve, one of: body, headers, both",
+							ValidateFunc:     validateEnum(WEB_RETRIEVE_MODE),
+							DiffSuppressFunc: suppressEnumDiff(WEB_RETRIEVE_MODE),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// webScenarioCustomizeDiff check the authentication and step settings the api
+// refuses together
+func webScenarioCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if enumName(d.Get("authentication").(string), WEB_AUTHENTICATION) == "none" &&
+		(d.Get("http_user").(string) != "" || d.Get("http_password").(string) != "") {
+		return fmt.Errorf("http_user and http_password need an authentication other than none")
+	}
+	if d.Get("ssl_key_password").(string) != "" && d.Get("ssl_cert_file").(string) == "" {
+		return fmt.Errorf("ssl_key_password needs ssl_cert_file")
+	}
+	if d.Get("ssl_key_file").(string) != "" && d.Get("ssl_cert_file").(string) == "" {
+		return fmt.Errorf("ssl_key_file needs ssl_cert_file")
+	}
+
+	for i, raw := range d.Get("step").([]interface{}) {
+		step := raw.(map[string]interface{})
+		if step["posts"].(string) != "" && len(step["post_field"].([]interface{})) > 0 {
+			return fmt.Errorf("step %d: posts and post_field conflict, use one of them", i)
+		}
+		// without the body the required string can't be searched for
+		if step["required"].(string) != "" && enumName(step["retrieve_mode"].(string), WEB_RETRIEVE_MODE) == "headers" {
+			return fmt.Errorf("step %d: required needs retrieve_mode body or both", i)
+		}
+	}
+	return nil
+}
+
+// buildWebFields build name/value pairs from terraform inputs
+func buildWebFields(list []interface{}) []webField {
+	fields := make([]webField, len(list))
+	for i, raw := range list {
+		current := raw.(map[string]interface{})
+		fields[i] = webField{
+			Name:  current["name"].(string),
+			Value: current["value"].(string),
+		}
+	}
+	return fields
+}
+
+// flattenWebFields convert response to terraform input
+func flattenWebFields(fields []webField) []interface{} {
+	val := make([]interface{}, len(fields))
+	for i, f := range fields {
+		val[i] = map[string]interface{}{
+			"name":  f.Name,
+			"value": f.Value,
+		}
+	}
+	return val
+}
+
+// buildWebScenarioObject create web scenario struct, steps are numbered in list
+// order and keep the ids of the steps previously at their position
+func buildWebScenarioObject(d *schema.ResourceData, api *zabbix.API) (*webScenario, error) {
+	item := webScenario{
+		Name:           d.Get("name").(string),
+		Delay:          d.Get("delay").(string),
+		Retries:        d.Get("retries").(int),
+		Agent:          d.Get("agent").(string),
+		Authentication: enumValue(d.Get("authentication").(string), WEB_AUTHENTICATION),
+		HTTPUser:       d.Get("http_user").(string),
+		HTTPPassword:   d.Get("http_password").(string),
+		SSLCertFile:    d.Get("ssl_cert_file").(string),
+		SSLKeyFile:     d.Get("ssl_key_file").(string),
+		SSLKeyPassword: d.Get("ssl_key_password").(string),
+		Variables:      buildWebFields(d.Get("variables").([]interface{})),
+		Headers:        buildWebFields(d.Get("headers").([]interface{})),
+	}
+
+	if !d.Get("enabled").(bool) {
+		item.Status = 1
+	}
+	if d.Get("verify_peer").(bool) {
+		item.VerifyPeer = 1
+	}
+	if d.Get("verify_host").(bool) {
+		item.VerifyHost = 1
+	}
+
+	if d.Get("tag").(*schema.Set).Len() > 0 {
+		if err := requireVersion(api, 50400, "web scenario tags"); err != nil {
+			return nil, errorAt("tag", err)
+		}
+	}
+	if api.Config.Version >= 50400 {
+		tags := tagGenerate(d)
+		item.Tags = &tags
+	}
+
+	for i, raw := range d.Get("step").([]interface{}) {
+		current := raw.(map[string]interface{})
+		step := webScenarioStep{
+			HTTPStepID:   current["id"].(string),
+			Name:         current["name"].(string),
+			No:           i + 1,
+			URL:          current["url"].(string),
+			Timeout:      current["timeout"].(string),
+			PostType:     1,
+			Variables:    buildWebFields(current["variables"].([]interface{})),
+			Headers:      buildWebFields(current["headers"].([]interface{})),
+			Required:     current["required"].(string),
+			StatusCodes:  current["status_codes"].(string),
+			RetrieveMode: enumValue(current["retrieve_mode"].(string), WEB_RETRIEVE_MODE),
+		}
+		if current["follow_redirects"].(bool) {
+			step.FollowRedirects = 1
+		}
+
+		var err error
+		if fields := current["post_field"].([]interface{}); len(fields) > 0 {
+			step.PostType = 0
+			step.Posts, err = json.Marshal(buildWebFields(fields))
+		} else {
+			step.Posts, err = json.Marshal(current["posts"].(string))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		item.Steps = append(item.Steps, step)
+	}
+
+	return &item, nil
+}
+
+// resourceWebScenarioCreate terraform create resource handler
+func resourceWebScenarioCreate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	item, err := buildWebScenarioObject(d, api)
+
+	if err != nil {
+		return err
+	}
+	item.HostID = d.Get("hostid").(string)
+	// steps of a new scenario have no ids yet
+	for i := range item.Steps {
+		item.Steps[i].HTTPStepID = ""
+	}
+
+	response, err := api.CallWithError("httptest.create", item)
+
+	if err != nil {
+		return err
+	}
+
+	result := response.Result.(map[string]interface{})
+	item.HTTPTestID = result["httptestids"].([]interface{})[0].(string)
+
+	log.Trace("created web scenario: %s", item.HTTPTestID)
+
+	d.SetId(item.HTTPTestID)
+
+	return resourceWebScenarioRead(d, m)
+}
+
+// resourceWebScenarioRead terraform read resource handler
+func resourceWebScenarioRead(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	log.Debug("Lookup of web scenario with id %s", d.Id())
+
+	params := zabbix.Params{
+		"output":      "extend",
+		"httptestids": d.Id(),
+		"selectSteps": "extend",
+	}
+	if api.Config.Version >= 50400 {
+		params["selectTags"] = []string{"tag", "value"}
+	}
+
+	var items []webScenario
+	err := api.CallWithErrorParse("httptest.get", params, &items)
+
+	if err != nil {
+		return err
+	}
+
+	if len(items) < 1 {
+		d.SetId("")
+		return nil
+	}
+	if len(items) > 1 {
+		return errors.New("multiple web scenarios found")
+	}
+	item := items[0]
+
+	log.Debug("Got web scenario: %s", item.HTTPTestID)
+
+	d.SetId(item.HTTPTestID)
+	d.Set("hostid", item.HostID)
+	d.Set("name", item.Name)
+	d.Set("delay", item.Delay)
+	d.Set("retries", item.Retries)
+	d.Set("enabled", item.Status == 0)
+	d.Set("agent", item.Agent)
+	setEnum(d, "authentication", item.Authentication, WEB_AUTHENTICATION, WEB_AUTHENTICATION_REV)
+	d.Set("http_user", item.HTTPUser)
+	d.Set("ssl_cert_file", item.SSLCertFile)
+	d.Set("ssl_key_file", item.SSLKeyFile)
+	d.Set("verify_peer", item.VerifyPeer == 1)
+	d.Set("verify_host", item.VerifyHost == 1)
+	// passwords the api does not return are kept from the configuration
+	if item.HTTPPassword != "" {
+		d.Set("http_password", item.HTTPPassword)
+	}
+	if item.SSLKeyPassword != "" {
+		d.Set("ssl_key_password", item.SSLKeyPassword)
+	}
+	d.Set("variables", flattenWebFields(item.Variables))
+	d.Set("headers", flattenWebFields(item.Headers))
+	if item.Tags != nil {
+		d.Set("tag", flattenTags(*item.Tags))
+	}
+	d.Set("step", flattenWebScenarioSteps(item.Steps, d))
+
+	return nil
+}
+
+// flattenWebScenarioSteps convert the steps, in execution order, to terraform input
+func flattenWebScenarioSteps(steps []webScenarioStep, d *schema.ResourceData) []interface{} {
+	sort.Slice(steps, func(i, j int) bool { return steps[i].No < steps[j].No })
+
+	val := make([]interface{}, len(steps))
+	for i, step := range steps {
+		current := map[string]interface{}{
+			"id":               step.HTTPStepID,
+			"name":             step.Name,
+			"url":              step.URL,
+			"timeout":          step.Timeout,
+			"posts":            "",
+			"post_field":       []interface{}{},
+			"variables":        flattenWebFields(step.Variables),
+			"headers":          flattenWebFields(step.Headers),
+			"required":         step.Required,
+			"status_codes":     step.StatusCodes,
+			"follow_redirects": step.FollowRedirects == 1,
+			"retrieve_mode":    WEB_RETRIEVE_MODE_REV[step.RetrieveMode],
+		}
+		if name := d.Get(fmt.Sprintf("step.%d.retrieve_mode", i)).(string); enumValue(name, WEB_RETRIEVE_MODE) == step.RetrieveMode {
+			current["retrieve_mode"] = name
+		}
+
+		// form fields come back as a list, raw data as a string
+		var fields []webField
+		var posts string
+		if err := json.Unmarshal(step.Posts, &fields); err == nil {
+			current["post_field"] = flattenWebFields(fields)
+		} else if err := json.Unmarshal(step.Posts, &posts); err == nil {
+			current["posts"] = posts
+		}
+
+		val[i] = current
+	}
+	return val
+}
+
+// resourceWebScenarioUpdate terraform update resource handler
+func resourceWebScenarioUpdate(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+
+	item, err := buildWebScenarioObject(d, api)
+
+	if err != nil {
+		return err
+	}
+	item.HTTPTestID = d.Id()
+
+	if _, err := api.CallWithError("httptest.update", item); err != nil {
+		return err
+	}
+
+	return resourceWebScenarioRead(d, m)
+}
+
+// resourceWebScenarioDelete terraform delete resource handler
+func resourceWebScenarioDelete(d *schema.ResourceData, m interface{}) error {
+	api := m.(*zabbix.API)
+	_, err := api.CallWithError("httptest.delete", []string{d.Id()})
+	return err
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceWebScenario(t *testing.T) {
+	server := testUnitServer(t, "6.0.0")
+
+	hostID := server.Seed("host", map[string]interface{}{"host": "tf-unit-host"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testUnitResourceWebScenario(server.APIURL(), hostID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "step.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "step.0.post_field.#", "2"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "step.0.variables.0.name", "{token}"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "step.1.posts", "{\"ping\":true}"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "step.1.retrieve_mode", "both"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "authentication", "ntlm"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "http_password", "secret"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "verify_peer", "true"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "verify_host", "false"),
+					resource.TestCheckResourceAttr("zabbix_web_scenario.test", "tag.#", "1"),
+					func(s *terraform.State) error {
+						calls := server.Calls("httptest.create")
+						if len(calls) != 1 {
+							return fmt.Errorf("expected 1 httptest.create call, got %d", len(calls))
+						}
+
+						var params webScenario
+						if err := json.Unmarshal(calls[0].Params, &params); err != nil {
+							return err
+						}
+						if params.Authentication != 2 || params.VerifyPeer != 1 || params.SSLCertFile != "client.pem" {
+							return fmt.Errorf("unexpected authentication sent: %s", calls[0].Params)
+						}
+						if params.Tags == nil || len(*params.Tags) != 1 || (*params.Tags)[0].Tag != "service" || (*params.Tags)[0].Value != "login" {
+							return fmt.Errorf("unexpected tags sent: %s", calls[0].Params)
+						}
+						if params.Steps[0].No != 1 || params.Steps[1].No != 2 || params.Steps[0].PostType != 0 || params.Steps[1].PostType != 1 {
+							return fmt.Errorf("unexpected steps sent: %s", calls[0].Params)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testUnitResourceWebScenario(url, hostID string) string {
+	return fmt.Sprintf(`%s
+resource "zabbix_web_scenario" "test" {
+	hostid = %q
+	name   = "login"
+
+	authentication = "ntlm"
+	http_user      = "monitor"
+	http_password  = "secret"
+	ssl_cert_file  = "client.pem"
+	verify_peer    = true
+
+	tag {
+		name  = "service"
+		value = "login"
+	}
+
+	step {
+		name = "login"
+		url  = "https://example.com/login"
+		post_field {
+			name  = "user"
+			value = "{$WEB.USER}"
+		}
+		post_field {
+			name  = "password"
+			value = "{$WEB.PASSWORD}"
+		}
+		variables {
+			name  = "{token}"
+			value = "regex:token=([a-z0-9]+)"
+		}
+		status_codes = "200,302"
+	}
+	step {
+		name          = "api"
+		url           = "https://example.com/api?token={token}"
+		posts         = "{\"ping\":true}"
+		required      = "pong"
+		retrieve_mode = "both"
+	}
+}
+`, testUnitProviderConfig(url), hostID)
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// safeDeleteSchema flags of the dependency check run before deleting hosts and templates
+func safeDeleteSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"safe_delete": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "Refuse to delete the object while it has dependent objects this configuration does not manage.",
+			Optional:    true,
+			Default:     false,
+		},
+		"force": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "Delete the object even when safe_delete finds dependent objects.",
+			Optional:    true,
+			Default:     false,
+		},
+	}
+}
+
+// dependentCheck a kind of object counted before deleting
+type dependentCheck struct {
+	Name   string
+	Method string
+	Params zabbix.Params
+}
+
+// hostDependents objects on a host not inherited from a template; managed items,
+// triggers and graphs depend on the host so terraform removes them first
+func hostDependents(id string) []dependentCheck {
+	return []dependentCheck{
+		{"items", "item.get", zabbix.Params{"hostids": id, "inherited": false}},
+		{"triggers", "trigger.get", zabbix.Params{"hostids": id, "inherited": false}},
+		{"graphs", "graph.get", zabbix.Params{"hostids": id, "inherited": false}},
+		{"discovery rules", "discoveryrule.get", zabbix.Params{"hostids": id, "inherited": false}},
+	}
+}
+
+// templateDependents own objects of a template and what it is linked to, deleting
+// a template also deletes everything it created on linked hosts
+func templateDependents(id string) []dependentCheck {
+	return append(hostDependents(id),
+		dependentCheck{"linked hosts", "host.get", zabbix.Params{"templateids": id}},
+		dependentCheck{"linked templates", "template.get", zabbix.Params{"parentTemplateids": id}},
+	)
+}
+
+// countObjects number of objects a get method returns
+func countObjects(api *zabbix.API, method string, params zabbix.Params) (int, error) {
+	params["countOutput"] = true
+
+	response, err := api.CallWithError(method, params)
+	if err != nil {
+		return 0, err
+	}
+
+	// counts are returned as a string
+	switch v := response.Result.(type) {
+	case string:
+		return strconv.Atoi(v)
+	case float64:
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("unexpected %s count result %v", method, response.Result)
+}
+
+// checkSafeDelete error out of delete handlers with safe_delete set while dependent
+// objects remain, unless force is set
+func checkSafeDelete(d *schema.ResourceData, api *zabbix.API, kind string, checks []dependentCheck) error {
+	if !d.Get("safe_delete").(bool) || d.Get("force").(bool) {
+		return nil
+	}
+
+	found := []string{}
+	for _, c := range checks {
+		count, err := countObjects(api, c.Method, c.Params)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			found = append(found, fmt.Sprintf("%d %s", count, c.Name))
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	log.Debug("%s %s still has %v", kind, d.Id(), found)
+
+	return fmt.Errorf("%s %s still has %s not managed by this configuration, deleting it loses them and their history; "+
+		"set force = true and apply to delete it anyway", kind, d.Id(), strings.Join(found, ", "))
+}
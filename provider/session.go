@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// sessionLogin log in with username/password, reusing a cached session token when configured
+func sessionLogin(api *zabbix.API, d *schema.ResourceData) error {
+	user := d.Get("username").(string)
+	password := d.Get("password").(string)
+
+	path := sessionCachePath(d)
+	if path == "" {
+		_, err := api.Login(user, password)
+		return err
+	}
+
+	key := sessionCacheKey(d.Get("url").(string), user)
+	if token := loadSessions(path)[key]; token != "" {
+		api.Auth = token
+
+		// a cheap authenticated call tells whether the session is still alive
+		_, err := api.CallWithError("user.get", zabbix.Params{
+			"output": []string{"userid"},
+			"limit":  1,
+		})
+		if err == nil {
+			log.Debug("Reusing cached session for %s", user)
+			return nil
+		}
+		log.Debug("Cached session for %s rejected, logging in again: %s", user, err)
+
+		// user.login refuses requests carrying an auth token
+		api.Auth = ""
+	}
+
+	token, err := api.Login(user, password)
+	if err != nil {
+		return err
+	}
+
+	if err := saveSession(path, key, token); err != nil {
+		log.Warn("Unable to cache session in %s: %s", path, err)
+	}
+	return nil
+}
+
+// sessionCachePath location of the session cache file, "" when disabled
+func sessionCachePath(d *schema.ResourceData) string {
+	path := d.Get("session_cache_file").(string)
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// sessionToken session token sent with every request, kept apart from the
+// api's own Auth which is read unlocked by every call and only set while configuring
+type sessionToken struct {
+	mu    sync.RWMutex
+	value string
+}
+
+// get current session token, "" when requests keep the api's own
+func (s *sessionToken) get() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value
+}
+
+// set replace the session token for all following requests
+func (s *sessionToken) set(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = token
+}
+
+// sessionRenewer log in again once the session expires, serialized so requests
+// failing together with the same stale session share a single user.login
+type sessionRenewer struct {
+	mu       sync.Mutex
+	version  int
+	user     string
+	password string
+	path     string
+	key      string
+}
+
+// newSessionRenewer renewer for the username/password session of api
+func newSessionRenewer(api *zabbix.API, d *schema.ResourceData) *sessionRenewer {
+	return &sessionRenewer{
+		version:  api.Config.Version,
+		user:     d.Get("username").(string),
+		password: d.Get("password").(string),
+		path:     sessionCachePath(d),
+		key:      sessionCacheKey(d.Get("url").(string), d.Get("username").(string)),
+	}
+}
+
+// renew return a valid session token for a request rejected with the stale one,
+// logging in only if no other request already did. The login is sent through
+// the given func rather than api.Login, which would block on the serialize lock
+// held by the rejected request and change api.Auth under running calls
+func (s *sessionRenewer) renew(session *sessionToken, stale string, login func(params map[string]string) (string, error)) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current := session.get(); current != stale && current != "" {
+		return current, nil
+	}
+	log.Debug("Session of %s was rejected, logging in again", s.user)
+
+	params := map[string]string{"username": s.user, "password": s.password}
+	if s.version < 50400 {
+		params = map[string]string{"user": s.user, "password": s.password}
+	}
+	token, err := login(params)
+	if err != nil {
+		return "", err
+	}
+	session.set(token)
+
+	if s.path != "" {
+		if err := saveSession(s.path, s.key, token); err != nil {
+			log.Warn("Unable to cache session in %s: %s", s.path, err)
+		}
+	}
+	return token, nil
+}
+
+// sessionCacheKey key cached sessions by api url and user, without storing either in clear
+func sessionCacheKey(url, user string) string {
+	sum := sha256.Sum256([]byte(url + "\x00" + user))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadSessions read the session cache file, a missing or broken file is an empty cache
+func loadSessions(path string) map[string]string {
+	sessions := map[string]string{}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return sessions
+	}
+	if err := json.Unmarshal(b, &sessions); err != nil {
+		log.Warn("Ignoring unreadable session cache %s: %s", path, err)
+		return map[string]string{}
+	}
+	return sessions
+}
+
+// saveSession store a session token in the cache file, readable by the current user only
+func saveSession(path, key, token string) error {
+	sessions := loadSessions(path)
+	sessions[key] = token
+
+	b, err := json.Marshal(sessions)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// write and rename, parallel runs must never see a partial file
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
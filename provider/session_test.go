@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+func TestUnitSessionRenewal(t *testing.T) {
+	for _, version := range []string{"6.0.0", "7.0.0"} {
+		t.Run(version, func(t *testing.T) {
+			var logins int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var call struct {
+					Method string `json:"method"`
+					Auth   string `json:"auth"`
+					ID     int32  `json:"id"`
+				}
+				json.NewDecoder(r.Body).Decode(&call)
+				if call.Auth == "" {
+					call.Auth = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				}
+
+				res := map[string]interface{}{"jsonrpc": "2.0", "id": call.ID}
+				switch {
+				case strings.EqualFold(call.Method, "apiinfo.version"):
+					res["result"] = version
+				case call.Method == "user.login":
+					atomic.AddInt32(&logins, 1)
+					res["result"] = "renewed"
+				case call.Auth != "renewed":
+					res["error"] = zabbix.Error{Code: -32602, Message: "Invalid params.", Data: "Session terminated, re-login, please."}
+				default:
+					res["result"] = []interface{}{}
+				}
+				json.NewEncoder(w).Encode(res)
+			}))
+			defer server.Close()
+
+			api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport := &apiTransport{
+				base:    http.DefaultTransport,
+				renewer: &sessionRenewer{version: api.Config.Version, user: "Admin", password: "zabbix"},
+			}
+			api.SetClient(&http.Client{Transport: transport})
+			api.Auth = "expired"
+			transport.session.set(api.Auth)
+
+			var wg sync.WaitGroup
+			errs := make([]error, 8)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = api.CallWithError("host.get", zabbix.Params{"output": []string{"hostid"}})
+				}(i)
+			}
+			wg.Wait()
+
+			for i, err := range errs {
+				if err != nil {
+					t.Errorf("call %d: %s", i, err)
+				}
+			}
+			if logins != 1 {
+				t.Errorf("expected 1 user.login, got %d", logins)
+			}
+			if token := transport.session.get(); token != "renewed" {
+				t.Errorf("expected the renewed session in the transport, got %q", token)
+			}
+			if api.Auth != "expired" {
+				t.Errorf("api.Auth changed after configure to %q", api.Auth)
+			}
+		})
+	}
+}
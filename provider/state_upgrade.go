@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// stateUpgrade a breaking schema change, the state of every resource goes through
+// all of them in order; the index of a step is the schema version it upgrades from
+type stateUpgrade struct {
+	Description string
+	Upgrade     schema.StateUpgradeFunc
+}
+
+// STATE_UPGRADES schema changes requiring state migration, append only
+var STATE_UPGRADES = []stateUpgrade{
+	{"tag key renamed to name", upgradeTagNames},
+}
+
+// withStateUpgraders version all resources and attach the state upgrade steps,
+// steps ignore resources they do not apply to
+func withStateUpgraders(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	for name, r := range resources {
+		// older states are decoded with the current schema, upgrades have to
+		// cope with attributes missing or left null
+		ty := r.CoreConfigSchema().ImpliedType()
+
+		r.SchemaVersion = len(STATE_UPGRADES)
+		r.StateUpgraders = make([]schema.StateUpgrader, len(STATE_UPGRADES))
+		for i, u := range STATE_UPGRADES {
+			r.StateUpgraders[i] = schema.StateUpgrader{
+				Version: i,
+				Type:    ty,
+				Upgrade: stateUpgradeFunc(name, i, u),
+			}
+		}
+	}
+	return resources
+}
+
+// stateUpgradeFunc wrap a step with logging
+func stateUpgradeFunc(name string, version int, u stateUpgrade) schema.StateUpgradeFunc {
+	return func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+		log.Debug("upgrading %s state from version %d: %s", name, version, u.Description)
+		return u.Upgrade(ctx, rawState, meta)
+	}
+}
+
+// upgradeTagNames copy the deprecated tag key into name
+func upgradeTagNames(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	tags, ok := rawState["tag"].([]interface{})
+	if !ok {
+		return rawState, nil
+	}
+
+	for _, raw := range tags {
+		tag, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _ := tag["name"].(string); name == "" {
+			tag["name"] = tag["key"]
+		}
+	}
+	return rawState, nil
+}
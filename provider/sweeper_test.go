@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// sweepPrefix name prefix of objects created by acceptance tests
+const sweepPrefix = "tf-acc-"
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	resource.AddTestSweepers("zabbix_host", &resource.Sweeper{
+		Name: "zabbix_host",
+		F:    sweepByName("host.get", "hostid", "host", "host.delete"),
+	})
+	resource.AddTestSweepers("zabbix_template", &resource.Sweeper{
+		Name:         "zabbix_template",
+		F:            sweepByName("template.get", "templateid", "host", "template.delete"),
+		Dependencies: []string{"zabbix_host"},
+	})
+	resource.AddTestSweepers("zabbix_hostgroup", &resource.Sweeper{
+		Name:         "zabbix_hostgroup",
+		F:            sweepByName("hostgroup.get", "groupid", "name", "hostgroup.delete"),
+		Dependencies: []string{"zabbix_host", "zabbix_template"},
+	})
+	resource.AddTestSweepers("zabbix_proxy", &resource.Sweeper{
+		Name:         "zabbix_proxy",
+		F:            sweepProxies,
+		Dependencies: []string{"zabbix_host"},
+	})
+	resource.AddTestSweepers("zabbix_user", &resource.Sweeper{
+		Name: "zabbix_user",
+		F:    sweepUsers,
+	})
+	resource.AddTestSweepers("zabbix_user_group", &resource.Sweeper{
+		Name:         "zabbix_user_group",
+		F:            sweepByName("usergroup.get", "usrgrpid", "name", "usergroup.delete"),
+		Dependencies: []string{"zabbix_user"},
+	})
+}
+
+// sweeperClient api client configured from the same environment as acceptance tests
+func sweeperClient() (*zabbix.API, error) {
+	p := Provider()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		return nil, fmt.Errorf("configuring sweeper client: %v", diags)
+	}
+	return p.Meta().(*zabbix.API), nil
+}
+
+// sweepByName sweeper deleting the objects a get method finds by name prefix
+func sweepByName(method, idField, nameField, deleteMethod string) func(string) error {
+	return func(region string) error {
+		api, err := sweeperClient()
+		if err != nil {
+			return err
+		}
+		return sweepNamed(api, method, idField, nameField, deleteMethod)
+	}
+}
+
+// sweepNamed delete the objects a get method finds by name prefix
+func sweepNamed(api *zabbix.API, method, idField, nameField, deleteMethod string) error {
+	var res []map[string]interface{}
+	err := api.CallWithErrorParse(method, zabbix.Params{
+		"output":      []string{idField, nameField},
+		"search":      map[string]interface{}{nameField: sweepPrefix},
+		"startSearch": true,
+	}, &res)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(res))
+	for i, r := range res {
+		ids[i] = r[idField].(string)
+		log.Debug("sweeping %s %s (%v)", method, ids[i], r[nameField])
+	}
+	return sweepDelete(api, deleteMethod, ids)
+}
+
+// sweepProxies proxies are named host before 7.0, proxiesGet translates
+func sweepProxies(region string) error {
+	api, err := sweeperClient()
+	if err != nil {
+		return err
+	}
+
+	proxies, err := proxiesGet(api, zabbix.Params{
+		"output":      []string{"proxyid", "name"},
+		"search":      map[string]interface{}{"name": sweepPrefix},
+		"startSearch": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(proxies))
+	for i, p := range proxies {
+		ids[i] = p.ProxyID
+	}
+	return sweepDelete(api, "proxy.delete", ids)
+}
+
+// sweepUsers users were identified by alias before 5.4
+func sweepUsers(region string) error {
+	api, err := sweeperClient()
+	if err != nil {
+		return err
+	}
+
+	field := "username"
+	if api.Config.Version < 50400 {
+		field = "alias"
+	}
+	return sweepNamed(api, "user.get", "userid", field, "user.delete")
+}
+
+// sweepDelete delete all ids with a single call
+func sweepDelete(api *zabbix.API, method string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := api.CallWithError(method, ids)
+	return err
+}
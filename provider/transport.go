@@ -0,0 +1,823 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hoonii2/go-zabbix-api"
+	"golang.org/x/time/rate"
+)
+
+// API error messages which indicate the frontend could not reach its backend,
+// the request did not change anything and is safe to send again
+var TRANSIENT_API_ERRORS = []string{
+	"Zabbix server is not running",
+	"Database error",
+	"Error connecting to database",
+}
+
+// idle connections kept open to the api, unless parallel_requests allows more
+const defaultIdleConns = 10
+
+// apiTransport http transport wrapping every JSON-RPC call to the Zabbix API
+type apiTransport struct {
+	base        http.RoundTripper
+	maxRetries  int
+	retryDelay  time.Duration
+	retryJitter bool
+	limiter     *rate.Limiter
+	slots       chan struct{}
+	basicUser   string
+	basicPass   string
+	cache       *responseCache
+	batcher     *readBatcher
+
+	// serialize sends one call at a time through this transport, which every
+	// client of the provider shares, in place of the lock of zabbix.API
+	serialize bool
+	serial    sync.Mutex
+
+	// session token put into every request in place of api.Auth, renewer
+	// replaces it once rejected. Both unused for api tokens
+	session sessionToken
+	renewer *sessionRenewer
+}
+
+// API error messages of requests sent with an expired or logged out session
+var AUTH_API_ERRORS = []string{
+	"Not authorised",
+	"Not authorized",
+	"Session terminated",
+}
+
+// responseCache responses of get calls keyed by method and params, any other
+// call may change objects and empties it
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+
+	// generation counts clears, a get answered across one may have read objects
+	// before they changed and is not cached
+	generation uint64
+}
+
+// newHTTPClient build the http client used for all API calls from the provider config
+func newHTTPClient(d *schema.ResourceData) (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	// every call goes to the same host, keep enough idle connections around
+	// for all parallel requests instead of the default of 2
+	base.MaxIdleConnsPerHost = defaultIdleConns
+	if n := d.Get("parallel_requests").(int); n > defaultIdleConns {
+		base.MaxIdleConnsPerHost = n
+	}
+	base.MaxIdleConns = base.MaxIdleConnsPerHost
+	if d.Get("tls_insecure").(bool) {
+		base.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
+
+	delay, err := time.ParseDuration(d.Get("retry_delay").(string))
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := time.ParseDuration(d.Get("timeout").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &apiTransport{
+		base:        base,
+		maxRetries:  d.Get("max_retries").(int),
+		retryDelay:  delay,
+		retryJitter: d.Get("retry_jitter").(bool),
+		serialize:   d.Get("serialize").(bool),
+	}
+
+	// no limit unless requests_per_second is set
+	if rps := d.Get("requests_per_second").(float64); rps > 0 {
+		transport.limiter = rate.NewLimiter(rate.Limit(rps), d.Get("burst").(int))
+	}
+
+	transport.basicUser, transport.basicPass = d.Get("http_basic_user").(string), d.Get("http_basic_password").(string)
+
+	if d.Get("cache_reads").(bool) {
+		transport.cache = &responseCache{entries: map[string][]byte{}}
+	}
+
+	if d.Get("batch_reads").(bool) {
+		window, err := time.ParseDuration(d.Get("batch_window").(string))
+		if err != nil {
+			return nil, err
+		}
+		transport.batcher = newReadBatcher(window)
+	}
+
+	// 0 leaves concurrency to terraform's own parallelism
+	if n := d.Get("parallel_requests").(int); n > 0 {
+		transport.slots = make(chan struct{}, n)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// RoundTrip send the request, answering repeated reads from the cache and adding
+// the call to the details of api errors
+func (t *apiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.serialize {
+		t.serial.Lock()
+		defer t.serial.Unlock()
+	}
+
+	req, err := withSession(req, t.session.get())
+	if err != nil {
+		return nil, err
+	}
+
+	key, read := t.cache.key(req)
+	var generation uint64
+	if read {
+		if b, ok := t.cache.get(key); ok {
+			log.Trace("Answering %s from the read cache", key)
+			return &http.Response{
+				Status:        "200 OK",
+				StatusCode:    http.StatusOK,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": []string{"application/json-rpc"}},
+				Body:          ioutil.NopCloser(bytes.NewReader(b)),
+				ContentLength: int64(len(b)),
+				Request:       req,
+			}, nil
+		}
+		generation = t.cache.current()
+	} else {
+		// gets sent while the write is in flight may see objects before or
+		// after it, clear once it returned as well
+		t.cache.clear()
+		defer t.cache.clear()
+	}
+
+	res, batched, err := t.batcher.do(req, t.roundTrip)
+	if !batched {
+		res, err = t.roundTrip(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stale, expired := t.sessionExpired(req, res); expired {
+		if res, err = t.retryWithNewSession(req, res, stale); err != nil {
+			return nil, err
+		}
+	}
+	res = annotateError(req, res)
+
+	if read {
+		t.cache.put(key, generation, res)
+	}
+	return res, nil
+}
+
+// sessionExpired whether a request was rejected for its session, along with the
+// session token it was sent with
+func (t *apiTransport) sessionExpired(req *http.Request, res *http.Response) (string, bool) {
+	if t.renewer == nil || res.StatusCode != http.StatusOK || req.GetBody == nil {
+		return "", false
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return "", false
+	}
+	var response struct {
+		Error *zabbix.Error `json:"error"`
+	}
+	if json.Unmarshal(b, &response) != nil || response.Error == nil {
+		return "", false
+	}
+	expired := false
+	for _, msg := range AUTH_API_ERRORS {
+		if strings.Contains(response.Error.Message, msg) || strings.Contains(response.Error.Data, msg) {
+			expired = true
+		}
+	}
+	if !expired {
+		return "", false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+	var call struct {
+		Method string `json:"method"`
+		Auth   string `json:"auth"`
+	}
+	if json.NewDecoder(body).Decode(&call) != nil || strings.EqualFold(call.Method, "user.login") {
+		return "", false
+	}
+
+	if call.Auth != "" {
+		return call.Auth, true
+	}
+	return strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), true
+}
+
+// retryWithNewSession send a request rejected for its session again with a
+// renewed one, the original response is kept when renewing fails
+func (t *apiTransport) retryWithNewSession(req *http.Request, res *http.Response, stale string) (*http.Response, error) {
+	token, err := t.renewer.renew(&t.session, stale, func(params map[string]string) (string, error) {
+		return t.login(req, params)
+	})
+	if err != nil {
+		log.Warn("Logging in again failed: %s", err)
+		return res, nil
+	}
+
+	retry, err := withSession(req, token)
+	if err != nil {
+		return res, nil
+	}
+
+	res.Body.Close()
+	return t.roundTrip(retry)
+}
+
+// withSession replace the session token a request was sent with, in the
+// authorization header from zabbix 7.0 and in the auth field before. Requests
+// without a token, like user.login, are left alone
+func withSession(req *http.Request, token string) (*http.Request, error) {
+	if token == "" || req.GetBody == nil {
+		return req, nil
+	}
+
+	if bearer := req.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		if bearer == "Bearer " || bearer == "Bearer "+token {
+			return req, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var call map[string]interface{}
+	if err := json.NewDecoder(body).Decode(&call); err != nil {
+		return nil, err
+	}
+	if auth, _ := call["auth"].(string); auth == "" || auth == token {
+		return req, nil
+	}
+	call["auth"] = token
+
+	b, err := json.Marshal(call)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.ContentLength = int64(len(b))
+	return req, nil
+}
+
+// login send user.login to the endpoint of req, returning the session token
+func (t *apiTransport) login(req *http.Request, params map[string]string) (string, error) {
+	b, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "user.login",
+		"params":  params,
+		"id":      0,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	login := req.Clone(req.Context())
+	login.Header.Del("Authorization")
+	login.Body = ioutil.NopCloser(bytes.NewReader(b))
+	login.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	login.ContentLength = int64(len(b))
+
+	res, err := t.roundTrip(login)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		Result string        `json:"result"`
+		Error  *zabbix.Error `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	if response.Error != nil {
+		return "", response.Error
+	}
+	return response.Result, nil
+}
+
+// key cache key of a request and whether it only reads, nil caches never read
+func (c *responseCache) key(req *http.Request) (string, bool) {
+	if c == nil || req.GetBody == nil {
+		return "", false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+
+	var call struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if json.NewDecoder(body).Decode(&call) != nil {
+		return "", false
+	}
+	if !strings.HasSuffix(strings.ToLower(call.Method), ".get") {
+		return "", false
+	}
+
+	// params are marshaled from maps, so equal params give equal keys
+	return call.Method + " " + string(call.Params), true
+}
+
+// get cached response body
+func (c *responseCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.entries[key]
+	return b, ok
+}
+
+// current generation, taken before sending a get and passed to put
+func (c *responseCache) current() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// put cache a successful response sent in generation, api errors and responses
+// to gets the cache was cleared during are not cached
+func (c *responseCache) put(key string, generation uint64, res *http.Response) {
+	if res.StatusCode != http.StatusOK {
+		return
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return
+	}
+
+	var response struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(b, &response) != nil || (len(response.Error) > 0 && string(response.Error) != "null") {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.entries[key] = b
+	}
+}
+
+// clear drop all cached responses
+func (c *responseCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if len(c.entries) > 0 {
+		c.entries = map[string][]byte{}
+	}
+}
+
+// roundTrip send the request, retrying with exponential backoff on transient errors
+func (t *apiTransport) roundTrip(req *http.Request) (res *http.Response, err error) {
+	if t.basicUser != "" {
+		if req, err = t.basicAuth(req); err != nil {
+			return nil, err
+		}
+	}
+
+	read := readOnly(req)
+	for attempt := 0; ; attempt++ {
+		// retries count against the limit as well
+		if t.limiter != nil {
+			if err = t.limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err = t.send(req)
+
+		if attempt >= t.maxRetries || !t.retryable(read, res, err) {
+			return
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+		if req.GetBody == nil {
+			return nil, err
+		}
+
+		wait := t.backoff(attempt)
+		log.Warn("Transient API error, retrying in %s (attempt %d of %d)", wait, attempt+1, t.maxRetries)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+
+		body, berr := req.GetBody()
+		if berr != nil {
+			return nil, berr
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+}
+
+// basicAuth add http basic auth for a proxy in front of the api, zabbix 7.0
+// sends its own token in the authorization header, so move it to the auth field
+func (t *apiTransport) basicAuth(req *http.Request) (*http.Request, error) {
+	req = req.Clone(req.Context())
+
+	if token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); token != req.Header.Get("Authorization") && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		var call map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&call); err != nil {
+			return nil, err
+		}
+		call["auth"] = token
+
+		b, err := json.Marshal(call)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(b)), nil
+		}
+		req.ContentLength = int64(len(b))
+	}
+
+	req.SetBasicAuth(t.basicUser, t.basicPass)
+	return req, nil
+}
+
+// send a single request, holding a parallel_requests slot until the response is read in full
+func (t *apiTransport) send(req *http.Request) (*http.Response, error) {
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		defer func() { <-t.slots }()
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || t.slots == nil {
+		return res, err
+	}
+
+	// the server is done with the request only once the body has been sent
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return res, nil
+}
+
+// retryable check whether a response or error is worth another attempt. Calls
+// other than gets may have been applied even though they failed, those are only
+// sent again when they never reached the server
+func (t *apiTransport) retryable(read bool, res *http.Response, err error) bool {
+	if err != nil {
+		// connection resets, refused connections, ...
+		return read || notSent(err)
+	}
+	if !read {
+		return false
+	}
+	if res.StatusCode >= 500 {
+		return true
+	}
+
+	// api errors are returned with a 200, peek into the body and put it back
+	b, rerr := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if rerr != nil {
+		return false
+	}
+
+	var response struct {
+		Error *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(b, &response) != nil || response.Error == nil {
+		return false
+	}
+
+	return transientMessage(response.Error.Message) || transientMessage(response.Error.Data)
+}
+
+// readOnly whether a request is a get, which is safe to send any number of times
+func readOnly(req *http.Request) bool {
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+
+	var call struct {
+		Method string `json:"method"`
+	}
+	if json.NewDecoder(body).Decode(&call) != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(call.Method), ".get")
+}
+
+// notSent whether a transport error happened before the request reached the
+// server, failing to connect rather than losing the connection
+func notSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// annotateError append the method and a summary of the params to the data of an
+// api error, the api only says what was wrong, not in which call
+func annotateError(req *http.Request, res *http.Response) *http.Response {
+	if res.StatusCode != http.StatusOK || req.GetBody == nil {
+		return res
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return res
+	}
+
+	var response map[string]json.RawMessage
+	if json.Unmarshal(b, &response) != nil || len(response["error"]) == 0 || string(response["error"]) == "null" {
+		return res
+	}
+	var apiErr zabbix.Error
+	if json.Unmarshal(response["error"], &apiErr) != nil {
+		return res
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return res
+	}
+	defer body.Close()
+	var call struct {
+		Method string      `json:"method"`
+		Params interface{} `json:"params"`
+	}
+	if json.NewDecoder(body).Decode(&call) != nil {
+		return res
+	}
+
+	apiErr.Data = fmt.Sprintf("%s [%s %s]", apiErr.Data, call.Method, paramsSummary(call.Params))
+	if response["error"], err = json.Marshal(apiErr); err != nil {
+		return res
+	}
+	if b, err = json.Marshal(response); err != nil {
+		return res
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	res.ContentLength = int64(len(b))
+	return res
+}
+
+// fields whose values identify an object in error summaries, other values are left
+// out as they may be long or secret
+var SUMMARY_NAME_FIELDS = map[string]bool{
+	"name":     true,
+	"host":     true,
+	"key_":     true,
+	"username": true,
+	"alias":    true,
+	"macro":    true,
+}
+
+// paramsSummary short description of call params: ids and names with their values,
+// the names of all other fields
+func paramsSummary(params interface{}) string {
+	switch p := params.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(p))
+		for k := range p {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		parts := []string{}
+		for _, k := range keys {
+			v, isString := p[k].(string)
+			if isString && (SUMMARY_NAME_FIELDS[k] || strings.HasSuffix(k, "id")) {
+				if len(v) > 64 {
+					v = v[:64] + "..."
+				}
+				parts = append(parts, fmt.Sprintf("%s=%q", k, v))
+			} else {
+				parts = append(parts, k)
+			}
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case []interface{}:
+		if len(p) == 1 {
+			return "[" + paramsSummary(p[0]) + "]"
+		}
+		return fmt.Sprintf("[%d objects]", len(p))
+	case string:
+		return fmt.Sprintf("%q", p)
+	}
+	return ""
+}
+
+// transientMessage check an api error message against TRANSIENT_API_ERRORS
+func transientMessage(text string) bool {
+	for _, msg := range TRANSIENT_API_ERRORS {
+		if strings.Contains(text, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientError check whether an error returned by an API call is worth
+// retrying. A create that failed on the network may have been applied anyway,
+// it is only repeated when it never reached the server
+func isTransientError(err error, create bool) bool {
+	if e, ok := err.(*zabbix.Error); ok {
+		return transientMessage(e.Message) || transientMessage(e.Data)
+	}
+	if _, ok := err.(net.Error); !ok {
+		return false
+	}
+	return !create || notSent(err)
+}
+
+// retryTransient keep calling fn while it fails with transient errors, until
+// timeout expires. fn gets a client cancelling each call after timeout as well
+func retryTransient(api *zabbix.API, timeout time.Duration, fn func(api *zabbix.API) error) error {
+	return retryTransientCall(api, timeout, false, fn)
+}
+
+// retryTransientCreate retryTransient for creates, which are not repeated after
+// timeouts or lost connections
+func retryTransientCreate(api *zabbix.API, timeout time.Duration, fn func(api *zabbix.API) error) error {
+	return retryTransientCall(api, timeout, true, fn)
+}
+
+// retryTransientCall implementation of retryTransient and retryTransientCreate
+func retryTransientCall(api *zabbix.API, timeout time.Duration, create bool, fn func(api *zabbix.API) error) error {
+	ctx := getProviderOptions(api).ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	bounded := apiWithTimeout(api, timeout)
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		if err := fn(bounded); err != nil {
+			if isTransientError(err, create) {
+				return retry.RetryableError(err)
+			}
+			return retry.NonRetryableError(err)
+		}
+		return nil
+	})
+}
+
+// timeoutClient key of an api client cancelling calls after a resource timeout
+type timeoutClient struct {
+	api     *zabbix.API
+	timeout time.Duration
+}
+
+var (
+	timeoutClientsMu sync.Mutex
+	timeoutClients   = map[timeoutClient]*zabbix.API{}
+)
+
+// apiWithTimeout client sharing the transport and session of api whose calls
+// are cancelled after timeout, api itself when the provider timeout is shorter
+func apiWithTimeout(api *zabbix.API, timeout time.Duration) *zabbix.API {
+	o := getProviderOptions(api)
+	if o.client == nil || o.blank == nil || timeout <= 0 || (o.client.Timeout > 0 && o.client.Timeout <= timeout) {
+		return api
+	}
+
+	timeoutClientsMu.Lock()
+	defer timeoutClientsMu.Unlock()
+
+	key := timeoutClient{api: api, timeout: timeout}
+	if bounded, ok := timeoutClients[key]; ok {
+		return bounded
+	}
+	bounded := copyAPI(o.blank)
+	bounded.SetClient(&http.Client{
+		Transport: o.client.Transport,
+		Timeout:   timeout,
+	})
+	setProviderOptions(bounded, o)
+	timeoutClients[key] = bounded
+	return bounded
+}
+
+// forgetTimeoutClients drop the clients of an api no longer used
+func forgetTimeoutClients(api *zabbix.API) {
+	timeoutClientsMu.Lock()
+	defer timeoutClientsMu.Unlock()
+	for key := range timeoutClients {
+		if key.api == api {
+			delete(timeoutClients, key)
+		}
+	}
+}
+
+// copyAPI copy of api with its url, version and logger, which NewAPI would
+// only get with another version lookup. vet rejects copying zabbix.API by
+// value for its serialize lock, unused as the transport serializes instead
+func copyAPI(api *zabbix.API) *zabbix.API {
+	c := new(zabbix.API)
+	reflect.ValueOf(c).Elem().Set(reflect.ValueOf(api).Elem())
+	return c
+}
+
+// backoff delay before the given retry attempt
+func (t *apiTransport) backoff(attempt int) time.Duration {
+	wait := t.retryDelay << uint(attempt)
+	if t.retryJitter && t.retryDelay > 0 {
+		wait += time.Duration(rand.Int63n(int64(t.retryDelay)))
+	}
+	return wait
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+func TestUnitTransportRetries(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var call struct {
+			Method string `json:"method"`
+			ID     int32  `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&call)
+		if strings.EqualFold(call.Method, "apiinfo.version") {
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": call.ID, "result": "6.0.0"})
+			return
+		}
+		mu.Lock()
+		calls[call.Method]++
+		mu.Unlock()
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.SetClient(&http.Client{Transport: &apiTransport{
+		base:       http.DefaultTransport,
+		maxRetries: 2,
+	}})
+
+	// the create may have been applied behind the 502, only the get is sent again
+	api.CallWithError("host.get", zabbix.Params{"output": []string{"hostid"}})
+	api.CallWithError("host.create", zabbix.Params{"host": "tf-unit-host"})
+
+	if calls["host.get"] != 3 {
+		t.Errorf("expected host.get to be sent 3 times, got %d", calls["host.get"])
+	}
+	if calls["host.create"] != 1 {
+		t.Errorf("expected host.create to be sent once, got %d", calls["host.create"])
+	}
+
+	// a server that is gone never saw the create, it is safe to send again
+	server.Close()
+	req, _ := http.NewRequest("POST", server.URL, nil)
+	_, err = http.DefaultTransport.RoundTrip(req)
+	if err == nil || !notSent(err) {
+		t.Errorf("expected a refused connection to count as not sent, got %v", err)
+	}
+}
+
+func TestUnitAPIWithTimeout(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var call struct {
+			Method string `json:"method"`
+			ID     int32  `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&call)
+		mu.Lock()
+		calls[strings.ToLower(call.Method)]++
+		mu.Unlock()
+		if strings.EqualFold(call.Method, "apiinfo.version") {
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": call.ID, "result": "7.0.0"})
+			return
+		}
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &apiTransport{
+		base:       http.DefaultTransport,
+		maxRetries: 2,
+		serialize:  true,
+	}}
+	api.SetClient(client)
+	setProviderOptions(api, providerOptions{client: client, blank: copyAPI(api)})
+
+	bounded := apiWithTimeout(api, time.Minute)
+	if bounded == api {
+		t.Fatal("expected a separate client for a timeout above the provider one")
+	}
+	if again := apiWithTimeout(api, time.Minute); again != bounded {
+		t.Error("expected the client of a timeout to be reused")
+	}
+	if bounded.Config.Version != 70000 {
+		t.Errorf("expected the copy to keep version 70000, got %d", bounded.Config.Version)
+	}
+
+	// the copy goes through the provider transport, retries included
+	bounded.CallWithError("host.get", zabbix.Params{"output": []string{"hostid"}})
+	if calls["apiinfo.version"] != 1 {
+		t.Errorf("expected no version lookup for the copy, got %d in total", calls["apiinfo.version"])
+	}
+	if calls["host.get"] != 3 {
+		t.Errorf("expected host.get to be sent 3 times, got %d", calls["host.get"])
+	}
+}
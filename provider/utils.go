@@ -1,7 +1,21 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"context"
+
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hoonii2/go-zabbix-api"
 )
 
@@ -63,6 +77,32 @@ func buildTemplateIds(s *schema.Set) zabbix.TemplateIDs {
 	return groups
 }
 
+// templatesClearSchema choice between clearing and unlinking templates removed
+// from the templates attribute
+func templatesClearSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Delete the entities of templates removed from templates, false only unlinks them and keeps their history.",
+		Optional:    true,
+		Default:     true,
+	}
+}
+
+// buildTemplateIdsClear templates removed from the templates attribute that
+// should be cleared, nil when they are only unlinked
+func buildTemplateIdsClear(d *schema.ResourceData) zabbix.TemplateIDs {
+	if !d.HasChange("templates") || !d.Get("templates_clear").(bool) {
+		return nil
+	}
+
+	old, new := d.GetChange("templates")
+	diff := old.(*schema.Set).Difference(new.(*schema.Set))
+	if diff.Len() == 0 {
+		return nil
+	}
+	return buildTemplateIds(diff)
+}
+
 // mergeSchemas, take a varadic list of schemas and merge, latter overwrites former
 func mergeSchemas(schemas ...map[string]*schema.Schema) map[string]*schema.Schema {
 	n := map[string]*schema.Schema{}
@@ -75,3 +115,454 @@ func mergeSchemas(schemas ...map[string]*schema.Schema) map[string]*schema.Schem
 
 	return n
 }
+
+// hashString non negative crc32 of s for set hashes and ids, the same values
+// the hashcode package of terraform 0.12 gave
+func hashString(s string) int {
+	v := int(crc32.ChecksumIEEE([]byte(s)))
+	if v >= 0 {
+		return v
+	}
+	if -v >= 0 {
+		return -v
+	}
+	// v == MinInt
+	return 0
+}
+
+// dataListID generate a stable id for list data sources from their lookup params
+func dataListID(params zabbix.Params) string {
+	return strconv.Itoa(hashString(fmt.Sprintf("%v", params)))
+}
+
+// searchSchema search options of data sources, wildcards sets whether they are
+// enabled by default, as plural data sources always allowed them
+func searchSchema(wildcards bool) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"search": &schema.Schema{
+			Type:        schema.TypeMap,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Optional:    true,
+			Description: "Object fields to search, matching objects whose field contains the given value",
+		},
+		"search_wildcards_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     wildcards,
+			Description: "Allow * as a wildcard in searched values, lookup attributes like name are searched instead of matched exactly",
+		},
+		"start_search": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Searched values have to match the start of the fields, lookup attributes like name are searched instead of matched exactly",
+		},
+		"search_by_any": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Return objects matching any of the searched fields instead of all of them",
+		},
+	}
+}
+
+// applySearch add the search options of a data source to params, the lookup
+// fields move from the exact filter to the search when wildcards or
+// start_search are enabled
+func applySearch(d *schema.ResourceData, params zabbix.Params, lookups ...string) {
+	search := map[string]interface{}{}
+	if m, ok := params["search"].(map[string]interface{}); ok {
+		for k, v := range m {
+			search[k] = v
+		}
+	}
+	for k, v := range d.Get("search").(map[string]interface{}) {
+		search[k] = v
+	}
+
+	wildcards := d.Get("search_wildcards_enabled").(bool)
+	start := d.Get("start_search").(bool)
+	if filter, ok := params["filter"].(map[string]interface{}); ok && (wildcards || start) {
+		for _, k := range lookups {
+			if v, ok := filter[k]; ok {
+				search[k] = v
+				delete(filter, k)
+			}
+		}
+	}
+	if len(search) == 0 {
+		return
+	}
+
+	params["search"] = search
+	params["searchWildcardsEnabled"] = wildcards
+	if start {
+		params["startSearch"] = true
+	}
+	if d.Get("search_by_any").(bool) {
+		params["searchByAny"] = true
+	}
+}
+
+// pagingSchema limit and page size of plural data sources
+func pagingSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"limit": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Maximum number of objects returned, 0 returns all matches",
+		},
+		"page_size": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      500,
+			ValidateFunc: validation.IntBetween(1, 10000),
+			Description:  "Number of objects fetched per API call",
+		},
+	}
+}
+
+// pagedGet fetch the objects matching params page by page: the ids alone first,
+// then the objects page_size ids at a time, so large results neither time out
+// nor have to be built by the server in one go. sortFields maps sort_field to
+// the sortfield of the get method, get returns the state entries of the
+// objects a call found, each with its "id"
+func pagedGet(d *schema.ResourceData, idField string, sortFields map[string]string, params zabbix.Params, get func(params zabbix.Params) ([]map[string]interface{}, error)) ([]string, []interface{}, error) {
+	idParams := zabbix.Params{}
+	for k, v := range params {
+		idParams[k] = v
+	}
+	idParams["output"] = []string{idField}
+
+	field, desc := d.Get("sort_field").(string), d.Get("sort_order").(string) == "desc"
+	limit := d.Get("limit").(int)
+	if limit > 0 {
+		// the server picks which matches make the limit, unless it sorts them
+		// first any of them come back
+		switch {
+		case sortFields[field] != "":
+			sortBy := []string{sortFields[field]}
+			if byID := sortFields["id"]; byID != "" && byID != sortBy[0] {
+				sortBy = append(sortBy, byID)
+			}
+			idParams["sortfield"] = sortBy
+			idParams["sortorder"] = "ASC"
+			if desc {
+				idParams["sortorder"] = "DESC"
+			}
+			idParams["limit"] = limit
+		case field != "id":
+			return nil, nil, fmt.Errorf("limit can not be combined with sort_field %q, the API can not sort by it", field)
+		}
+	}
+
+	found, err := get(idParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, len(found))
+	for i, e := range found {
+		ids[i] = e["id"].(string)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+	// ids the API can not sort by came back in full and are limited here
+	if limit > 0 && len(ids) > limit {
+		if desc {
+			ids = ids[len(ids)-limit:]
+		} else {
+			ids = ids[:limit]
+		}
+	}
+
+	size := d.Get("page_size").(int)
+	list := make([]interface{}, 0, len(ids))
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		page := zabbix.Params{}
+		for k, v := range params {
+			page[k] = v
+		}
+		page[idField+"s"] = ids[start:end]
+		log.Trace("Fetching page of %d objects from %d", end-start, start)
+
+		entries, err := get(page)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, e := range entries {
+			list = append(list, e)
+		}
+	}
+
+	sortEntries(d, list)
+
+	// objects deleted between the calls are gone from the pages
+	ids = make([]string, len(list))
+	for i, e := range list {
+		ids[i] = e.(map[string]interface{})["id"].(string)
+	}
+	return ids, list, nil
+}
+
+// sortSchema sort options of plural data sources, fields maps the attributes
+// of the returned objects results can be sorted by, id included, to the API
+// sortfield of each, empty for those the API can not sort by
+func sortSchema(fields map[string]string) map[string]*schema.Schema {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return map[string]*schema.Schema{
+		"sort_field": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "id",
+			ValidateFunc: validation.StringInSlice(names, false),
+			Description:  "Attribute of the returned objects to sort them by, with limit only those the API can sort by",
+		},
+		"sort_order": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "asc",
+			ValidateFunc: validation.StringInSlice([]string{"asc", "desc"}, false),
+			Description:  "Sort order, asc or desc",
+		},
+	}
+}
+
+// sortEntries sort the state entries of a plural data source by sort_field,
+// numerically when both values are numbers, ties are ordered by id
+func sortEntries(d *schema.ResourceData, list []interface{}) {
+	field := d.Get("sort_field").(string)
+	desc := d.Get("sort_order").(string) == "desc"
+
+	less := func(a, b interface{}) (bool, bool) {
+		as, bs := fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)
+		if as == bs {
+			return false, false
+		}
+		an, aerr := strconv.ParseFloat(as, 64)
+		bn, berr := strconv.ParseFloat(bs, 64)
+		if aerr == nil && berr == nil {
+			return an < bn, true
+		}
+		return as < bs, true
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i].(map[string]interface{}), list[j].(map[string]interface{})
+		l, differ := less(a[field], b[field])
+		if !differ {
+			l, differ = less(a["id"], b["id"])
+		}
+		if desc {
+			return differ && !l
+		}
+		return l
+	})
+}
+
+// validateDuration check a string parses as a go duration, e.g. 500ms or 2m
+func validateDuration(v interface{}, k string) (ws []string, es []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		es = append(es, fmt.Errorf("%q: %s", k, err))
+	}
+	return
+}
+
+// zabbix time values: a number with an optional time suffix, or a user or lld macro
+var timeSuffixValue = regexp.MustCompile(`^(\d+[smhdw]?|\{\$[A-Z0-9_.]+(:[^}]*)?\}|\{#[A-Z0-9_.]+\})$`)
+
+// flexible interval, <interval>/<weekday[-weekday]>,<hh:mm>-<hh:mm> or a macro as period
+var flexibleInterval = regexp.MustCompile(`^([^/]+)/(\d(-\d)?,\d{1,2}:\d{2}-\d{1,2}:\d{2}|\{\$[A-Z0-9_.]+(:[^}]*)?\})$`)
+
+// scheduling interval, e.g. wd1-5h9m30 or h0-23/2
+var schedulingInterval = regexp.MustCompile(`^((md|wd|h|m|s)[0-9,/-]+)+$`)
+
+// colors, rrggbb without a leading #
+var hexColor = regexp.MustCompile("^[0-9A-Fa-f]{6}$")
+
+// validateTimeSuffix accept zabbix time values like 30s, 5m, 2h, 1d, 1w or {$MACRO}
+func validateTimeSuffix(v interface{}, k string) (ws []string, es []error) {
+	if !timeSuffixValue.MatchString(v.(string)) {
+		es = append(es, fmt.Errorf("%q: %q is not a time value like 30s, 5m, 2h, 1d, 1w or a macro", k, v))
+	}
+	return
+}
+
+// validateUpdateInterval accept an update interval, optionally followed by ; separated
+// flexible (50s/1-5,09:00-18:00) or scheduling (wd1-5h9) custom intervals
+func validateUpdateInterval(v interface{}, k string) (ws []string, es []error) {
+	parts := strings.Split(v.(string), ";")
+	ws, es = validateTimeSuffix(parts[0], k)
+
+	for _, part := range parts[1:] {
+		if m := flexibleInterval.FindStringSubmatch(part); m != nil {
+			if !timeSuffixValue.MatchString(m[1]) {
+				es = append(es, fmt.Errorf("%q: invalid interval %q in flexible interval %q", k, m[1], part))
+			}
+			continue
+		}
+		if !schedulingInterval.MatchString(part) {
+			es = append(es, fmt.Errorf("%q: %q is neither a flexible nor a scheduling interval", k, part))
+		}
+	}
+	return
+}
+
+// getByName read the single object of a get method whose nameField matches name
+// into obj, what names the object in the errors when none or several match
+func getByName(api *zabbix.API, method, nameField, name, what string, obj interface{}) error {
+	var res []json.RawMessage
+	err := api.CallWithErrorParse(method, zabbix.Params{
+		"output": "extend",
+		"filter": map[string]interface{}{
+			nameField: name,
+		},
+	}, &res)
+
+	if err != nil {
+		return err
+	}
+
+	if len(res) < 1 {
+		return fmt.Errorf("no %s found", what)
+	}
+	if len(res) > 1 {
+		return fmt.Errorf("multiple %ss found", what)
+	}
+	return json.Unmarshal(res[0], obj)
+}
+
+// enumValue resolve a named enum value, or its plain number, -1 if unknown
+func enumValue(v string, names map[string]int) int {
+	if n, ok := names[v]; ok {
+		return n
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		for _, known := range names {
+			if known == n {
+				return n
+			}
+		}
+	}
+	return -1
+}
+
+// enumName name of a named enum value or its plain number, empty if unknown
+func enumName(v string, names map[string]int) string {
+	n := enumValue(v, names)
+	for name, known := range names {
+		if known == n {
+			return name
+		}
+	}
+	return ""
+}
+
+// validateEnum accept the names of an enum as well as the numbers they stand for
+func validateEnum(names map[string]int) schema.SchemaValidateFunc {
+	return func(v interface{}, k string) (ws []string, es []error) {
+		if enumValue(v.(string), names) < 0 {
+			es = append(es, fmt.Errorf("%q: unknown value %q", k, v))
+		}
+		return
+	}
+}
+
+// suppressEnumDiff treat a name and its number as the same enum value
+func suppressEnumDiff(names map[string]int) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		return old != "" && enumValue(old, names) == enumValue(new, names)
+	}
+}
+
+// suppressNumericDiff treat numbers the api returns reformatted, e.g. 100 as
+// 100.0000, as unchanged
+func suppressNumericDiff(k, old, new string, d *schema.ResourceData) bool {
+	a, aerr := strconv.ParseFloat(old, 64)
+	b, berr := strconv.ParseFloat(new, 64)
+	return aerr == nil && berr == nil && a == b
+}
+
+// resourceGetter read access shared by schema.ResourceData and schema.ResourceDiff,
+// so builders can also be used at plan time
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// setEnum store an enum value read from the api, keeping the spelling used in the
+// configuration when it still means the same value
+func setEnum(d *schema.ResourceData, key string, value int, names map[string]int, rev map[int]string) {
+	if enumValue(d.Get(key).(string), names) == value {
+		return
+	}
+	if name, ok := rev[value]; ok {
+		d.Set(key, name)
+		return
+	}
+	d.Set(key, strconv.Itoa(value))
+}
+
+// deletionProtectionSchema flag refusing to delete the object while it is set
+func deletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Refuse to delete the object while set, unset and apply it before destroying.",
+		Optional:    true,
+		Default:     false,
+	}
+}
+
+// checkDeletionProtection error out of delete handlers of protected objects,
+// the flag is taken from state so it has to be unset with an apply first
+func checkDeletionProtection(d *schema.ResourceData, kind string) error {
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf("%s %s has deletion_protection set, set it to false and apply before deleting it", kind, d.Id())
+	}
+	return nil
+}
+
+// hashSecret state func keeping only a hash of write-only secrets in the state,
+// changes to the configured value still show up as a diff
+func hashSecret(v interface{}) string {
+	s := v.(string)
+	if s == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hashedSecretCustomizeDiff reject changes to keys which need a hashSecret field
+// sent again unless the secret changes as well, an unchanged secret is only
+// known by its hash and the configured value never reaches the update
+func hashedSecretCustomizeDiff(secret string, keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if d.Id() == "" || !d.NewValueKnown(secret) {
+			return nil
+		}
+		// the plan holds the configured secret, the state its hash
+		o, n := d.GetChange(secret)
+		if n.(string) == "" || (n != o && hashSecret(n) != o) {
+			return nil
+		}
+		for _, key := range keys {
+			if d.HasChange(key) {
+				return fmt.Errorf("%s changed but %s did not, the state only keeps a hash of %s so it can not be sent again, change %s along with %s", key, secret, secret, secret, key)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hoonii2/go-zabbix-api"
+)
+
+// versionString format a numeric api version (60204) as a readable one (6.2.4)
+func versionString(v int) string {
+	return fmt.Sprintf("%d.%d.%d", v/10000, v/100%100, v%100)
+}
+
+// requireVersion error out early when the connected server is older than min,
+// rather than sending a request the server answers with an opaque error
+func requireVersion(api *zabbix.API, min int, feature string) error {
+	if api.Config.Version < min {
+		return fmt.Errorf("%s is only supported in zabbix versions >= %s, connected server is %s",
+			feature, versionString(min), versionString(api.Config.Version))
+	}
+	return nil
+}
+
+// versionedParams convert an api object into request params, renaming fields
+// for servers older than the version the field was introduced in
+func versionedParams(api *zabbix.API, obj interface{}, renames map[string]versionedField) (map[string]interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{}
+	if err := json.Unmarshal(b, &params); err != nil {
+		return nil, err
+	}
+
+	for field, r := range renames {
+		if v, ok := params[field]; ok && api.Config.Version < r.Since {
+			delete(params, field)
+			params[r.Before] = v
+		}
+	}
+	return params, nil
+}
+
+// versionedField field name used before the api version a field was renamed in
+type versionedField struct {
+	Since  int
+	Before string
+}